@@ -1,9 +1,10 @@
-// cmd/installer/animations.go
-package main
+// internal/installer/animations.go
+package installer
 
 import (
 	"fmt"
 	"math/rand"
+	"os"
 	"sort"
 	"strings"
 	"time"
@@ -717,6 +718,25 @@ func NewTypewriterTicker() *TypewriterTicker {
 	}
 }
 
+// NewStaticTicker shows one message in full, for a welcome screen without
+// animation; it never types or rotates
+func NewStaticTicker() *TypewriterTicker {
+	message := "Installing OpenCode-Cursor plugin..."
+	return &TypewriterTicker{
+		roasts:      []string{message},
+		roastsRunes: [][]rune{[]rune(message)},
+		paused:      true,
+		pauseUntil:  time.Now(),
+	}
+}
+
+// animationsDisabled reports whether the TUI should be static: --no-animation,
+// or a terminal that asks for plain output (NO_COLOR, TERM=dumb). Screen
+// readers and slow remote sessions cope badly with a screen redrawn every 50ms.
+func animationsDisabled(noAnimation bool) bool {
+	return noAnimation || os.Getenv("NO_COLOR") != "" || os.Getenv("TERM") == "dumb"
+}
+
 func (t *TypewriterTicker) Update() {
 	now := time.Now()
 
@@ -0,0 +1,24 @@
+// internal/installer/ansi.go
+package installer
+
+import "regexp"
+
+// ansiEscapeRegex matches the terminal escape sequences seen in command
+// output, in both 7-bit (ESC-prefixed) and 8-bit (C1 control) forms:
+//   - OSC, e.g. hyperlinks: ESC ] 8 ; ; url BEL|ST
+//   - DCS, SOS, PM and APC strings, up to ST
+//   - CSI, e.g. colors and cursor movement: ESC [ 1 ; 32 m
+//   - the remaining short escapes, e.g. charset selection: ESC ( B
+//
+// String sequences stop at a newline if unterminated so a stray ESC ] cannot
+// swallow the rest of the output.
+var ansiEscapeRegex = regexp.MustCompile(
+	`(?:\x1b\]|\x{9d})[^\x07\x1b\x{9c}\n]*(?:\x07|\x1b\\|\x{9c})?` +
+		`|(?:\x1b[PX^_]|[\x{90}\x{98}\x{9e}\x{9f}])[^\x1b\x{9c}\n]*(?:\x1b\\|\x{9c})?` +
+		`|(?:\x1b\[|\x{9b})[0-?]*[ -/]*[@-~]` +
+		`|\x1b[ -/]*[0-~]`)
+
+// stripANSI removes terminal escape sequences, leaving the visible text
+func stripANSI(s string) string {
+	return ansiEscapeRegex.ReplaceAllString(s, "")
+}
@@ -0,0 +1,368 @@
+// internal/installer/api.go
+
+// Package installer installs the cursor-acp OpenCode plugin. Main runs the
+// interactive installer for cmd/installer; Install, Uninstall and Doctor run
+// the same steps without a terminal UI or printed output, for the public
+// pkg/installer to expose.
+package installer
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Options configures Install, Uninstall and Doctor. The zero value behaves
+// like the installer run with no flags, except that nothing is logged.
+type Options struct {
+	ProjectDir   string // plugin checkout to build; empty uses OPENCODE_CURSOR_PROJECT_DIR or the working directory
+	ConfigPath   string // opencode.json to edit; empty uses the detected OpenCode's
+	PluginDir    string // where to link the plugin; empty uses the one beside the config
+	OpenCodePath string // opencode binary to use; empty uses the first on PATH
+	LogFile      string // appended to; empty writes no log
+	BackupDir    string // where copies of changed files go; empty uses ~/.config/opencode/.cursor-acp-backups
+
+	BaseURL string // proxy base URL; empty keeps the configured one
+	Port    string // proxy port, or "auto" to pick a free one
+
+	NetworkProxy string // HTTP(S) proxy for bun, npm and cursor-agent (--proxy); empty uses HTTPS_PROXY etc. as set
+
+	DryRun     bool // report what would change without changing it
+	SkipBuild  bool // use the existing dist/ instead of building
+	Force      bool // rebuild and rewrite the provider from scratch
+	NoRollback bool // leave changes in place when a task fails
+	DeepVerify bool // send a test chat completion after installing
+	Resume     bool // skip tasks the last failed install completed, if their work still holds
+	NoNetwork  bool // never touch the network; needs ModelsFile and already installed dependencies
+
+	ModelsFile     string   // read models from this file instead of cursor-agent
+	ModelsEndpoint string   // HTTP endpoint of a running cursor-agent session to list models from; the CLI is the fallback
+	Ref            string   // git branch, tag or commit of the plugin source to build
+	MergeStrategy  string   // replace, union or prune; empty uses union
+	PackageManager string   // bun, pnpm or npm; empty auto-detects
+	AcpSdkVersion  string   // version or range for @agentclientprotocol/sdk
+	AiSdkVersion   string   // version or range for @ai-sdk/openai-compatible
+	ExcludeModels  []string // model IDs or globs to leave out
+	OnlyModels     []string // model IDs or globs to keep
+
+	// ProviderOptions adds entries to the cursor-acp provider's options, each
+	// value JSON (a plain string is taken as is); baseURL cannot be set here
+	ProviderOptions map[string]string
+
+	Timeout time.Duration // deadline for the whole run; 0 is none
+
+	// Runner runs every external command; nil runs them for real
+	Runner CommandRunner
+
+	VerifyRetries int           // extra checks that OpenCode loaded the plugin; 0 uses the default, negative none
+	VerifyTimeout time.Duration // budget for those checks; 0 uses the default
+}
+
+// TaskResult is the outcome of one install or uninstall step.
+type TaskResult struct {
+	Name     string
+	Status   string // "complete", "failed" or "skipped"
+	Err      string
+	Fix      string // what to do about Err
+	Note     string
+	Duration time.Duration
+	Optional bool // a failure here does not fail the run
+}
+
+// Result is what Install and Uninstall did.
+type Result struct {
+	Tasks     []TaskResult
+	Warnings  []string // "source: message"
+	Removed   []string // what Uninstall deleted
+	BackupDir string   // where copies of the files changed were kept, if any
+	NoChanges bool     // the install was already in place; nothing was modified
+	Duration  time.Duration
+}
+
+// Check is one Doctor diagnostic.
+type Check struct {
+	Name    string
+	Passed  bool
+	Warning bool // a failure that does not break the install
+	Message string
+}
+
+// Install installs the plugin and configures OpenCode to use it. It returns
+// an error if a pre-install check or a required task fails; the Result then
+// shows how far it got. Cancelling ctx stops the install and rolls it back.
+func Install(ctx context.Context, opts Options) (Result, error) {
+	// Fetching models needs cursor-agent to reach Cursor
+	if opts.NoNetwork && opts.ModelsFile == "" {
+		return Result{}, fmt.Errorf("NoNetwork cannot fetch models from cursor-agent; set ModelsFile")
+	}
+	m, release, err := newAPIModel(ctx, opts, true)
+	if err != nil {
+		return Result{}, err
+	}
+	defer release()
+
+	var blocking []string
+	for _, check := range m.checks {
+		if !check.passed && !check.warning {
+			blocking = append(blocking, check.name+": "+check.message)
+		}
+	}
+	if len(blocking) > 0 {
+		return Result{}, fmt.Errorf("pre-install checks failed: %s", strings.Join(blocking, "; "))
+	}
+	if m.needsLogin() && m.modelsFile == "" {
+		return Result{}, fmt.Errorf("cursor-agent is not logged in - run `cursor-agent login`")
+	}
+
+	m.step = stepInstalling
+	m.tasks = buildInstallPlan(&m.installContext)
+	m.currentTaskIndex = 0
+	return apiResult(runTasks(m, nil, nil))
+}
+
+// Uninstall removes what Install added. The pre-install checks are not run.
+func Uninstall(ctx context.Context, opts Options) (Result, error) {
+	m, release, err := newAPIModel(ctx, opts, false)
+	if err != nil {
+		return Result{}, err
+	}
+	defer release()
+
+	m.step = stepUninstalling
+	m.isUninstall = true
+	m.tasks = m.uninstallTasks()
+	m.currentTaskIndex = 0
+	return apiResult(runTasks(m, nil, nil))
+}
+
+// Doctor inspects an existing install without changing anything. It returns
+// an error if any check found a problem that breaks the install.
+func Doctor(ctx context.Context, opts Options) ([]Check, error) {
+	m, release, err := newAPIModel(ctx, opts, false)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	var checks []Check
+	problems := 0
+	for _, check := range doctorChecks(&m) {
+		checks = append(checks, Check{Name: check.name, Passed: check.passed, Warning: check.warning, Message: check.message})
+		if !check.passed && !check.warning {
+			problems++
+		}
+	}
+	if problems > 0 {
+		return checks, fmt.Errorf("%d problems found", problems)
+	}
+	return checks, nil
+}
+
+// settings builds the run's options from opts directly, checking each value
+// as its flag would be checked. Combinations that only matter to installing
+// are left to Install.
+func (opts Options) settings() (cliOptions, error) {
+	settings := cliOptions{
+		headless:      true,
+		noLog:         opts.LogFile == "",
+		logPath:       opts.LogFile,
+		configPath:    opts.ConfigPath,
+		modelsFile:    opts.ModelsFile,
+		dryRun:        opts.DryRun,
+		skipBuild:     opts.SkipBuild,
+		force:         opts.Force,
+		noRollback:    opts.NoRollback,
+		deepVerify:    opts.DeepVerify,
+		resume:        opts.Resume,
+		noNetwork:     opts.NoNetwork,
+		modelsTTL:     defaultModelCacheTTL,
+		acpSdkVersion: defaultAcpSdkVersion,
+		aiSdkVersion:  opts.AiSdkVersion,
+		mergeStrategy: defaultMergeStrategy,
+		pkgManager:    opts.PackageManager,
+		ref:           opts.Ref,
+		timeout:       opts.Timeout,
+		runner:        opts.Runner,
+		verifyRetries: defaultVerifyRetries,
+		verifyTimeout: defaultVerifyTimeout,
+	}
+
+	var err error
+	if opts.NetworkProxy != "" {
+		if settings.proxy, err = parseProxyURL(opts.NetworkProxy); err != nil {
+			return settings, err
+		}
+	}
+	if opts.ModelsEndpoint != "" {
+		if settings.modelsURL, err = parseModelsEndpoint(opts.ModelsEndpoint); err != nil {
+			return settings, err
+		}
+	}
+	if strings.HasPrefix(opts.Ref, "-") {
+		return settings, fmt.Errorf("invalid Ref %q", opts.Ref)
+	}
+	if opts.MergeStrategy != "" {
+		if !slices.Contains(mergeStrategies, opts.MergeStrategy) {
+			return settings, fmt.Errorf("invalid MergeStrategy %q (use %s)", opts.MergeStrategy, strings.Join(mergeStrategies, ", "))
+		}
+		settings.mergeStrategy = opts.MergeStrategy
+	}
+	if opts.PackageManager != "" && !slices.Contains(packageManagerNames(), opts.PackageManager) {
+		return settings, fmt.Errorf("invalid PackageManager %q (supported: %s)", opts.PackageManager, strings.Join(packageManagerNames(), ", "))
+	}
+	for _, sdk := range []struct {
+		name, version string
+		into          *string
+	}{
+		{"AcpSdkVersion", opts.AcpSdkVersion, &settings.acpSdkVersion},
+		{"AiSdkVersion", opts.AiSdkVersion, &settings.aiSdkVersion},
+	} {
+		if sdk.version == "" {
+			continue
+		}
+		if !validPackageVersion(sdk.version) {
+			return settings, fmt.Errorf("invalid %s %q (expected a version or range like ^1.2.0)", sdk.name, sdk.version)
+		}
+		*sdk.into = sdk.version
+	}
+	if len(opts.ExcludeModels) > 0 {
+		if settings.excludeModels, err = parseModelPatterns("ExcludeModels", strings.Join(opts.ExcludeModels, ",")); err != nil {
+			return settings, err
+		}
+	}
+	if len(opts.OnlyModels) > 0 {
+		if settings.onlyModels, err = parseModelPatterns("OnlyModels", strings.Join(opts.OnlyModels, ",")); err != nil {
+			return settings, err
+		}
+	}
+	for key, value := range opts.ProviderOptions {
+		key, decoded, err := parseProviderOption(key + "=" + value)
+		if err != nil {
+			return settings, err
+		}
+		if settings.providerOpts == nil {
+			settings.providerOpts = make(map[string]interface{})
+		}
+		settings.providerOpts[key] = decoded
+	}
+
+	if opts.OpenCodePath != "" {
+		if settings.opencodePath, err = exec.LookPath(opts.OpenCodePath); err != nil {
+			return settings, fmt.Errorf("invalid OpenCodePath: %w", err)
+		}
+	}
+	for _, dir := range []struct {
+		name, path string
+		into       *string
+	}{
+		{"PluginDir", opts.PluginDir, &settings.pluginDir},
+		{"BackupDir", opts.BackupDir, &settings.backupDir},
+		{"ProjectDir", opts.ProjectDir, &settings.projectDir},
+	} {
+		if dir.path == "" {
+			continue
+		}
+		abs, err := filepath.Abs(dir.path)
+		if err != nil {
+			return settings, fmt.Errorf("invalid %s %q: %w", dir.name, dir.path, err)
+		}
+		*dir.into = abs
+	}
+	if settings.projectDir != "" {
+		if info, err := os.Stat(settings.projectDir); err != nil || !info.IsDir() {
+			return settings, fmt.Errorf("project directory %s does not exist", settings.projectDir)
+		}
+	}
+
+	if opts.Timeout < 0 {
+		return settings, fmt.Errorf("invalid Timeout %v", opts.Timeout)
+	}
+	if opts.VerifyRetries != 0 {
+		settings.verifyRetries = max(opts.VerifyRetries, 0)
+	}
+	if opts.VerifyTimeout < 0 {
+		return settings, fmt.Errorf("invalid VerifyTimeout %v", opts.VerifyTimeout)
+	}
+	if opts.VerifyTimeout > 0 {
+		settings.verifyTimeout = opts.VerifyTimeout
+	}
+
+	port := opts.Port
+	if port == "auto" || port == "0" {
+		settings.autoPort = true
+		port = ""
+	}
+	if opts.BaseURL != "" || port != "" {
+		if settings.baseURL, err = resolveBaseURL(opts.BaseURL, port); err != nil {
+			return settings, err
+		}
+	}
+
+	if opts.Force && opts.SkipBuild {
+		return settings, fmt.Errorf("Force rebuilds the plugin and cannot be combined with SkipBuild")
+	}
+	if opts.Ref != "" && opts.SkipBuild {
+		return settings, fmt.Errorf("Ref builds the plugin and cannot be combined with SkipBuild")
+	}
+	if opts.NoNetwork && opts.DeepVerify {
+		return settings, fmt.Errorf("DeepVerify talks to Cursor and cannot be combined with NoNetwork")
+	}
+	return settings, nil
+}
+
+// newAPIModel builds the headless model the exported functions drive, and a
+// func that releases its log file and context. Only an install needs the
+// pre-install checks; without checks none of their commands, temp files or
+// port probes happen.
+func newAPIModel(ctx context.Context, opts Options, checks bool) (model, func(), error) {
+	parsed, err := opts.settings()
+	if err != nil {
+		return model{}, nil, err
+	}
+	parsed.skipChecks = !checks
+	logFile, err := openLogFile(parsed)
+	if err != nil {
+		return model{}, nil, err
+	}
+	m := newModel(ctx, parsed, logFile)
+	release := func() {
+		m.cancel()
+		if logFile != nil {
+			logFile.Close()
+		}
+	}
+	return m, release, nil
+}
+
+// apiResult converts a finished run to a Result, with an error if it failed
+func apiResult(m model) (Result, error) {
+	result := Result{Removed: m.state.removed, BackupDir: m.writtenBackupDir(), NoChanges: m.changedNothing(), Duration: time.Since(m.startedAt)}
+	for _, warning := range m.allWarnings() {
+		result.Warnings = append(result.Warnings, warning.String())
+	}
+	var failure error
+	for _, task := range m.tasks {
+		report := task.report()
+		result.Tasks = append(result.Tasks, TaskResult{
+			Name:     report.Name,
+			Status:   report.Status,
+			Err:      report.Err,
+			Fix:      report.Fix,
+			Note:     report.Note,
+			Duration: task.duration,
+			Optional: report.Optional,
+		})
+		if task.status == statusFailed && !task.optional && failure == nil {
+			failure = fmt.Errorf("%s: %s", report.Name, report.Err)
+		}
+	}
+	if m.cancelled {
+		return result, m.ctx.Err()
+	}
+	return result, failure
+}
@@ -0,0 +1,192 @@
+// internal/installer/backups.go
+package installer
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	backupDirName     = ".cursor-acp-backups"
+	backupTimeLayout  = "20060102-150405.000"
+	maxBackupsPerFile = 10
+)
+
+var backupNameRegex = regexp.MustCompile(`^(.+)\.(\d{8}-\d{6}\.\d{3})$`)
+
+// diskBackup is a timestamped copy of a file in the backup directory
+type diskBackup struct {
+	name    string // e.g. opencode.json.20260101-120000.000
+	path    string
+	base    string // name of the original file, e.g. opencode.json
+	created time.Time
+}
+
+// getBackupDir returns ~/.config/opencode/.cursor-acp-backups, where backups
+// go unless --backup-dir says otherwise
+func getBackupDir() string {
+	configDir, _ := getConfigDir()
+	return filepath.Join(configDir, "opencode", backupDirName)
+}
+
+// backupConfigToDisk writes data to dir as a timestamped backup of path and
+// prunes old backups of the same file, returning the backup's location.
+func backupConfigToDisk(dir, path string, data []byte) (string, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", err
+	}
+
+	base := filepath.Base(path)
+	backupPath := filepath.Join(dir, fmt.Sprintf("%s.%s", base, time.Now().Format(backupTimeLayout)))
+	if err := os.WriteFile(backupPath, data, 0644); err != nil {
+		return "", err
+	}
+
+	pruneBackups(dir, base, maxBackupsPerFile)
+	return backupPath, nil
+}
+
+// listBackups returns the backups in dir, newest first
+func listBackups(dir string) ([]diskBackup, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var backups []diskBackup
+	for _, entry := range entries {
+		matches := backupNameRegex.FindStringSubmatch(entry.Name())
+		if entry.IsDir() || matches == nil {
+			continue
+		}
+		created, err := time.ParseInLocation(backupTimeLayout, matches[2], time.Local)
+		if err != nil {
+			continue
+		}
+		backups = append(backups, diskBackup{
+			name:    entry.Name(),
+			path:    filepath.Join(dir, entry.Name()),
+			base:    matches[1],
+			created: created,
+		})
+	}
+
+	sort.Slice(backups, func(i, j int) bool {
+		return backups[i].created.After(backups[j].created)
+	})
+	return backups, nil
+}
+
+// pruneBackups deletes all but the newest keep backups of base
+func pruneBackups(dir, base string, keep int) {
+	backups, err := listBackups(dir)
+	if err != nil {
+		return
+	}
+	kept := 0
+	for _, b := range backups {
+		if b.base != base {
+			continue
+		}
+		kept++
+		if kept > keep {
+			os.Remove(b.path)
+		}
+	}
+}
+
+// backupTarget maps a backup back to the file it was taken from
+func backupTarget(m *model, b diskBackup) string {
+	if b.base == filepath.Base(m.configPath) {
+		return m.configPath
+	}
+	if b.base == "cursor-acp.js" {
+		return filepath.Join(m.pluginDir, b.base)
+	}
+	configDir, _ := getConfigDir()
+	return filepath.Join(configDir, "opencode", b.base)
+}
+
+// runRestore lists on-disk backups and restores the one chosen by number or
+// name, either from args or (interactively) from stdin.
+func runRestore(m model, args []string) int {
+	dir := m.backupDir
+	backups, err := listBackups(dir)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: failed to read %s: %v\n", dir, err)
+		return 1
+	}
+	if len(backups) == 0 {
+		fmt.Printf("No backups found in %s\n", dir)
+		return 1
+	}
+
+	fmt.Printf("Backups in %s:\n\n", dir)
+	for i, b := range backups {
+		fmt.Printf("  %2d) %s  -> %s\n", i+1, b.name, backupTarget(&m, b))
+	}
+	fmt.Println()
+
+	choice := ""
+	if len(args) > 0 {
+		choice = args[0]
+	} else if !m.headless {
+		fmt.Print("Restore which backup? [number, Enter to cancel]: ")
+		line, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+		choice = strings.TrimSpace(line)
+	}
+	if choice == "" {
+		return 0
+	}
+
+	var selected *diskBackup
+	if n, err := strconv.Atoi(choice); err == nil && n >= 1 && n <= len(backups) {
+		selected = &backups[n-1]
+	} else {
+		for i := range backups {
+			if backups[i].name == choice {
+				selected = &backups[i]
+			}
+		}
+	}
+	if selected == nil {
+		fmt.Fprintf(os.Stderr, "Error: no backup matches %q\n", choice)
+		return 1
+	}
+
+	data, err := os.ReadFile(selected.path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: failed to read backup: %v\n", err)
+		return 1
+	}
+
+	target := backupTarget(&m, *selected)
+	// Snapshot the current file first so the restore itself can be undone
+	if err := createBackup(&m.installContext, target); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+	if err := writeFileAtomic(target, data, 0644); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: failed to restore %s: %v\n", target, err)
+		return 1
+	}
+	if err := chownToActualUser(target); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: could not give %s back to %s: %v\n", target, getActualUser(), err)
+	}
+
+	fmt.Printf("Restored %s from %s\n", target, selected.name)
+	if err := validateJSON(target); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: restored file is not valid JSON: %v\n", err)
+	}
+	return 0
+}
@@ -0,0 +1,954 @@
+// internal/installer/cli.go
+package installer
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/charmbracelet/bubbles/progress"
+	"github.com/charmbracelet/bubbles/spinner"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// installerVersion is recorded in the install manifest; release builds set it
+// with -ldflags "-X github.com/nomadcxx/opencode-cursor/internal/installer.installerVersion=<version>".
+var installerVersion = "dev"
+
+// cliOptions holds the flags parsed from the command line.
+type cliOptions struct {
+	command    string   // optional subcommand, e.g. "update"
+	args       []string // positional arguments after the subcommand
+	debugMode  bool
+	noRollback bool
+	dryRun     bool
+	headless   bool
+	jsonOutput bool
+	quiet      bool // headless with only failures and a one-line summary
+	protocol   bool // headless, driven by a front-end over stdin/stdout; see protocol.go
+
+	refreshModels bool
+	modelsTTL     time.Duration
+	baseURL       string        // resolved from --base-url/--port; empty keeps the config's value
+	autoPort      bool          // --port auto/0: pick a free port at install time
+	configPath    string        // --config; empty uses ~/.config/opencode/opencode.json
+	skipBuild     bool          // use an existing dist/ instead of running bun build
+	modelsFile    string        // --models-from-file; bypasses cursor-agent
+	modelsURL     string        // --models-endpoint: HTTP endpoint listing the models; cursor-agent is the fallback
+	captureModels string        // --capture-models-output: file for the raw and cleaned `cursor-agent models` output
+	deepVerify    bool          // send a real chat completion after install
+	uninstall     bool          // --uninstall: skip the menu and remove the install
+	force         bool          // --force/repair: rebuild and rewrite the provider from scratch
+	acpSdkVersion string        // version/range for @agentclientprotocol/sdk
+	aiSdkVersion  string        // version/range for @ai-sdk/openai-compatible; empty installs latest
+	pkgManager    string        // --package-manager; empty auto-detects
+	excludeModels []string      // --exclude-models: IDs or globs to leave out
+	onlyModels    []string      // --only-models: IDs or globs to keep
+	logPath       string        // --log-file; empty uses a temp file
+	opencodePath  string        // --opencode-path: which opencode binary to use
+	noLog         bool          // --no-log: write no log at all
+	noAnimation   bool          // --no-animation: a static TUI; see animationsDisabled
+	projectDir    string        // --project-dir; empty finds it with getProjectDir
+	timeout       time.Duration // --timeout: deadline for all the tasks together; 0 is none
+	flags         []string      // the arguments as given, for the log header
+	verifyRetries int           // --verify-retries: extra `opencode models` attempts after install
+	verifyTimeout time.Duration // --verify-timeout: total budget for those attempts
+	pluginDir     string        // --plugin-dir; empty uses the plugin directory beside the config
+	resume        bool          // --resume: skip what the last failed install already did
+	backupDir     string        // --backup-dir; empty uses getBackupDir
+	proxy         string        // --proxy: HTTP(S) proxy for child processes
+	printConfig   bool          // --print-config: print the merged config and exit
+	checkOnly     bool          // --check-only: run the pre-install checks and exit
+	detectPlugin  bool          // --detect-plugin-dir: print where the plugin would be linked and exit
+	record        string        // --record: file to append every command, write and symlink to
+	replay        string        // --replay: a --record file to perform again, then exit
+	noNetwork     bool          // --no-network: use only local artifacts; see checkOfflineArtifacts
+	ref           string        // --ref: git ref of the plugin source to build; see checkoutRef
+	autoFix       bool          // --auto-fix: offer to run the fixes of failed checks (headless)
+	mergeStrategy string        // --merge-strategy: how fetched models combine with configured ones
+	runner        CommandRunner // runs the external commands; newModel defaults it to execRunner
+	skipChecks    bool          // leave out the pre-install checks; the API's Uninstall and Doctor have no use for them
+
+	// --provider-option: extra entries for the provider's options, JSON-decoded
+	providerOpts map[string]interface{}
+}
+
+func parseArgs(args []string) (cliOptions, error) {
+	opts := cliOptions{
+		modelsTTL:     defaultModelCacheTTL,
+		acpSdkVersion: defaultAcpSdkVersion,
+		mergeStrategy: defaultMergeStrategy,
+		flags:         args,
+		verifyRetries: defaultVerifyRetries,
+		verifyTimeout: defaultVerifyTimeout,
+	}
+	var baseURL, port string
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		name, value, hasValue := strings.Cut(arg, "=")
+		// takeValue returns the value of "--flag=value" or "--flag value"
+		takeValue := func() (string, error) {
+			if hasValue {
+				return value, nil
+			}
+			if i+1 >= len(args) {
+				return "", fmt.Errorf("%s requires a value", name)
+			}
+			i++
+			return args[i], nil
+		}
+
+		switch name {
+		case "--debug", "-d":
+			opts.debugMode = true
+		case "--no-rollback":
+			opts.noRollback = true
+		case "--dry-run":
+			opts.dryRun = true
+		case "--headless", "--yes", "-y":
+			opts.headless = true
+		case "--json":
+			// JSON lines on stdout cannot share the terminal with the TUI
+			opts.jsonOutput = true
+			opts.headless = true
+		case "--quiet", "-q":
+			opts.quiet = true
+			opts.headless = true
+		case "--protocol":
+			opts.protocol = true
+			opts.headless = true
+		case "--print-config":
+			// Nothing to ask: the config goes to stdout as is
+			opts.printConfig = true
+			opts.headless = true
+		case "--check-only":
+			opts.checkOnly = true
+			opts.headless = true
+		case "--detect-plugin-dir":
+			opts.detectPlugin = true
+			opts.headless = true
+		case "--skip-build":
+			opts.skipBuild = true
+		case "--deep-verify":
+			opts.deepVerify = true
+		case "--no-network":
+			opts.noNetwork = true
+		case "--auto-fix":
+			opts.autoFix = true
+		case "--uninstall":
+			opts.uninstall = true
+		case "--force":
+			opts.force = true
+		case "--resume":
+			opts.resume = true
+		case "--refresh-models":
+			opts.refreshModels = true
+		case "--models-ttl":
+			v, err := takeValue()
+			if err != nil {
+				return opts, err
+			}
+			ttl, err := time.ParseDuration(v)
+			if err != nil || ttl < 0 {
+				return opts, fmt.Errorf("invalid --models-ttl %q (expected a duration like 30m)", v)
+			}
+			opts.modelsTTL = ttl
+		case "--timeout":
+			v, err := takeValue()
+			if err != nil {
+				return opts, err
+			}
+			timeout, err := time.ParseDuration(v)
+			if err != nil || timeout <= 0 {
+				return opts, fmt.Errorf("invalid --timeout %q (expected a duration like 10m)", v)
+			}
+			opts.timeout = timeout
+		case "--verify-retries":
+			v, err := takeValue()
+			if err != nil {
+				return opts, err
+			}
+			retries, err := strconv.Atoi(v)
+			if err != nil || retries < 0 {
+				return opts, fmt.Errorf("invalid --verify-retries %q (expected a number like 3)", v)
+			}
+			opts.verifyRetries = retries
+		case "--verify-timeout":
+			v, err := takeValue()
+			if err != nil {
+				return opts, err
+			}
+			budget, err := time.ParseDuration(v)
+			if err != nil || budget <= 0 {
+				return opts, fmt.Errorf("invalid --verify-timeout %q (expected a duration like 30s)", v)
+			}
+			opts.verifyTimeout = budget
+		case "--proxy":
+			v, err := takeValue()
+			if err != nil {
+				return opts, err
+			}
+			if opts.proxy, err = parseProxyURL(v); err != nil {
+				return opts, err
+			}
+		case "--base-url":
+			v, err := takeValue()
+			if err != nil {
+				return opts, err
+			}
+			baseURL = v
+		case "--port":
+			v, err := takeValue()
+			if err != nil {
+				return opts, err
+			}
+			port = v
+		case "--ref":
+			v, err := takeValue()
+			if err != nil {
+				return opts, err
+			}
+			if strings.HasPrefix(v, "-") {
+				return opts, fmt.Errorf("invalid --ref %q", v)
+			}
+			opts.ref = v
+		case "--merge-strategy":
+			v, err := takeValue()
+			if err != nil {
+				return opts, err
+			}
+			if !slices.Contains(mergeStrategies, v) {
+				return opts, fmt.Errorf("invalid --merge-strategy %q (use %s)", v, strings.Join(mergeStrategies, ", "))
+			}
+			opts.mergeStrategy = v
+		case "--provider-option":
+			v, err := takeValue()
+			if err != nil {
+				return opts, err
+			}
+			key, value, err := parseProviderOption(v)
+			if err != nil {
+				return opts, err
+			}
+			if _, dup := opts.providerOpts[key]; dup {
+				return opts, fmt.Errorf("--provider-option %s given more than once", key)
+			}
+			if opts.providerOpts == nil {
+				opts.providerOpts = make(map[string]interface{})
+			}
+			opts.providerOpts[key] = value
+		case "--models-endpoint":
+			v, err := takeValue()
+			if err != nil {
+				return opts, err
+			}
+			if opts.modelsURL, err = parseModelsEndpoint(v); err != nil {
+				return opts, err
+			}
+		case "--record", "--replay":
+			v, err := takeValue()
+			if err != nil {
+				return opts, err
+			}
+			if name == "--record" {
+				opts.record = v
+			} else {
+				opts.replay = v
+			}
+		case "--capture-models-output":
+			v, err := takeValue()
+			if err != nil {
+				return opts, err
+			}
+			opts.captureModels = v
+		case "--models-from-file":
+			v, err := takeValue()
+			if err != nil {
+				return opts, err
+			}
+			opts.modelsFile = v
+		case "--acp-sdk-version", "--ai-sdk-version":
+			v, err := takeValue()
+			if err != nil {
+				return opts, err
+			}
+			if !validPackageVersion(v) {
+				return opts, fmt.Errorf("invalid %s %q (expected a version or range like ^1.2.0)", name, v)
+			}
+			if name == "--acp-sdk-version" {
+				opts.acpSdkVersion = v
+			} else {
+				opts.aiSdkVersion = v
+			}
+		case "--package-manager":
+			v, err := takeValue()
+			if err != nil {
+				return opts, err
+			}
+			if !slices.Contains(packageManagerNames(), v) {
+				return opts, fmt.Errorf("invalid --package-manager %q (supported: %s)", v, strings.Join(packageManagerNames(), ", "))
+			}
+			opts.pkgManager = v
+		case "--exclude-models", "--only-models":
+			v, err := takeValue()
+			if err != nil {
+				return opts, err
+			}
+			patterns, err := parseModelPatterns(name, v)
+			if err != nil {
+				return opts, err
+			}
+			if name == "--exclude-models" {
+				opts.excludeModels = patterns
+			} else {
+				opts.onlyModels = patterns
+			}
+		case "--log-file":
+			v, err := takeValue()
+			if err != nil {
+				return opts, err
+			}
+			opts.logPath = v
+		case "--no-log":
+			opts.noLog = true
+		case "--no-animation":
+			opts.noAnimation = true
+		case "--opencode-path":
+			v, err := takeValue()
+			if err != nil {
+				return opts, err
+			}
+			path, err := exec.LookPath(v)
+			if err != nil {
+				return opts, fmt.Errorf("invalid --opencode-path: %w", err)
+			}
+			opts.opencodePath = path
+		case "--config":
+			v, err := takeValue()
+			if err != nil {
+				return opts, err
+			}
+			opts.configPath = v
+		case "--plugin-dir":
+			v, err := takeValue()
+			if err != nil {
+				return opts, err
+			}
+			dir, err := filepath.Abs(v)
+			if err != nil {
+				return opts, fmt.Errorf("invalid --plugin-dir %q: %w", v, err)
+			}
+			opts.pluginDir = dir
+		case "--backup-dir":
+			v, err := takeValue()
+			if err != nil {
+				return opts, err
+			}
+			dir, err := filepath.Abs(v)
+			if err != nil {
+				return opts, fmt.Errorf("invalid --backup-dir %q: %w", v, err)
+			}
+			opts.backupDir = dir
+		case "--project-dir":
+			v, err := takeValue()
+			if err != nil {
+				return opts, err
+			}
+			dir, err := filepath.Abs(v)
+			if err != nil {
+				return opts, fmt.Errorf("invalid --project-dir %q: %w", v, err)
+			}
+			if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+				return opts, fmt.Errorf("project directory %s does not exist", dir)
+			}
+			opts.projectDir = dir
+		default:
+			if strings.HasPrefix(arg, "-") {
+				return opts, fmt.Errorf("unknown flag: %s", arg)
+			}
+			if opts.command == "" {
+				opts.command = arg
+			} else {
+				opts.args = append(opts.args, arg)
+			}
+		}
+	}
+
+	if opts.noLog && opts.logPath != "" {
+		return opts, fmt.Errorf("--log-file and --no-log cannot be combined")
+	}
+	if opts.quiet && opts.jsonOutput {
+		return opts, fmt.Errorf("--quiet and --json cannot be combined")
+	}
+	if opts.protocol && (opts.quiet || opts.jsonOutput) {
+		return opts, fmt.Errorf("--protocol cannot be combined with --quiet or --json")
+	}
+	if opts.autoFix && opts.protocol {
+		return opts, fmt.Errorf("--auto-fix asks on the terminal and cannot be combined with --protocol")
+	}
+	// `repair` is a forced install, not a subcommand of its own
+	if opts.command == "repair" {
+		opts.command = ""
+		opts.force = true
+	}
+	if opts.printConfig && (opts.uninstall || opts.protocol || opts.jsonOutput || opts.command != "") {
+		return opts, fmt.Errorf("--print-config cannot be combined with --uninstall, --protocol, --json or a command")
+	}
+	if opts.checkOnly && (opts.uninstall || opts.protocol || opts.printConfig || opts.autoFix || opts.command != "") {
+		return opts, fmt.Errorf("--check-only cannot be combined with --uninstall, --protocol, --print-config, --auto-fix or a command")
+	}
+	if opts.detectPlugin && (opts.uninstall || opts.protocol || opts.printConfig || opts.checkOnly || opts.command != "") {
+		return opts, fmt.Errorf("--detect-plugin-dir cannot be combined with --uninstall, --protocol, --print-config, --check-only or a command")
+	}
+	if opts.replay != "" && (opts.record != "" || opts.uninstall || opts.protocol || opts.printConfig || opts.checkOnly || opts.detectPlugin || opts.command != "") {
+		return opts, fmt.Errorf("--replay cannot be combined with --record, --uninstall, --protocol, --print-config, --check-only, --detect-plugin-dir or a command")
+	}
+	if opts.force && opts.skipBuild {
+		return opts, fmt.Errorf("--force rebuilds the plugin and cannot be combined with --skip-build")
+	}
+	if opts.ref != "" && opts.skipBuild {
+		return opts, fmt.Errorf("--ref builds the plugin and cannot be combined with --skip-build")
+	}
+	// Fetching models needs cursor-agent to reach Cursor, and --deep-verify
+	// sends a real completion through it
+	if opts.noNetwork && opts.modelsFile == "" && !opts.uninstall && (opts.command == "" || opts.command == "update") {
+		return opts, fmt.Errorf("--no-network cannot fetch models from cursor-agent; pass --models-from-file")
+	}
+	if opts.captureModels != "" && opts.modelsFile != "" {
+		return opts, fmt.Errorf("--capture-models-output records cursor-agent's output and cannot be combined with --models-from-file")
+	}
+	if opts.noNetwork && opts.deepVerify {
+		return opts, fmt.Errorf("--deep-verify talks to Cursor and cannot be combined with --no-network")
+	}
+
+	if port == "auto" || port == "0" {
+		opts.autoPort = true
+		port = ""
+	}
+	if baseURL != "" || port != "" {
+		resolved, err := resolveBaseURL(baseURL, port)
+		if err != nil {
+			return opts, err
+		}
+		opts.baseURL = resolved
+	}
+	return opts, nil
+}
+
+func newModel(ctx context.Context, opts cliOptions, logFile *os.File) model {
+	s := spinner.New()
+	s.Style = lipgloss.NewStyle().Foreground(Secondary)
+	s.Spinner = spinner.Dot
+
+	p := progress.New(progress.WithSolidFill(string(Secondary)), progress.WithoutPercentage())
+	p.EmptyColor = string(FgMuted)
+
+	if opts.runner == nil {
+		opts.runner = execRunner{}
+	}
+	// --proxy goes on each child's environment, not the installer's
+	if opts.proxy != "" {
+		opts.runner = proxyRunner{opts.runner, opts.proxy}
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+
+	// --opencode-path picks the install; otherwise the shell's (first on PATH)
+	// is used, and the TUI asks when there is more than one
+	installs := detectOpenCodeInstalls(opts.runner)
+	opencode := OpenCodeInfo{Installed: false}
+	if opts.opencodePath != "" {
+		opencode = inspectOpenCode(opts.runner, opts.opencodePath)
+	} else if len(installs) > 0 {
+		opencode = installs[0]
+	}
+
+	// Detect paths; the chosen install's config dir unless --config is given
+	container := inContainer()
+	projectDir := opts.projectDir
+	if projectDir == "" {
+		projectDir = getProjectDir(container)
+	}
+	configOverride := opts.configPath
+	if configOverride == "" && opencode.Installed {
+		configOverride = filepath.Join(opencode.ConfigDir, "opencode.json")
+	}
+	pluginDir, pluginDirFrom := opts.pluginDir, "--plugin-dir"
+	if pluginDir == "" {
+		if defaultConfig, _, err := opencodePaths(configOverride); err == nil {
+			pluginDir, pluginDirFrom = detectPluginDir(opts.runner, opencode, defaultConfig)
+		}
+	}
+	existingSetup, configPath := detectExistingSetup(configOverride, pluginDir)
+	npmTag := os.Getenv("CURSOR_ACP_NPM_TAG")
+	if npmTag == "" {
+		npmTag = "latest"
+	}
+
+	m := model{
+		installContext:   newInstallContext(ctx, opts, logFile, projectDir, pluginDir, configPath, npmTag),
+		step:             stepWelcome,
+		tasks:            []installTask{},
+		spinner:          s,
+		progress:         p,
+		errors:           []string{},
+		warnings:         []installWarning{},
+		startedAt:        time.Now(),
+		headless:         opts.headless,
+		autoFix:          opts.autoFix,
+		jsonOutput:       opts.jsonOutput,
+		quiet:            opts.quiet,
+		showLog:          opts.debugMode,
+		ticking:          true, // started by Init
+		spinning:         true,
+		cancel:           cancel,
+		existingSetup:    existingSetup,
+		opencodeInstalls: installs,
+
+		beams:  nil,
+		ticker: NewTypewriterTicker(),
+	}
+	m.container = container
+	if animationsDisabled(opts.noAnimation) {
+		m.static = true
+		m.ticking = false
+		m.ticker = NewStaticTicker()
+	}
+	m.pluginDirFrom = pluginDirFrom
+	m.useOpenCode(opencode)
+	writeLogHeader(&m, opts)
+	var resumeProblem string
+	if opts.resume {
+		resumeProblem = m.loadResume()
+	}
+
+	// Run pre-install checks
+	if !opts.skipChecks {
+		m.checks = runPreInstallChecks(opts, configPath, projectDir, opencode, container)
+	}
+	m.checkOpts = opts
+	if opts.resume {
+		if resumeProblem != "" {
+			m.checks = append(m.checks, checkResult{name: "resume", passed: false, warning: true, message: resumeProblem + "; running every task"})
+		} else {
+			m.checks = append(m.checks, checkResult{name: "resume", passed: true,
+				message: fmt.Sprintf("%d tasks done by the run at %s", len(m.resumeFrom.Completed), m.resumeFrom.UpdatedAt.Format("2006-01-02 15:04"))})
+		}
+	}
+	if len(installs) > 1 && opts.opencodePath == "" {
+		// With --protocol the front-end is asked instead
+		if opts.headless && !opts.protocol {
+			m.checks = append(m.checks, checkResult{name: "OpenCode installs", passed: false, warning: true,
+				message: fmt.Sprintf("%d found; using %s (choose with --opencode-path)", len(installs), opencode.BinaryPath)})
+		} else {
+			m.step = stepSelectOpenCode
+		}
+	}
+
+	return m
+}
+
+func newInstallContext(ctx context.Context, opts cliOptions, logFile *os.File, projectDir, pluginDir, configPath, npmTag string) installContext {
+	backupDir := opts.backupDir
+	if backupDir == "" {
+		backupDir = getBackupDir()
+	}
+	return installContext{
+		ctx:           ctx,
+		logFile:       logFile,
+		projectDir:    projectDir,
+		pluginDir:     pluginDir,
+		configPath:    configPath,
+		npmTag:        npmTag,
+		runner:        opts.runner,
+		debugMode:     opts.debugMode,
+		noRollback:    opts.noRollback,
+		dryRun:        opts.dryRun,
+		refreshModels: opts.refreshModels,
+		modelsTTL:     opts.modelsTTL,
+		baseURL:       opts.baseURL,
+		autoPort:      opts.autoPort,
+		skipBuild:     opts.skipBuild,
+		modelsFile:    opts.modelsFile,
+		modelsURL:     opts.modelsURL,
+		captureModels: opts.captureModels,
+		providerOpts:  opts.providerOpts,
+		deepVerify:    opts.deepVerify,
+		noNetwork:     opts.noNetwork,
+		ref:           opts.ref,
+		mergeStrategy: opts.mergeStrategy,
+		force:         opts.force,
+		acpSdkVersion: opts.acpSdkVersion,
+		aiSdkVersion:  opts.aiSdkVersion,
+		pkgManager:    opts.pkgManager,
+		proxy:         opts.proxy,
+		excludeModels: opts.excludeModels,
+		onlyModels:    opts.onlyModels,
+		timeout:       opts.timeout,
+		verifyRetries: opts.verifyRetries,
+		verifyTimeout: opts.verifyTimeout,
+		pluginDirSet:  opts.pluginDir != "",
+		backupDir:     backupDir,
+		backupFiles:   make(map[string]backupEntry),
+		diskBackups:   make(map[string]string),
+		undo:          &undoLog{},
+		state:         &installState{},
+	}
+}
+
+func runPreInstallChecks(opts cliOptions, configPath, projectDir string, opencode OpenCodeInfo, container bool) []checkResult {
+	var checks []checkResult
+	skipBuild := opts.skipBuild
+
+	// Check for a package manager (only a warning with --skip-build, which
+	// never builds; an existing AI SDK install is then reused)
+	if pm, err := detectPackageManager(opts.pkgManager); err == nil {
+		checks = append(checks, checkResult{name: "package manager", passed: true, message: pm.name})
+		if pm.name == "bun" {
+			versionCheck := checkToolVersion(opts.runner, requiredTools[0])
+			if skipBuild && !versionCheck.passed {
+				versionCheck.warning = true
+			}
+			checks = append(checks, versionCheck)
+		}
+	} else {
+		check := checkResult{name: "package manager", passed: false, message: err.Error(), warning: skipBuild}
+		if opts.pkgManager == "" || opts.pkgManager == "bun" {
+			check.fixCmd = installFix("bun")
+		}
+		checks = append(checks, check)
+	}
+
+	// Check cursor-agent
+	if commandExists("cursor-agent") {
+		checks = append(checks, checkResult{name: "cursor-agent", passed: true, message: "installed"})
+		checks = append(checks, checkToolVersion(opts.runner, requiredTools[1]))
+		checks = append(checks, checkLogin(opts.runner, container))
+	} else {
+		checks = append(checks, checkResult{name: "cursor-agent", passed: false, message: "not found - install with: " + installCommand("cursor-agent"),
+			fixCmd: installFix("cursor-agent")})
+	}
+
+	// Verify plugin loads the build with node, or bun without it
+	checks = append(checks, checkNode(opts.runner))
+
+	// Check OpenCode installation
+	checks = append(checks, openCodeChecks(opencode)...)
+
+	// Check OpenCode config directory
+	if configPath != "" {
+		opencodeDir := filepath.Dir(configPath)
+		if _, err := os.Stat(opencodeDir); err == nil {
+			checks = append(checks, checkResult{name: "OpenCode config", passed: true, message: opencodeDir})
+		} else {
+			checks = append(checks, checkResult{name: "OpenCode config", passed: true, message: "will create: " + opencodeDir, warning: true})
+		}
+		// A config that does not parse blocks a headless run; the TUI offers
+		// to back it up and start over
+		if problem := configSyntaxProblem(configPath); problem != "" {
+			offerRepair := (!opts.headless || opts.protocol) && !opts.dryRun
+			message := "invalid JSON: " + problem
+			if offerRepair && !opts.protocol {
+				message += " - Enter offers to back it up and reset it"
+			}
+			checks = append(checks, checkResult{name: configSyntaxCheckName, passed: false, message: message, warning: offerRepair})
+		}
+		checks = append(checks, checkConfigWritable(configPath))
+		if opts.pluginDir != "" {
+			checks = append(checks, checkPluginDir(opts.pluginDir, opencode, configPath))
+		}
+		checks = append(checks, checkDiskSpace("config disk", opencodeDir))
+	}
+	// Only a source build writes to the project (node_modules, dist/)
+	if !skipBuild && projectDir != "" {
+		checks = append(checks, checkDiskSpace("project disk", projectDir))
+	}
+	if skipBuild && projectDir != "" {
+		checks = append(checks, checkPrebuiltPlugin(projectDir))
+	}
+	if opts.noNetwork {
+		checks = append(checks, checkOfflineArtifacts(opts, projectDir)...)
+	}
+	if opts.ref != "" && projectDir != "" {
+		checks = append(checks, checkGitRef(opts.runner, projectDir, opts.ref))
+	}
+
+	// Check the proxy port: the flag's value, else what's configured, else the default
+	baseURL := opts.baseURL
+	if baseURL == "" && configPath != "" {
+		if config, _, err := readConfig(configPath); err == nil {
+			baseURL = configuredBaseURL(config)
+		}
+	}
+	if baseURL == "" {
+		baseURL = defaultBaseURL
+	}
+	if !opts.autoPort {
+		checks = append(checks, checkPortAvailable(baseURL))
+	}
+
+	return checks
+}
+
+// checkPrebuiltPlugin looks at the dist/ --skip-build will link. A build
+// older than its source is only a warning: --skip-build asked for it as is.
+func checkPrebuiltPlugin(projectDir string) checkResult {
+	distPath := filepath.Join(projectDir, "dist", "plugin-entry.js")
+	info, err := os.Stat(distPath)
+	if err != nil || info.Size() == 0 {
+		return checkResult{name: "prebuilt plugin", passed: false, message: distPath + " not found - build it first or drop --skip-build"}
+	}
+	if distStale(projectDir, info.ModTime()) {
+		return checkResult{name: "prebuilt plugin", passed: false, warning: true,
+			message: fmt.Sprintf("%s (built %s) is older than src/ - rebuild or drop --skip-build", distPath, info.ModTime().Format("2006-01-02 15:04"))}
+	}
+	return checkResult{name: "prebuilt plugin", passed: true, message: distPath + " is up to date"}
+}
+
+// checkOfflineArtifacts checks --no-network has what the install would
+// otherwise download: the models file, the project's node_modules for a
+// source build, and OpenCode's AI SDK. Any of them missing blocks the install.
+func checkOfflineArtifacts(opts cliOptions, projectDir string) []checkResult {
+	var checks []checkResult
+	if _, err := os.Stat(opts.modelsFile); err != nil {
+		checks = append(checks, checkResult{name: "offline models", passed: false, message: "--no-network: models file " + opts.modelsFile + " not found"})
+	} else {
+		checks = append(checks, checkResult{name: "offline models", passed: true, message: opts.modelsFile})
+	}
+
+	if !opts.skipBuild && projectDir != "" {
+		nodeModules := filepath.Join(projectDir, "node_modules")
+		if info, err := os.Stat(nodeModules); err != nil || !info.IsDir() {
+			checks = append(checks, checkResult{name: "offline dependencies", passed: false,
+				message: fmt.Sprintf("--no-network: %s not found - run `bun install` in %s first or pass --skip-build", nodeModules, projectDir)})
+		} else {
+			checks = append(checks, checkResult{name: "offline dependencies", passed: true, message: nodeModules})
+		}
+	}
+
+	// installAiSdk always uses OpenCode's own config directory, whatever --config says
+	if configDir, err := getConfigDir(); err == nil {
+		opencodeDir := filepath.Join(configDir, "opencode")
+		sdk := filepath.Join(opencodeDir, "node_modules", "@ai-sdk", "openai-compatible", "package.json")
+		if _, err := os.Stat(sdk); err != nil {
+			checks = append(checks, checkResult{name: "offline AI SDK", passed: false,
+				message: fmt.Sprintf("--no-network: @ai-sdk/openai-compatible not installed - run `bun add @ai-sdk/openai-compatible` in %s first", opencodeDir)})
+		} else {
+			checks = append(checks, checkResult{name: "offline AI SDK", passed: true, message: filepath.Dir(sdk)})
+		}
+	}
+	return checks
+}
+
+// openCodeChecks describes the OpenCode install being configured
+func openCodeChecks(info OpenCodeInfo) []checkResult {
+	if !info.Installed {
+		return []checkResult{{name: "OpenCode", passed: false, message: "not found - install with: " + installCommand("opencode"),
+			fixCmd: installFix("opencode")}}
+	}
+	return []checkResult{
+		{name: "OpenCode", passed: true, message: info.describe()},
+		{name: "OpenCode binary", passed: true, message: info.BinaryPath},
+	}
+}
+
+const (
+	loginCheckName        = "cursor-agent login"
+	configSyntaxCheckName = "config syntax"
+)
+
+// checkLogin checks for a cursor-agent login. A container's is usually
+// mounted or provided at run time rather than made in the image, so there it
+// is only noted.
+func checkLogin(runner CommandRunner, container bool) checkResult {
+	if container {
+		return checkResult{name: loginCheckName, passed: true, message: "not checked in a container"}
+	}
+	if cursorAgentLoggedIn(runner) {
+		return checkResult{name: loginCheckName, passed: true, message: "logged in"}
+	}
+	return checkResult{name: loginCheckName, passed: false, message: "not logged in - run: cursor-agent login", warning: true,
+		fixCmd: []string{"cursor-agent", "login"}}
+}
+
+func (m model) Init() tea.Cmd {
+	if m.static {
+		return tea.Batch(m.spinner.Tick, m.startCmd)
+	}
+	return tea.Batch(
+		m.spinner.Tick,
+		tickCmd(),
+		m.startCmd,
+	)
+}
+
+// openLogFile returns the log to write: the --log-file path (appended to),
+// nil for --no-log, or else a new temp file. Failing to create the temp file
+// only disables logging; an explicit --log-file that cannot be opened is an error.
+func openLogFile(opts cliOptions) (*os.File, error) {
+	if opts.noLog {
+		return nil, nil
+	}
+	if opts.logPath == "" {
+		logFile, err := os.CreateTemp("", "opencode-cursor-installer-*.log")
+		if err != nil {
+			return nil, nil
+		}
+		return logFile, nil
+	}
+
+	// Absolute so the path shown on the completion screen works from anywhere
+	path, err := filepath.Abs(opts.logPath)
+	if err != nil {
+		return nil, fmt.Errorf("invalid --log-file %q: %w", opts.logPath, err)
+	}
+	logFile, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("cannot open --log-file: %w", err)
+	}
+	chownToActualUser(path)
+	return logFile, nil
+}
+
+// rollbackAfterSignal undoes whatever the interrupted install had changed
+// (unless --no-rollback) and returns the conventional 128+signal exit code.
+func rollbackAfterSignal(final tea.Model, sig os.Signal, logFile *os.File) int {
+	fmt.Fprintf(os.Stderr, "Installation interrupted by %v\n", sig)
+	if fm, ok := final.(model); ok && !fm.noRollback && fm.pendingUndo() {
+		if err := rollbackInstall(&fm.installContext); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: rollback failed: %v\n", err)
+		} else {
+			fmt.Fprintln(os.Stderr, "Rolled back changes")
+		}
+	}
+	if logFile != nil {
+		logFile.WriteString(fmt.Sprintf("Interrupted by %v\n", sig))
+		logFile.Sync()
+		fmt.Fprintf(os.Stderr, "See logs: %s\n", logFile.Name())
+	}
+	if s, ok := sig.(syscall.Signal); ok {
+		return 128 + int(s)
+	}
+	return 130
+}
+
+func tickCmd() tea.Cmd {
+	return tea.Tick(time.Millisecond*50, func(t time.Time) tea.Msg {
+		return tickMsg(t)
+	})
+}
+
+// Main runs the installer CLI on os.Args and exits the process when done.
+func Main() {
+	opts, err := parseArgs(os.Args[1:])
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(2)
+	}
+	if opts.replay != "" {
+		os.Exit(runReplay(opts))
+	}
+
+	logFile, err := openLogFile(opts)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(2)
+	}
+	// os.Exit skips deferred calls, so every way out closes the files here
+	exit := func(code int) {
+		stopRecording()
+		if logFile != nil {
+			logFile.Close()
+		}
+		os.Exit(code)
+	}
+	// Before newModel, whose pre-install checks already run commands
+	if opts.record != "" {
+		projectDir := opts.projectDir
+		if projectDir == "" {
+			projectDir = getProjectDir(inContainer())
+		}
+		if err := startRecording(opts.record, projectDir, opts.flags); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			exit(2)
+		}
+		opts.runner = recordingRunner{execRunner{}}
+	}
+	m := newModel(context.Background(), opts, logFile)
+
+	if opts.command != "" {
+		exit(runSubcommand(m, opts.command, opts.args))
+	}
+
+	if opts.checkOnly {
+		exit(runCheckOnly(m))
+	}
+
+	if opts.detectPlugin {
+		exit(runDetectPluginDir(m))
+	}
+
+	if opts.printConfig {
+		exit(runPrintConfig(m))
+	}
+
+	if opts.headless {
+		run := runHeadless
+		if opts.uninstall {
+			run = runHeadlessUninstall
+		}
+		if opts.protocol {
+			run = func(m model) int { return runProtocol(m, opts.uninstall) }
+		}
+		exit(run(m))
+	}
+
+	if opts.uninstall {
+		// Skip the menu and go straight to confirming the removal
+		m = m.confirmUninstall()
+	}
+
+	p := tea.NewProgram(m, tea.WithAltScreen())
+	globalProgram = p
+
+	// Ctrl+C arrives as a key in raw mode, but a SIGTERM from a CI timeout (or
+	// a kill -INT) would otherwise leave the alt-screen up and backups in place.
+	// Quit right away; rollback happens below once the terminal is restored.
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(signals)
+	var received atomic.Value
+	go func() {
+		sig := <-signals
+		received.Store(sig)
+		m.cancel()
+		if logFile != nil {
+			logFile.Sync()
+		}
+		p.Quit()
+	}()
+
+	final, err := p.Run()
+	if sig, ok := received.Load().(os.Signal); ok {
+		exit(rollbackAfterSignal(final, sig, logFile))
+	}
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		exit(1)
+	}
+	if fm, ok := final.(model); ok && fm.cancelled {
+		fmt.Fprintf(os.Stderr, "Installation %s\n", strings.Join(fm.errors, "; "))
+		exit(fm.cancelledExitCode())
+	}
+	exit(0)
+}
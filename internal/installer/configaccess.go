@@ -0,0 +1,94 @@
+// internal/installer/configaccess.go
+package installer
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"syscall"
+)
+
+const configWritableCheckName = "config writable"
+
+// makeWritableCommand is the command that clears a file's read-only bit
+func makeWritableCommand(path string) string {
+	if runtime.GOOS == "windows" {
+		return "attrib -r " + path
+	}
+	return "chmod u+w " + path
+}
+
+// configAccessProblem explains why path could not be written, with the fix,
+// for the permission and read-only filesystem errors a raw errno does not
+// make clear. It returns "" for any other error.
+func configAccessProblem(path string, err error) (problem, fix string) {
+	switch {
+	case errors.Is(err, syscall.EROFS):
+		return "it is on a read-only filesystem",
+			"Point --config at a writable copy, or change the file where it is managed (e.g. your dotfiles or home-manager setup)"
+	case errors.Is(err, fs.ErrPermission):
+		return "permission denied",
+			fmt.Sprintf("Make it writable with `%s` (and check you own its directory), then re-run", makeWritableCommand(path))
+	}
+	return "", ""
+}
+
+// configWriteError is the error for a failed write of the config: a CONFIG
+// error saying why and what to do when it was not allowed, else err as is
+func configWriteError(path string, err error) error {
+	problem, fix := configAccessProblem(path, err)
+	if problem == "" {
+		return fmt.Errorf("failed to write config: %w", err)
+	}
+	configErr := NewConfigError("cannot write the config: "+problem, path, err)
+	configErr.Remediation = fix
+	return configErr
+}
+
+// checkConfigWritable is the pre-install check that the config can be
+// written before any slow build work: the file a symlinked config points to,
+// its mode, and the directory the atomic write replaces it in. A config that
+// does not exist yet is left to the config disk check.
+func checkConfigWritable(configPath string) checkResult {
+	path := configPath
+	if resolved, err := filepath.EvalSymlinks(configPath); err == nil {
+		path = resolved
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return checkResult{name: configWritableCheckName, passed: true, message: "will create: " + configPath}
+	}
+
+	if perm := info.Mode().Perm(); perm&0200 == 0 {
+		return checkResult{name: configWritableCheckName, passed: false,
+			message: fmt.Sprintf("%s is read-only (mode %04o) - make it writable with: %s", path, perm, makeWritableCommand(path))}
+	}
+	f, err := os.OpenFile(path, os.O_WRONLY, 0)
+	if err == nil {
+		f.Close()
+		// The write goes to a temp file beside it that is renamed over it
+		var probe *os.File
+		if probe, err = os.CreateTemp(filepath.Dir(path), ".cursor-acp-write-test-*"); err == nil {
+			probe.Close()
+			os.Remove(probe.Name())
+		}
+	}
+	if err != nil {
+		message := fmt.Sprintf("cannot write %s: %v", path, err)
+		switch problem, _ := configAccessProblem(path, err); {
+		case errors.Is(err, syscall.EROFS):
+			message = fmt.Sprintf("cannot write %s: %s - point --config at a writable copy", path, problem)
+		case problem != "":
+			message = fmt.Sprintf("cannot write %s or its directory: %s - check you own them (e.g. %s)", path, problem, makeWritableCommand(filepath.Dir(path)))
+		}
+		return checkResult{name: configWritableCheckName, passed: false, message: message}
+	}
+
+	if path != configPath {
+		return checkResult{name: configWritableCheckName, passed: true, message: configPath + " -> " + path}
+	}
+	return checkResult{name: configWritableCheckName, passed: true, message: path}
+}
@@ -0,0 +1,54 @@
+// internal/installer/configlock.go
+package installer
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const configLockTimeout = 5 * time.Second
+
+// errLockHeld is returned by tryLockFile when another process holds the lock
+var errLockHeld = errors.New("lock held by another process")
+
+// lockConfig takes an exclusive advisory lock on <configPath>.lock so two
+// installers cannot interleave their read-modify-write of opencode.json. The
+// lock is tied to the open file, so a crashed installer never leaves it held.
+// The lock file itself is left in place: unlinking it would let a waiter lock
+// an orphaned inode while a newcomer locks a fresh one.
+func lockConfig(configPath string) (func(), error) {
+	lockPath := configPath + ".lock"
+	if _, err := os.Stat(filepath.Dir(lockPath)); os.IsNotExist(err) {
+		// Nothing to edit yet, and we must not create directories just to lock
+		return func() {}, nil
+	}
+
+	f, err := os.OpenFile(lockPath, os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		return nil, NewConfigError("failed to open config lock", lockPath, err)
+	}
+
+	deadline := time.Now().Add(configLockTimeout)
+	for {
+		err := tryLockFile(f)
+		if err == nil {
+			break
+		}
+		if !errors.Is(err, errLockHeld) {
+			f.Close()
+			return nil, NewConfigError("failed to lock config", lockPath, err)
+		}
+		if time.Now().After(deadline) {
+			f.Close()
+			return nil, NewConfigError("another installer is running - wait for it to finish and retry", lockPath, nil)
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+
+	return func() {
+		unlockFile(f)
+		f.Close()
+	}, nil
+}
@@ -0,0 +1,200 @@
+// internal/installer/deepverify.go
+package installer
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	deepVerifyPrompt       = "Reply with the single word: ok"
+	deepVerifyStartTimeout = 20 * time.Second
+	deepVerifyTimeout      = 90 * time.Second
+)
+
+// deepVerify sends one minimal chat completion through the plugin's proxy
+// (--deep-verify). It consumes a request from the Cursor subscription, so it
+// is opt-in, and a failure is reported as a warning rather than rolling back
+// an otherwise working install.
+func deepVerify(ic *installContext) error {
+	if ic.dryRun {
+		return skipTask("nothing installed in dry-run mode")
+	}
+
+	config, _, err := readConfig(ic.configPath)
+	if err != nil {
+		return err
+	}
+	baseURL := ic.state.autoPortURL
+	if baseURL == "" {
+		baseURL = configuredBaseURL(config)
+	}
+	if baseURL == "" {
+		baseURL = defaultBaseURL
+	}
+	modelID := deepVerifyModel(configuredModels(config))
+
+	reply, err := runDeepVerify(ic, baseURL, modelID)
+	if err != nil {
+		if ic.ctx.Err() != nil {
+			return err
+		}
+		ic.state.warn("deep verify",
+			"chat completion failed: %v. The install itself is fine; check `cursor-agent status` shows you logged in, then try `opencode run -m cursor-acp/%s hi`", err, modelID)
+		return skipTask("chat completion failed (see warnings)")
+	}
+	ic.state.note = fmt.Sprintf("%s replied %q", modelID, summarizeRawOutput(reply))
+	return nil
+}
+
+// runDeepVerify sends the completion through withProxy
+func runDeepVerify(ic *installContext, baseURL, modelID string) (string, error) {
+	var reply string
+	_, err := withProxy(ic.ctx, ic.logFile, ic.opencodeBin, ic.proxy, baseURL, func() error {
+		ctx, cancel := context.WithTimeout(ic.ctx, deepVerifyTimeout)
+		defer cancel()
+		var err error
+		reply, err = chatCompletion(ctx, ic.proxy, baseURL, modelID, deepVerifyPrompt)
+		return err
+	})
+	return reply, err
+}
+
+// withProxy runs fn against an already-running proxy at baseURL, or starts one
+// by running `opencode serve` (which loads the plugin) for the duration of fn.
+// It returns how long a started proxy took to come up, or zero if one was
+// already running.
+func withProxy(ctx context.Context, logFile *os.File, opencodeBin, proxy, baseURL string, fn func() error) (time.Duration, error) {
+	u, err := url.Parse(baseURL)
+	if err != nil {
+		return 0, fmt.Errorf("invalid baseURL %q: %w", baseURL, err)
+	}
+	if isCursorProxy(u.Host) {
+		return 0, fn()
+	}
+	if _, err := exec.LookPath(opencodeBin); err != nil {
+		return 0, fmt.Errorf("no proxy on %s and opencode is not installed to start one", u.Host)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	serve := exec.CommandContext(ctx, opencodeBin, "serve")
+	setProxyEnv(serve, proxy)
+	if logFile != nil {
+		serve.Stdout = logFile
+		serve.Stderr = logFile
+	}
+	started := time.Now()
+	if err := serve.Start(); err != nil {
+		recorder.command(serve, err)
+		return 0, fmt.Errorf("failed to start opencode serve: %w", err)
+	}
+	recorder.server(serve)
+	// exited is closed once serve has been reaped so both the wait loop
+	// and the cleanup below can observe it
+	exited := make(chan struct{})
+	go func() {
+		serve.Wait()
+		close(exited)
+	}()
+	defer func() {
+		cancel()
+		<-exited
+	}()
+
+	if err := waitForProxy(ctx, u.Host, exited); err != nil {
+		return 0, err
+	}
+	startup := time.Since(started)
+	return startup, fn()
+}
+
+// waitForProxy polls the proxy's /health endpoint until it answers, giving up
+// early if opencode serve exits.
+func waitForProxy(ctx context.Context, addr string, exited <-chan struct{}) error {
+	deadline := time.After(deepVerifyStartTimeout)
+	for !isCursorProxy(addr) {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-exited:
+			return fmt.Errorf("opencode serve exited before the proxy came up on %s", addr)
+		case <-deadline:
+			return fmt.Errorf("proxy did not come up on %s within %s", addr, deepVerifyStartTimeout)
+		case <-time.After(500 * time.Millisecond):
+		}
+	}
+	return nil
+}
+
+// chatCompletion issues a non-streaming /chat/completions request and returns
+// the first choice's content.
+func chatCompletion(ctx context.Context, proxy, baseURL, modelID, prompt string) (string, error) {
+	body, err := json.Marshal(map[string]interface{}{
+		"model":    modelID,
+		"stream":   false,
+		"messages": []map[string]string{{"role": "user", "content": prompt}},
+	})
+	if err != nil {
+		return "", err
+	}
+
+	endpoint := strings.TrimSuffix(baseURL, "/") + "/chat/completions"
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := httpClient(proxy).Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("%s returned %s: %s", endpoint, resp.Status, summarizeRawOutput(string(data)))
+	}
+
+	var completion struct {
+		Choices []struct {
+			Message struct {
+				Content string `json:"content"`
+			} `json:"message"`
+		} `json:"choices"`
+	}
+	if err := json.Unmarshal(data, &completion); err != nil {
+		return "", fmt.Errorf("unexpected response from %s: %w", endpoint, err)
+	}
+	if len(completion.Choices) == 0 || strings.TrimSpace(completion.Choices[0].Message.Content) == "" {
+		return "", fmt.Errorf("%s returned an empty completion", endpoint)
+	}
+	return strings.TrimSpace(completion.Choices[0].Message.Content), nil
+}
+
+// deepVerifyModel prefers "auto" and otherwise the first configured model
+func deepVerifyModel(models map[string]interface{}) string {
+	if _, ok := models["auto"]; ok || len(models) == 0 {
+		return "auto"
+	}
+	ids := make([]string, 0, len(models))
+	for id := range models {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids[0]
+}
@@ -0,0 +1,57 @@
+// internal/installer/diskspace.go
+package installer
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// minFreeSpace is what a from-source install needs: node_modules for the
+// project plus the opencode packages, with headroom.
+const minFreeSpace = 200 << 20
+
+// checkDiskSpace verifies dir (or the nearest parent that exists yet) can be
+// written to and has minFreeSpace available, so a full disk or read-only
+// mount fails here rather than halfway through `bun install`.
+func checkDiskSpace(name, dir string) checkResult {
+	existing := existingParent(dir)
+
+	f, err := os.CreateTemp(existing, ".cursor-acp-write-test-*")
+	if err != nil {
+		return checkResult{name: name, passed: false, message: fmt.Sprintf("%s is not writable: %v", existing, err)}
+	}
+	f.Close()
+	os.Remove(f.Name())
+
+	free, err := freeSpace(existing)
+	if err != nil {
+		// Writable is what matters most; an unknown free space is only worth a warning
+		return checkResult{name: name, passed: false, message: fmt.Sprintf("%s is writable, free space unknown: %v", existing, err), warning: true}
+	}
+	if free < minFreeSpace {
+		return checkResult{name: name, passed: false, message: fmt.Sprintf("only %s free on %s (need %s; short by %s)",
+			formatSize(free), existing, formatSize(minFreeSpace), formatSize(minFreeSpace-free))}
+	}
+	return checkResult{name: name, passed: true, message: fmt.Sprintf("%s writable, %s free", existing, formatSize(free))}
+}
+
+// existingParent returns dir or its closest ancestor that exists
+func existingParent(dir string) string {
+	for p := filepath.Clean(dir); ; p = filepath.Dir(p) {
+		if _, err := os.Stat(p); err == nil || p == filepath.Dir(p) {
+			return p
+		}
+	}
+}
+
+func formatSize(bytes uint64) string {
+	switch {
+	case bytes >= 1<<30:
+		return fmt.Sprintf("%.1f GB", float64(bytes)/(1<<30))
+	case bytes >= 1<<20:
+		return fmt.Sprintf("%d MB", bytes>>20)
+	default:
+		return fmt.Sprintf("%d KB", bytes>>10)
+	}
+}
@@ -0,0 +1,16 @@
+// internal/installer/diskspace_unix.go
+
+//go:build !windows
+
+package installer
+
+import "syscall"
+
+// freeSpace returns the bytes available to unprivileged users on path's filesystem
+func freeSpace(path string) (uint64, error) {
+	var st syscall.Statfs_t
+	if err := syscall.Statfs(path, &st); err != nil {
+		return 0, err
+	}
+	return uint64(st.Bavail) * uint64(st.Bsize), nil
+}
@@ -0,0 +1,20 @@
+// internal/installer/diskspace_windows.go
+
+//go:build windows
+
+package installer
+
+import "golang.org/x/sys/windows"
+
+// freeSpace returns the bytes available to the current user on path's volume
+func freeSpace(path string) (uint64, error) {
+	p, err := windows.UTF16PtrFromString(path)
+	if err != nil {
+		return 0, err
+	}
+	var available uint64
+	if err := windows.GetDiskFreeSpaceEx(p, &available, nil, nil); err != nil {
+		return 0, err
+	}
+	return available, nil
+}
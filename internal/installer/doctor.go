@@ -0,0 +1,129 @@
+// internal/installer/doctor.go
+package installer
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// runDoctor runs read-only diagnostics against an existing install and
+// returns a non-zero exit code if any blocking check fails.
+func runDoctor(m model) int {
+	checks := doctorChecks(&m)
+
+	fmt.Println("cursor-acp doctor:")
+	fmt.Println()
+	fmt.Print(renderChecks(checks))
+	fmt.Println()
+
+	for _, check := range checks {
+		if !check.passed && !check.warning {
+			fmt.Println("Problems found. Re-run the installer to repair the install.")
+			return 1
+		}
+	}
+	fmt.Println("No problems found.")
+	return 0
+}
+
+// doctorChecks inspects the symlink, config, packages and OpenCode without mutating anything.
+func doctorChecks(m *model) []checkResult {
+	var checks []checkResult
+
+	// Plugin symlink
+	symlinkPath := filepath.Join(m.pluginDir, "cursor-acp.js")
+	if target, err := os.Readlink(symlinkPath); err != nil {
+		if info, statErr := os.Lstat(symlinkPath); statErr == nil && info.Mode().IsRegular() && info.Size() > 0 {
+			// Copied in place of a symlink (Windows fallback)
+			checks = append(checks, checkResult{name: "plugin symlink", passed: true, message: symlinkPath + " (copied plugin)"})
+		} else if statErr == nil {
+			checks = append(checks, checkResult{name: "plugin symlink", passed: false, message: symlinkPath + " is not a symlink or plugin file"})
+		} else {
+			checks = append(checks, checkResult{name: "plugin symlink", passed: false, message: "missing: " + symlinkPath})
+		}
+	} else if info, err := os.Stat(symlinkPath); err != nil {
+		checks = append(checks, checkResult{name: "plugin symlink", passed: false, message: "dangling: " + symlinkPath + " -> " + target})
+	} else if info.Size() == 0 {
+		checks = append(checks, checkResult{name: "plugin entry", passed: false, message: "empty file: " + target})
+	} else {
+		checks = append(checks, checkResult{name: "plugin symlink", passed: true, message: symlinkPath + " -> " + target})
+	}
+
+	// Config
+	checks = append(checks, doctorConfigCheck(m.configPath, m.baseURL))
+	if manifest, err := readManifest(m.configPath); err == nil && manifest != nil && manifest.InstallerVersion != "" {
+		if manifest.InstallerVersion == installerVersion {
+			checks = append(checks, checkResult{name: "installer version", passed: true, message: "config written by this installer (" + installerVersion + ")"})
+		} else {
+			checks = append(checks, checkResult{name: "installer version", passed: false, message: fmt.Sprintf("config written by %s, running %s - re-run the installer to update it", manifest.InstallerVersion, installerVersion), warning: true})
+		}
+	}
+
+	// Packages under the opencode node_modules
+	nodeModules := getOpenCodeNodeModulesDir()
+	packages := []struct {
+		name     string
+		optional bool
+	}{
+		{name: "@ai-sdk/openai-compatible"},
+		{name: "@agentclientprotocol/sdk", optional: true},
+	}
+	for _, pkg := range packages {
+		pkgPath := filepath.Join(nodeModules, pkg.name)
+		if _, err := os.Stat(filepath.Join(pkgPath, "package.json")); err == nil {
+			message := pkgPath
+			if version := packageJSONVersion(filepath.Join(pkgPath, "package.json")); version != "" {
+				message += " (" + version + ")"
+			}
+			checks = append(checks, checkResult{name: pkg.name, passed: true, message: message})
+		} else {
+			checks = append(checks, checkResult{name: pkg.name, passed: false, message: "not found in " + nodeModules, warning: pkg.optional})
+		}
+	}
+
+	// OpenCode sees the provider
+	var skip *taskSkipError
+	if err := verifyPostInstall(&m.installContext); errors.As(err, &skip) {
+		checks = append(checks, checkResult{name: "opencode models", passed: true, message: skip.note})
+	} else if err != nil {
+		checks = append(checks, checkResult{name: "opencode models", passed: false, message: summarizeRawOutput(err.Error())})
+	} else {
+		checks = append(checks, checkResult{name: "opencode models", passed: true, message: "cursor-acp listed"})
+	}
+
+	return checks
+}
+
+// doctorConfigCheck validates the cursor-acp provider in configPath. When
+// expectedBaseURL is set (--base-url/--port), the configured value must match it.
+func doctorConfigCheck(configPath, expectedBaseURL string) checkResult {
+	data, err := os.ReadFile(configPath)
+	if err != nil {
+		return checkResult{name: "opencode.json", passed: false, message: fmt.Sprintf("cannot read %s: %v", configPath, err)}
+	}
+
+	var config map[string]interface{}
+	if err := parseJSONC(data, &config); err != nil {
+		return checkResult{name: "opencode.json", passed: false, message: fmt.Sprintf("invalid JSON in %s: %v", configPath, err)}
+	}
+
+	providers, _ := config["provider"].(map[string]interface{})
+	if _, ok := providers["cursor-acp"].(map[string]interface{}); !ok {
+		return checkResult{name: "opencode.json", passed: false, message: "cursor-acp provider missing from " + configPath}
+	}
+
+	baseURL := configuredBaseURL(config)
+	if baseURL == "" {
+		return checkResult{name: "opencode.json", passed: false, message: "cursor-acp provider has no options.baseURL"}
+	}
+	if _, err := resolveBaseURL(baseURL, ""); err != nil {
+		return checkResult{name: "opencode.json", passed: false, message: "cursor-acp options.baseURL is malformed: " + baseURL}
+	}
+	if expectedBaseURL != "" && baseURL != expectedBaseURL {
+		return checkResult{name: "opencode.json", passed: false, message: fmt.Sprintf("cursor-acp -> %s (expected %s)", baseURL, expectedBaseURL), warning: true}
+	}
+
+	return checkResult{name: "opencode.json", passed: true, message: "cursor-acp -> " + baseURL}
+}
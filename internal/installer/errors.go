@@ -0,0 +1,193 @@
+package installer
+
+import (
+	"errors"
+	"fmt"
+	"runtime"
+	"strings"
+)
+
+func summarizeRawOutput(raw string) string {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return ""
+	}
+
+	lines := strings.Split(raw, "\n")
+	for i := len(lines) - 1; i >= 0; i-- {
+		line := strings.TrimSpace(lines[i])
+		if line == "" {
+			continue
+		}
+		if len(line) > 220 {
+			return line[:220] + "..."
+		}
+		return line
+	}
+
+	if len(raw) > 220 {
+		return raw[:220] + "..."
+	}
+	return raw
+}
+
+type InstallerError struct {
+	Category    string
+	Message     string
+	Details     string
+	RawOutput   string
+	Cause       error
+	Recoverable bool
+	Remediation string // set when the error knows its own fix; beats the category's
+}
+
+func (e *InstallerError) Error() string {
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("[%s] %s", e.Category, e.Message))
+	if e.Details != "" {
+		b.WriteString(fmt.Sprintf(": %s", e.Details))
+	}
+	if summary := summarizeRawOutput(e.RawOutput); summary != "" {
+		b.WriteString(fmt.Sprintf(" | output: %s", summary))
+	}
+	if e.Cause != nil {
+		b.WriteString(fmt.Sprintf(" (cause: %v)", e.Cause))
+	}
+	return b.String()
+}
+
+func (e *InstallerError) Unwrap() error {
+	return e.Cause
+}
+
+func NewParseError(msg, rawOutput string, cause error) *InstallerError {
+	return &InstallerError{
+		Category:    "PARSE",
+		Message:     msg,
+		RawOutput:   rawOutput,
+		Cause:       cause,
+		Recoverable: false,
+	}
+}
+
+func NewConfigError(msg, path string, cause error) *InstallerError {
+	return &InstallerError{
+		Category:    "CONFIG",
+		Message:     msg,
+		Details:     path,
+		Cause:       cause,
+		Recoverable: true,
+	}
+}
+
+func NewExecError(msg, output string, cause error) *InstallerError {
+	return &InstallerError{
+		Category:    "EXEC",
+		Message:     msg,
+		RawOutput:   output,
+		Cause:       cause,
+		Recoverable: false,
+	}
+}
+
+func NewValidationError(msg, details string, cause error) *InstallerError {
+	return &InstallerError{
+		Category:    "VALIDATE",
+		Message:     msg,
+		Details:     details,
+		Cause:       cause,
+		Recoverable: true,
+	}
+}
+
+// categoryRemediation is the generic next step for each InstallerError
+// category; tasks with a more specific fix set installTask.remediation.
+var categoryRemediation = map[string]string{
+	"EXEC":     "Run the failing command by hand to see its full output, or re-run with --debug",
+	"PARSE":    "Update cursor-agent with `cursor-agent update`; re-run with --debug to see the raw output",
+	"CONFIG":   "Check the file named above exists, is writable and is valid JSON; `restore` rolls opencode.json back to a backup",
+	"VALIDATE": "Re-run the installer; if it keeps failing, roll back with the `restore` command",
+}
+
+// errorCategoryUnknown is the category of an error that is not an InstallerError
+const errorCategoryUnknown = "UNKNOWN"
+
+// errorCategories lists every category in the order the completion screen
+// groups them: things the user can fix first, unknowns last
+var errorCategories = []string{"CONFIG", "VALIDATE", "EXEC", "PARSE", errorCategoryUnknown}
+
+// categoryHint says what a category of failure usually means
+var categoryHint = map[string]string{
+	"CONFIG":             "A file the installer reads or writes is missing, unreadable or not valid JSON",
+	"VALIDATE":           "A step finished, but checking its result failed",
+	"EXEC":               "A command the installer ran exited with an error",
+	"PARSE":              "A tool's output was not in the format the installer expects",
+	errorCategoryUnknown: "An unexpected error; the log has the details",
+}
+
+// errorCategory returns err's InstallerError category, or UNKNOWN
+func errorCategory(err error) string {
+	var installerErr *InstallerError
+	if errors.As(err, &installerErr) && installerErr.Category != "" {
+		return installerErr.Category
+	}
+	return errorCategoryUnknown
+}
+
+// unparsedOutput returns the full output a PARSE error could not make sense
+// of, for the completion screen to show in full
+func unparsedOutput(err error) string {
+	var installerErr *InstallerError
+	if errors.As(err, &installerErr) && installerErr.Category == "PARSE" {
+		return strings.TrimSpace(installerErr.RawOutput)
+	}
+	return ""
+}
+
+// remediationFor returns the category's next step for an InstallerError
+func remediationFor(err error) string {
+	var installerErr *InstallerError
+	if errors.As(err, &installerErr) {
+		if installerErr.Remediation != "" {
+			return installerErr.Remediation
+		}
+		return categoryRemediation[installerErr.Category]
+	}
+	return ""
+}
+
+// hasSpecificRemediation reports whether err's remediation should win over the
+// task's: a CONFIG error names a file the user has to fix, and an explicit
+// Remediation is specific by definition.
+func hasSpecificRemediation(err error) bool {
+	var installerErr *InstallerError
+	return errors.As(err, &installerErr) && (installerErr.Category == "CONFIG" || installerErr.Remediation != "")
+}
+
+// symlinkRemediation explains the usual cause of symlink failures per platform
+func symlinkRemediation() string {
+	if runtime.GOOS == "windows" {
+		return "Enable Developer Mode (Settings > System > For developers) so symlinks can be created, or run the installer as administrator"
+	}
+	return "Check you own the OpenCode plugin directory (e.g. `ls -ld ~/.config/opencode/plugin`) and it is not read-only"
+}
+
+// taskSkipError signals that a task deliberately did nothing (for example in
+// --dry-run mode). It is reported as statusSkipped rather than a failure.
+type taskSkipError struct {
+	note string
+}
+
+func (e *taskSkipError) Error() string {
+	return e.note
+}
+
+func skipTask(format string, args ...interface{}) error {
+	return &taskSkipError{note: fmt.Sprintf(format, args...)}
+}
+
+// alreadyDone is skipTask for work an earlier install left in place, so
+// running the installer again changes nothing it does not have to
+func alreadyDone(format string, args ...interface{}) error {
+	return skipTask("no change: "+format, args...)
+}
@@ -0,0 +1,187 @@
+// internal/installer/fixes.go
+package installer
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// installCommands is how the pre-install checks tell the user to install
+// each prerequisite, by runtime.GOOS; "" is Linux and any other Unix. The
+// Unix install scripts run on macOS too, and are used there without Homebrew.
+var installCommands = map[string]map[string]string{
+	"bun": {
+		"windows": `powershell -c "irm bun.sh/install.ps1 | iex"`,
+		"darwin":  "brew install oven-sh/bun/bun",
+		"":        "curl -fsSL https://bun.sh/install | bash",
+	},
+	"cursor-agent": {
+		"windows": `powershell -c "irm 'https://cursor.com/install?win32=true' | iex"`,
+		"":        "curl -fsS https://cursor.com/install | bash",
+	},
+	"opencode": {
+		"windows": "scoop install extras/opencode",
+		"darwin":  "brew install opencode",
+		"":        "curl -fsSL https://opencode.ai/install | bash",
+	},
+}
+
+// installCommand is the command that installs tool here
+func installCommand(tool string) string {
+	return installCommandFor(tool, runtime.GOOS, commandExists("brew"))
+}
+
+// installCommandFor picks tool's install command for goos
+func installCommandFor(tool, goos string, hasBrew bool) string {
+	if goos == "darwin" && !hasBrew {
+		goos = ""
+	}
+	if command, ok := installCommands[tool][goos]; ok {
+		return command
+	}
+	return installCommands[tool][""]
+}
+
+// installFix is the fix that runs installCommand(tool): a script with bash,
+// a Homebrew command as is. It is nil on Windows, where the command is for
+// the user to paste into PowerShell.
+func installFix(tool string) []string {
+	command := installCommand(tool)
+	switch {
+	case runtime.GOOS == "windows":
+		return nil
+	case strings.HasPrefix(command, "brew "):
+		return strings.Fields(command)
+	}
+	return []string{"bash", "-c", command}
+}
+
+// describeFix is a fix command as the user would type it
+func describeFix(fix []string) string {
+	if len(fix) == 3 && fix[0] == "bash" && fix[1] == "-c" {
+		return fix[2]
+	}
+	return strings.Join(fix, " ")
+}
+
+// fixableChecks returns the indexes of the failed checks that have a fix
+func fixableChecks(checks []checkResult) []int {
+	var fixable []int
+	for i, check := range checks {
+		if !check.passed && len(check.fixCmd) > 0 {
+			fixable = append(fixable, i)
+		}
+	}
+	return fixable
+}
+
+// selectedFix is the check the welcome screen's f key would fix, if any
+func (m model) selectedFix() (checkResult, bool) {
+	fixable := fixableChecks(m.checks)
+	if len(fixable) == 0 {
+		return checkResult{}, false
+	}
+	return m.checks[fixable[min(m.fixCursor, len(fixable)-1)]], true
+}
+
+// recheck runs the pre-install checks again after a fix. OpenCode is looked
+// for again if it was missing; checks newModel adds on top of
+// runPreInstallChecks (resume, several OpenCode installs) are kept.
+func (m model) recheck() checksCompleteMsg {
+	var msg checksCompleteMsg
+	opencode := OpenCodeInfo{}
+	for _, install := range m.opencodeInstalls {
+		if install.BinaryPath == m.opencodeBin {
+			opencode = install
+		}
+	}
+	if !opencode.Installed {
+		if installs := detectOpenCodeInstalls(m.runner); len(installs) > 0 {
+			msg.installs = installs
+			opencode = installs[0]
+		}
+	}
+
+	msg.checks = runPreInstallChecks(m.checkOpts, m.configPath, m.projectDir, opencode, m.container)
+	rerun := make(map[string]bool, len(msg.checks))
+	for _, check := range msg.checks {
+		rerun[check.name] = true
+	}
+	for _, check := range m.checks {
+		if !rerun[check.name] && check.name != "OpenCode binary" {
+			msg.checks = append(msg.checks, check)
+		}
+	}
+	return msg
+}
+
+// fixCmd suspends the TUI to run check's fix in the terminal, then runs the
+// pre-install checks again so the welcome screen shows what it changed
+func (m model) fixCmd(check checkResult) tea.Cmd {
+	cmd := exec.Command(check.fixCmd[0], check.fixCmd[1:]...)
+	setProxyEnv(cmd, m.proxy)
+	return tea.ExecProcess(cmd, func(err error) tea.Msg {
+		recorder.command(cmd, err)
+		msg := m.recheck()
+		if err == nil {
+			return msg
+		}
+		for i := range msg.checks {
+			if msg.checks[i].name == check.name && !msg.checks[i].passed {
+				msg.checks[i].message = fmt.Sprintf("fix failed (%v) - %s", err, msg.checks[i].message)
+			}
+		}
+		return msg
+	})
+}
+
+// applyChecks takes the result of recheck (or loginCmd) into the model
+func (m *model) applyChecks(msg checksCompleteMsg) {
+	m.checks = msg.checks
+	m.checksComplete = true
+	if msg.installs != nil {
+		m.opencodeInstalls = msg.installs
+		m.useOpenCode(msg.installs[0])
+	}
+}
+
+// runAutoFixes is --auto-fix: it offers each failed check's fix in turn,
+// running those confirmed on stdin, then runs the checks again. Prompts and
+// fix output go to stderr so --json keeps stdout to itself. It reports
+// whether any fix ran.
+func (m *model) runAutoFixes(in io.Reader) bool {
+	reader := bufio.NewReader(in)
+	ran := false
+	for _, i := range fixableChecks(m.checks) {
+		check := m.checks[i]
+		fmt.Fprintf(os.Stderr, "Fix %s (%s) by running `%s`? [y/N] ", check.name, check.message, describeFix(check.fixCmd))
+		answer, _ := reader.ReadString('\n')
+		if !strings.EqualFold(strings.TrimSpace(answer), "y") {
+			fmt.Fprintln(os.Stderr, "  skipped")
+			continue
+		}
+		cmd := exec.CommandContext(m.ctx, check.fixCmd[0], check.fixCmd[1:]...)
+		cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stderr, os.Stderr
+		setProxyEnv(cmd, m.proxy)
+		if m.logFile != nil {
+			m.logFile.WriteString(fmt.Sprintf("--auto-fix: running %s\n", cmd.String()))
+		}
+		err := cmd.Run()
+		recorder.command(cmd, err)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "  fix failed: %v\n", err)
+		}
+		ran = true
+	}
+	if ran {
+		m.applyChecks(m.recheck())
+	}
+	return ran
+}
@@ -0,0 +1,327 @@
+// internal/installer/frontend.go
+package installer
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+)
+
+// protocolSession is one --protocol run: messages out, responses in
+type protocolSession struct {
+	enc   *json.Encoder
+	lines chan []byte // closed when stdin is
+}
+
+func newProtocolSession(out io.Writer, in io.Reader) *protocolSession {
+	enc := json.NewEncoder(out)
+	enc.SetEscapeHTML(false)
+	p := &protocolSession{enc: enc, lines: make(chan []byte)}
+
+	// Read in the background so an interrupt can end a prompt that is
+	// still waiting for an answer
+	go func() {
+		scanner := bufio.NewScanner(in)
+		scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+		for scanner.Scan() {
+			p.lines <- slices.Clone(scanner.Bytes())
+		}
+		close(p.lines)
+	}()
+	return p
+}
+
+func (p *protocolSession) send(msg interface{}) {
+	p.enc.Encode(msg)
+}
+
+func (p *protocolSession) sendStep(step string) {
+	p.send(protocolStep{Type: "step", Step: step})
+}
+
+func (p *protocolSession) sendError(format string, args ...interface{}) {
+	p.send(protocolError{Type: "error", Message: fmt.Sprintf(format, args...)})
+}
+
+// ask sends prompt and returns the first response to it that valid accepts.
+// Closed stdin or a cancelled ctx answers with a cancel.
+func (p *protocolSession) ask(ctx context.Context, prompt protocolPrompt, valid func(protocolResponse) error) protocolResponse {
+	prompt.Type = "prompt"
+	p.sendStep(prompt.ID)
+	p.send(prompt)
+	for {
+		select {
+		case <-ctx.Done():
+			return protocolResponse{Type: "response", ID: prompt.ID, Cancel: true}
+		case line, ok := <-p.lines:
+			if !ok {
+				return protocolResponse{Type: "response", ID: prompt.ID, Cancel: true}
+			}
+			var response protocolResponse
+			if err := json.Unmarshal(line, &response); err != nil || response.Type != "response" {
+				p.sendError("expected a response to %s, got %s", prompt.ID, summarizeRawOutput(string(line)))
+				continue
+			}
+			if response.ID != prompt.ID {
+				p.sendError("response is for %q, but %q is waiting", response.ID, prompt.ID)
+				continue
+			}
+			if response.Cancel {
+				return response
+			}
+			if valid != nil {
+				if err := valid(response); err != nil {
+					p.sendError("invalid response to %s: %v", prompt.ID, err)
+					continue
+				}
+			}
+			return response
+		}
+	}
+}
+
+// runProtocol drives an install (or uninstall) for a front-end, as described
+// in protocol.go. It returns the process exit code.
+func runProtocol(m model, uninstall bool) int {
+	p := newProtocolSession(os.Stdout, os.Stdin)
+	p.send(protocolHello{Type: "hello", Protocol: protocolVersion, Version: installerVersion})
+	defer cancelOnInterrupt(m)()
+
+	if uninstall {
+		if code := p.confirmUninstall(&m); code != 0 {
+			return code
+		}
+		m.step = stepUninstalling
+		m.isUninstall = true
+		m.tasks = m.uninstallTasks()
+	} else {
+		if m.step == stepSelectOpenCode {
+			if !p.selectOpenCode(&m) {
+				p.sendError("cancelled")
+				return 130
+			}
+			m.step = stepWelcome
+		}
+
+		checks := protocolChecks{Type: "checks", Checks: []protocolCheck{}}
+		var blocking []string
+		for _, check := range m.checks {
+			checks.Checks = append(checks.Checks, protocolCheck{Name: check.name, Passed: check.passed, Warning: check.warning, Message: check.message})
+			if !check.passed && !check.warning {
+				blocking = append(blocking, check.name+": "+check.message)
+			}
+		}
+		p.send(checks)
+		if len(blocking) > 0 {
+			p.sendError("pre-install checks failed: %s", strings.Join(blocking, "; "))
+			return 1
+		}
+		if m.needsLogin() && m.modelsFile == "" {
+			p.sendError("cursor-agent is not logged in - run `cursor-agent login`")
+			return 1
+		}
+
+		if !m.dryRun && configSyntaxProblem(m.configPath) != "" {
+			if code := p.repairConfig(&m); code != 0 {
+				return code
+			}
+		}
+
+		m.step = stepInstalling
+		m.tasks = buildInstallPlan(&m.installContext)
+	}
+	m.currentTaskIndex = 0
+
+	m = p.runTasks(m)
+
+	summary, criticalFailure := m.summary()
+	if !criticalFailure && !m.cancelled {
+		summary.NextSteps = m.nextSteps()
+	}
+	if m.cancelled {
+		p.sendError("%s", strings.Join(m.errors, "; "))
+		return m.cancelledExitCode()
+	}
+	p.send(summary)
+	if criticalFailure {
+		return 1
+	}
+	return 0
+}
+
+// runTasks is runTasks for the protocol: the same rollback behaviour, with
+// the prompts the TUI would show sent to the front-end instead
+func (p *protocolSession) runTasks(m model) model {
+	m.headless = false
+	m.startDeadline()
+
+	phase := "installing"
+	if m.isUninstall {
+		phase = "uninstalling"
+	}
+	p.sendStep(phase)
+
+	for m.step != stepComplete {
+		index := m.currentTaskIndex
+		m.tasks[index].status = statusRunning
+		task := m.tasks[index]
+		p.send(protocolTaskStart{Type: "task_start", Index: index, Total: len(m.tasks), Name: task.name, Description: task.description, Optional: task.optional})
+
+		msg := executeTaskCmd(index, &m)().(taskCompleteMsg)
+		next, _ := m.handleTaskComplete(msg)
+		m = next.(model)
+		p.send(m.tasks[index].report())
+
+		var answered bool
+		switch m.step {
+		case stepSelectModels:
+			m, answered = p.selectModels(m)
+		case stepConfirmConfig:
+			m, answered = p.confirmConfig(m)
+		default:
+			continue
+		}
+		if !answered {
+			next, _ := m.finishCancelled()
+			m = next.(model)
+			break
+		}
+		p.sendStep(phase)
+	}
+
+	p.sendStep("complete")
+	return m
+}
+
+// selectOpenCode asks which of several opencode binaries to use
+func (p *protocolSession) selectOpenCode(m *model) bool {
+	prompt := protocolPrompt{ID: promptSelectOpenCode, Message: "More than one OpenCode is installed. Which one should the plugin be installed for?"}
+	for i, install := range m.opencodeInstalls {
+		prompt.Choices = append(prompt.Choices, protocolChoice{ID: install.BinaryPath, Label: install.describe(), Selected: i == 0})
+	}
+	response := p.ask(m.ctx, prompt, func(r protocolResponse) error {
+		if len(r.Choices) != 1 {
+			return fmt.Errorf("choose exactly one")
+		}
+		for _, install := range m.opencodeInstalls {
+			if install.BinaryPath == r.Choices[0] {
+				return nil
+			}
+		}
+		return fmt.Errorf("unknown opencode %q", r.Choices[0])
+	})
+	if response.Cancel {
+		return false
+	}
+	for _, install := range m.opencodeInstalls {
+		if install.BinaryPath == response.Choices[0] {
+			m.useOpenCode(install)
+		}
+	}
+	return true
+}
+
+// repairConfig offers to back up and reset an opencode.json that does not
+// parse, returning a non-zero exit code if the run should stop
+func (p *protocolSession) repairConfig(m *model) int {
+	prompt := protocolPrompt{
+		ID: promptRepairConfig,
+		Message: fmt.Sprintf("%s is not valid JSON (%s). Back it up to %s and start from a minimal config?",
+			m.configPath, configSyntaxProblem(m.configPath), m.backupDir),
+	}
+	response := p.ask(m.ctx, prompt, nil)
+	if response.Cancel {
+		p.sendError("cancelled")
+		return 130
+	}
+	if !response.Accept {
+		p.sendError("%s is not valid JSON; fix it and re-run", m.configPath)
+		return 1
+	}
+	backupPath, err := resetCorruptConfig(&m.installContext)
+	if err != nil {
+		p.sendError("could not reset %s: %v", m.configPath, err)
+		return 1
+	}
+	m.configReset(backupPath)
+	return 0
+}
+
+// confirmUninstall lists what uninstalling removes and asks to go ahead
+func (p *protocolSession) confirmUninstall(m *model) int {
+	prompt := protocolPrompt{
+		ID:      promptConfirmUninstall,
+		Message: fmt.Sprintf("Remove cursor-acp? Changed files are backed up to %s first.", m.backupDir),
+		Remove:  planUninstall(&m.installContext),
+	}
+	response := p.ask(m.ctx, prompt, nil)
+	if response.Cancel {
+		p.sendError("cancelled")
+		return 130
+	}
+	if !response.Accept {
+		p.sendError("uninstall not confirmed; nothing was removed")
+		return 1
+	}
+	return 0
+}
+
+// selectModels asks which of the fetched models to configure
+func (p *protocolSession) selectModels(m model) (model, bool) {
+	keepsConfigured := m.keepsConfiguredModels(m.currentConfig())
+	prompt := protocolPrompt{ID: promptSelectModels, Message: "Choose the models to add to OpenCode.", Multiple: true}
+	for _, id := range m.modelChoices {
+		label := id
+		if entry, ok := m.state.models[id].(map[string]interface{}); ok {
+			if name, _ := entry["name"].(string); name != "" {
+				label = name
+			}
+		}
+		prompt.Choices = append(prompt.Choices, protocolChoice{ID: id, Label: label, Selected: m.modelSelected[id]})
+	}
+	if len(configuredModels(m.currentConfig())) > 0 {
+		prompt.Message += " " + m.selectionNote()
+	}
+	response := p.ask(m.ctx, prompt, func(r protocolResponse) error {
+		for _, id := range r.Choices {
+			if !slices.Contains(m.modelChoices, id) {
+				return fmt.Errorf("unknown model %q", id)
+			}
+		}
+		if len(r.Choices) == 0 && !keepsConfigured {
+			return fmt.Errorf("choose at least one model")
+		}
+		return nil
+	})
+	if response.Cancel {
+		return m, false
+	}
+
+	selected := make(map[string]bool, len(response.Choices))
+	for _, id := range response.Choices {
+		selected[id] = true
+	}
+	m.state.selectedModels = selected
+	next, _ := m.resumeInstall()
+	return next.(model), true
+}
+
+// confirmConfig asks the front-end to approve the opencode.json changes
+func (p *protocolSession) confirmConfig(m model) (model, bool) {
+	prompt := protocolPrompt{
+		ID:      promptConfirmConfig,
+		Message: fmt.Sprintf("The installer will make these changes to %s.", m.configPath),
+		Diff:    m.state.configDiff,
+	}
+	response := p.ask(m.ctx, prompt, nil)
+	if response.Cancel || !response.Accept {
+		return m, false
+	}
+	next, _ := m.resumeInstall()
+	return next.(model), true
+}
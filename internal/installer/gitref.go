@@ -0,0 +1,105 @@
+// internal/installer/gitref.go
+package installer
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// gitOutput runs git in dir and returns its trimmed stdout
+func gitOutput(ctx context.Context, runner CommandRunner, dir string, args ...string) (string, error) {
+	output, err := runner.Output(exec.CommandContext(ctx, "git", append([]string{"-C", dir}, args...)...))
+	if err != nil {
+		if exitErr, ok := err.(*exec.ExitError); ok && len(exitErr.Stderr) > 0 {
+			return "", fmt.Errorf("git %s: %s", args[0], summarizeRawOutput(string(exitErr.Stderr)))
+		}
+		return "", fmt.Errorf("git %s: %w", args[0], err)
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// resolveGitRef checks dir is a git checkout with ref in it and returns the
+// commit ref names
+func resolveGitRef(ctx context.Context, runner CommandRunner, dir, ref string) (string, error) {
+	if !commandExists("git") {
+		return "", fmt.Errorf("--ref needs git, which was not found")
+	}
+	if inside, err := gitOutput(ctx, runner, dir, "rev-parse", "--is-inside-work-tree"); err != nil || inside != "true" {
+		return "", fmt.Errorf("--ref: %s is not a git checkout", dir)
+	}
+	commit, err := gitOutput(ctx, runner, dir, "rev-parse", "--verify", "--quiet", ref+"^{commit}")
+	if err != nil || commit == "" {
+		return "", fmt.Errorf("--ref: %q is not a commit, branch or tag in %s (fetch it first?)", ref, dir)
+	}
+	return commit, nil
+}
+
+// checkGitRef is the pre-install check for --ref
+func checkGitRef(runner CommandRunner, dir, ref string) checkResult {
+	commit, err := resolveGitRef(context.Background(), runner, dir, ref)
+	if err != nil {
+		return checkResult{name: "git ref", passed: false, message: err.Error()}
+	}
+	return checkResult{name: "git ref", passed: true, message: fmt.Sprintf("%s (%s)", ref, commit[:min(len(commit), 12)])}
+}
+
+// checkoutRef checks out ic.ref in the project for the build, stashing any
+// local changes first. The returned func puts back the branch (or commit)
+// that was checked out and the stashed changes; it must be called once the
+// build is done, whether or not it succeeded.
+func checkoutRef(ic *installContext) (func(), error) {
+	dir := ic.projectDir
+	commit, err := resolveGitRef(ic.ctx, ic.runner, dir, ic.ref)
+	if err != nil {
+		return nil, err
+	}
+
+	// Come back to the branch if there is one, else to the detached commit
+	original, err := gitOutput(ic.ctx, ic.runner, dir, "symbolic-ref", "--quiet", "--short", "HEAD")
+	if err != nil || original == "" {
+		if original, err = gitOutput(ic.ctx, ic.runner, dir, "rev-parse", "HEAD"); err != nil {
+			return nil, err
+		}
+	}
+
+	git := func(name string, args ...string) error {
+		return runCommand(ic.runner, name, exec.CommandContext(ic.ctx, "git", append([]string{"-C", dir}, args...)...), ic.logFile)
+	}
+
+	status, err := gitOutput(ic.ctx, ic.runner, dir, "status", "--porcelain")
+	if err != nil {
+		return nil, err
+	}
+	stashed := false
+	if status != "" {
+		if err := git("git stash", "stash", "push", "--include-untracked", "--message", "opencode-cursor installer --ref "+ic.ref); err != nil {
+			return nil, err
+		}
+		stashed = true
+	}
+
+	restore := func() {
+		// The build's own context may have been cancelled; putting the
+		// checkout back must still happen
+		run := func(name string, args ...string) error {
+			return runCommand(ic.runner, name, exec.Command("git", append([]string{"-C", dir}, args...)...), ic.logFile)
+		}
+		if err := run("git checkout", "checkout", "--quiet", original); err != nil {
+			ic.state.warn("git ref", "could not check %s out again in %s: %v", original, dir, err)
+			return
+		}
+		if stashed {
+			if err := run("git stash pop", "stash", "pop", "--quiet"); err != nil {
+				ic.state.warn("git ref", "could not restore your stashed changes in %s - they are still in `git stash list`: %v", dir, err)
+			}
+		}
+	}
+
+	if err := git("git checkout", "checkout", "--quiet", "--detach", commit); err != nil {
+		restore()
+		return nil, err
+	}
+	return restore, nil
+}
@@ -0,0 +1,397 @@
+// internal/installer/headless.go
+package installer
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// runHeadless drives the install without Bubble Tea, printing plain-text
+// progress (or JSON lines with --json). It returns the process exit code.
+func runHeadless(m model) int {
+	out := m.progressOutput()
+	printChecks := func(heading string) {
+		if m.jsonOutput {
+			return
+		}
+		fmt.Fprintln(out, heading)
+		for _, check := range m.checks {
+			fmt.Fprintf(out, "  %s %s: %s\n", checkLabel(check), check.name, check.message)
+		}
+	}
+	printChecks("Pre-install checks:")
+	if m.autoFix && m.runAutoFixes(os.Stdin) {
+		printChecks("Pre-install checks after fixes:")
+	}
+
+	blocked := false
+	for _, check := range m.checks {
+		if !check.passed && !check.warning {
+			blocked = true
+			if m.jsonOutput || m.quiet {
+				fmt.Fprintf(os.Stderr, "Error: %s: %s\n", check.name, check.message)
+			}
+		}
+	}
+	if blocked {
+		fmt.Fprintln(os.Stderr, "Error: blocking pre-install checks failed; fix the issues above and re-run")
+		return 1
+	}
+	// Nobody can answer a login prompt here, and models come from cursor-agent
+	// unless --models-from-file supplies them
+	if m.needsLogin() && m.modelsFile == "" {
+		fmt.Fprintln(os.Stderr, "Error: cursor-agent is not logged in - run `cursor-agent login`, then re-run")
+		return 1
+	}
+
+	if !m.jsonOutput {
+		fmt.Fprintln(out)
+	}
+	m.step = stepInstalling
+	m.tasks = buildInstallPlan(&m.installContext)
+	m.currentTaskIndex = 0
+	return runTasksHeadless(m)
+}
+
+// checksReport is the result of --check-only --json
+type checksReport struct {
+	Type   string          `json:"type"`  // "checks"
+	Ready  bool            `json:"ready"` // nothing would stop a headless install
+	Checks []protocolCheck `json:"checks"`
+	Errors []string        `json:"errors,omitempty"` // why it is not ready
+}
+
+// runCheckOnly is --check-only: it runs the pre-install checks, reports them
+// and exits non-zero if a headless install would refuse to start. Nothing
+// is changed.
+func runCheckOnly(m model) int {
+	report := checksReport{Type: "checks", Checks: []protocolCheck{}}
+	for _, check := range m.checks {
+		report.Checks = append(report.Checks, protocolCheck{Name: check.name, Passed: check.passed, Warning: check.warning, Message: check.message})
+		if !check.passed && !check.warning {
+			report.Errors = append(report.Errors, check.name+": "+check.message)
+		}
+	}
+	// runHeadless refuses to start on these too
+	if m.needsLogin() && m.modelsFile == "" {
+		report.Errors = append(report.Errors, "cursor-agent is not logged in - run `cursor-agent login`")
+	}
+	report.Ready = len(report.Errors) == 0
+
+	if m.jsonOutput {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetEscapeHTML(false)
+		enc.Encode(report)
+	} else {
+		out := m.progressOutput()
+		fmt.Fprintln(out, "Pre-install checks:")
+		for _, check := range m.checks {
+			fmt.Fprintf(out, "  %s %s: %s\n", checkLabel(check), check.name, check.message)
+		}
+		fmt.Fprintln(out)
+		if report.Ready {
+			fmt.Println("Ready to install.")
+		} else {
+			for _, problem := range report.Errors {
+				fmt.Fprintf(os.Stderr, "Error: %s\n", problem)
+			}
+			fmt.Fprintln(os.Stderr, "Not ready to install; fix the issues above and re-run")
+		}
+	}
+	if !report.Ready {
+		return 1
+	}
+	return 0
+}
+
+// runHeadlessUninstall removes the install without the TUI menu (--uninstall
+// with --headless). Pre-install checks do not apply and are skipped.
+func runHeadlessUninstall(m model) int {
+	m.step = stepUninstalling
+	m.isUninstall = true
+	m.tasks = m.uninstallTasks()
+	m.currentTaskIndex = 0
+	// --headless is also --yes: the listing is the record of what it removed
+	if items := planUninstall(&m.installContext); len(items) > 0 && !m.jsonOutput {
+		out := m.progressOutput()
+		if m.dryRun {
+			fmt.Fprintln(out, "Would remove:")
+		} else {
+			fmt.Fprintln(out, "Removing:")
+		}
+		for _, item := range items {
+			fmt.Fprintf(out, "  - %s\n", item)
+		}
+		fmt.Fprintln(out)
+	}
+	return runTasksHeadless(m)
+}
+
+// runTasksHeadless runs m.tasks, printing progress and a summary.
+func runTasksHeadless(m model) int {
+	defer cancelOnInterrupt(m)()
+
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetEscapeHTML(false)
+	out := m.progressOutput()
+
+	started := func(task installTask) {
+		if !m.jsonOutput {
+			fmt.Fprintf(out, "==> %s\n", task.description)
+		}
+	}
+	finished := func(task installTask) {
+		if m.jsonOutput {
+			enc.Encode(task.report())
+			return
+		}
+		fmt.Fprintf(out, "  %s %s (%s)\n", statusLabel(task.status), task.name, formatDuration(task.duration))
+		if task.note != "" {
+			fmt.Fprintf(out, "      %s\n", strings.ReplaceAll(task.note, "\n", "\n      "))
+		}
+		if task.status == statusFailed && task.errorDetails != nil {
+			// With --quiet the log gets a copy; it has the rest of the story
+			errOut := io.Writer(os.Stderr)
+			if m.quiet {
+				errOut = io.MultiWriter(os.Stderr, out)
+			}
+			fmt.Fprintf(errOut, "Error: %s: %s\n", task.name, task.errorDetails.message)
+			if task.errorDetails.remediation != "" {
+				fmt.Fprintf(errOut, "Fix: %s\n", task.errorDetails.remediation)
+			}
+		}
+	}
+	m = runTasks(m, started, finished)
+
+	summary, criticalFailure := m.summary()
+	if !criticalFailure && !m.cancelled {
+		summary.NextSteps = m.nextSteps()
+	}
+
+	if m.jsonOutput {
+		enc.Encode(summary)
+	} else if criticalFailure {
+		if summary.LogFile != "" && !m.quiet {
+			fmt.Fprintf(os.Stderr, "See logs: %s\n", summary.LogFile)
+		}
+	} else {
+		fmt.Fprintln(out)
+		if len(m.state.removed) > 0 {
+			fmt.Fprintln(out, "Removed:")
+			for _, item := range m.state.removed {
+				fmt.Fprintf(out, "  - %s\n", item)
+			}
+		}
+		if summary.BackupDir != "" {
+			fmt.Fprintf(out, "Backups: %s\n", summary.BackupDir)
+		}
+		for _, warning := range summary.Warnings {
+			fmt.Fprintf(out, "Warning [%s]: %s\n", warning.Source, warning.Message)
+		}
+		switch {
+		case len(summary.Warnings) > 0:
+			fmt.Fprintln(out, "Done, with warnings.")
+		case summary.NoChanges:
+			fmt.Fprintln(out, "Done, no changes needed.")
+		default:
+			fmt.Fprintln(out, "Done.")
+		}
+		if len(summary.NextSteps) > 0 {
+			fmt.Fprintln(out, "\nNext steps:")
+			for i, step := range summary.NextSteps {
+				fmt.Fprintf(out, "  %d. %s - %s\n", i+1, step.Command, step.Description)
+			}
+		}
+	}
+	if m.quiet {
+		line := quietSummary(summary, m.isUninstall, criticalFailure)
+		if criticalFailure {
+			fmt.Fprintln(os.Stderr, line)
+		} else {
+			fmt.Println(line)
+		}
+	}
+
+	if m.cancelled {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", strings.Join(m.errors, "; "))
+		return m.cancelledExitCode()
+	}
+	if criticalFailure {
+		return 1
+	}
+	return 0
+}
+
+// cancelOnInterrupt cancels m's context on Ctrl-C or SIGTERM, which kills the
+// running command; handleTaskComplete then rolls back. Call the returned func
+// to stop listening.
+func cancelOnInterrupt(m model) func() {
+	interrupts := make(chan os.Signal, 1)
+	signal.Notify(interrupts, os.Interrupt, syscall.SIGTERM)
+	cancel := m.cancel
+	logFile := m.logFile
+	go func() {
+		if _, ok := <-interrupts; !ok {
+			return
+		}
+		cancel()
+		if logFile != nil {
+			logFile.Sync()
+		}
+	}()
+	return func() {
+		signal.Stop(interrupts)
+		close(interrupts)
+	}
+}
+
+// progressOutput is where headless progress goes: stdout, or with --quiet the
+// log file alone, so nothing is lost from the diagnostics.
+func (m model) progressOutput() io.Writer {
+	if !m.quiet {
+		return os.Stdout
+	}
+	if m.logFile != nil {
+		return m.logFile
+	}
+	return io.Discard
+}
+
+// quietSummary is the single line --quiet prints once the tasks have run
+func quietSummary(summary summaryReport, uninstall, failed bool) string {
+	action := "install"
+	if uninstall {
+		action = "uninstall"
+	}
+	outcome := "complete"
+	if failed {
+		outcome = "failed"
+	}
+	line := fmt.Sprintf("%s %s: %d completed, %d skipped, %d failed", action, outcome, summary.Completed, summary.Skipped, summary.Failed)
+	if len(summary.Warnings) > 0 {
+		line += fmt.Sprintf(", %d warnings", len(summary.Warnings))
+	}
+	if summary.LogFile != "" {
+		line += " (log: " + summary.LogFile + ")"
+	}
+	return line
+}
+
+// runTasks executes m.tasks in order through handleTaskComplete so rollback
+// behaves exactly as it does in the TUI. started and finished, if set, are
+// called around each task.
+func runTasks(m model, started, finished func(installTask)) model {
+	// Interactive steps (e.g. model selection) are skipped without a TUI
+	m.headless = true
+	m.startDeadline()
+
+	for m.step != stepComplete {
+		index := m.currentTaskIndex
+		m.tasks[index].status = statusRunning
+		if started != nil {
+			started(m.tasks[index])
+		}
+
+		msg := executeTaskCmd(index, &m)().(taskCompleteMsg)
+		next, _ := m.handleTaskComplete(msg)
+		m = next.(model)
+
+		if finished != nil {
+			finished(m.tasks[index])
+		}
+	}
+	return m
+}
+
+// summary totals the task outcomes; failed is true if a required task failed
+func (m model) summary() (report summaryReport, failed bool) {
+	report = summaryReport{Type: "summary", Warnings: m.allWarnings(), Removed: m.state.removed, BackupDir: m.writtenBackupDir(), NoChanges: m.changedNothing(),
+		LogFile: logFileName(m.logFile), DurationMs: time.Since(m.startedAt).Milliseconds()}
+	for _, task := range m.tasks {
+		switch task.status {
+		case statusComplete:
+			report.Completed++
+		case statusFailed:
+			report.Failed++
+			if !task.optional {
+				failed = true
+			}
+		case statusSkipped:
+			report.Skipped++
+		}
+	}
+	return report, failed
+}
+
+// changedNothing reports whether an install modified nothing: every task
+// that writes found its work already done (see alreadyDone)
+func (m model) changedNothing() bool {
+	if m.isUninstall || m.dryRun {
+		return false
+	}
+	writes := false
+	for _, task := range m.tasks {
+		if !task.mutates {
+			continue
+		}
+		if task.status != statusSkipped {
+			return false
+		}
+		writes = true
+	}
+	return writes
+}
+
+// writtenBackupDir is the backup directory if this run copied anything to it
+func (m model) writtenBackupDir() string {
+	if len(m.diskBackups) == 0 {
+		return ""
+	}
+	return m.backupDir
+}
+
+// allWarnings is every warning of the run: those of the pre-install checks
+// that let an install go ahead, then those the tasks raised
+func (m model) allWarnings() []installWarning {
+	var warnings []installWarning
+	if !m.isUninstall {
+		for _, check := range m.checks {
+			if check.warning && !check.passed {
+				warnings = append(warnings, installWarning{Source: check.name, Message: check.message})
+			}
+		}
+	}
+	return append(warnings, m.warnings...)
+}
+
+func checkLabel(check checkResult) string {
+	switch {
+	case check.passed:
+		return "[OK]"
+	case check.warning:
+		return "[WARN]"
+	default:
+		return "[FAIL]"
+	}
+}
+
+func statusLabel(status taskStatus) string {
+	switch status {
+	case statusComplete:
+		return "[OK]"
+	case statusFailed:
+		return "[FAIL]"
+	case statusSkipped:
+		return "[SKIP]"
+	case statusRunning:
+		return "[..]"
+	default:
+		return "[  ]"
+	}
+}
@@ -0,0 +1,383 @@
+// internal/installer/integration_test.go
+package installer
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// fakeRunner stands in for cursor-agent, bun, npm and opencode, answering
+// from cmd.Args and doing the one side effect the install relies on for each
+// (bun and npm writing dist/ and node_modules). It records every command it
+// ran.
+type fakeRunner struct {
+	t  *testing.T
+	mu sync.Mutex
+	// ran holds each command as "name args...", name without its directory
+	ran []string
+
+	npmRoot   string // what `npm root -g` prints
+	published string // the version npm has for npmPackage@latest
+}
+
+func (r *fakeRunner) Run(cmd *exec.Cmd) ([]byte, error)            { return r.answer(cmd) }
+func (r *fakeRunner) Output(cmd *exec.Cmd) ([]byte, error)         { return r.answer(cmd) }
+func (r *fakeRunner) CombinedOutput(cmd *exec.Cmd) ([]byte, error) { return r.answer(cmd) }
+
+func (r *fakeRunner) answer(cmd *exec.Cmd) ([]byte, error) {
+	line := strings.Join(append([]string{filepath.Base(cmd.Args[0])}, cmd.Args[1:]...), " ")
+	r.mu.Lock()
+	r.ran = append(r.ran, line)
+	r.mu.Unlock()
+
+	switch {
+	case line == "bun --version":
+		return []byte("1.2.3\n"), nil
+	case line == "cursor-agent --version":
+		return []byte("2025.11.25-abc\n"), nil
+	case line == "cursor-agent whoami":
+		return []byte("Logged in as test@example.com\n"), nil
+	case line == "cursor-agent models --json":
+		return []byte(`[{"id":"auto","name":"Auto"},{"id":"gpt-5","name":"GPT-5"}]`), nil
+	case line == "opencode --version":
+		return []byte("1.1.53\n"), nil
+	case line == "opencode models":
+		return []byte("cursor-acp/auto\ncursor-acp/gpt-5\n"), nil
+	case line == "bun install":
+		return nil, os.MkdirAll(filepath.Join(cmd.Dir, "node_modules"), 0755)
+	case line == "bun run build":
+		if err := os.MkdirAll(filepath.Join(cmd.Dir, "dist"), 0755); err != nil {
+			return nil, err
+		}
+		return nil, os.WriteFile(filepath.Join(cmd.Dir, "dist", "plugin-entry.js"), []byte("export default async function CursorPluginEntry() { return {}; }\n"), 0644)
+	case strings.HasPrefix(line, "bun add "):
+		for _, spec := range cmd.Args[2:] {
+			// The version a range starts at, else one any test range takes
+			name, version := spec, "1.0.9"
+			if i := strings.LastIndex(spec, "@"); i > 0 {
+				name = spec[:i]
+				if start := strings.TrimLeft(spec[i+1:], "^~="); strings.Count(start, ".") == 2 {
+					version = start
+				}
+			}
+			dir := filepath.Join(cmd.Dir, "node_modules", filepath.FromSlash(name))
+			if err := os.MkdirAll(dir, 0755); err != nil {
+				return nil, err
+			}
+			if err := os.WriteFile(filepath.Join(dir, "package.json"), []byte(`{"version":"`+version+`"}`), 0644); err != nil {
+				return nil, err
+			}
+		}
+		return nil, nil
+	case line == "npm root -g":
+		return []byte(r.npmRoot + "\n"), nil
+	case line == "npm view "+npmPackage+"@latest version":
+		return []byte(r.published + "\n"), nil
+	case line == "npm install -g "+npmPackage+"@latest":
+		dir := filepath.Join(r.npmRoot, filepath.FromSlash(npmPackage))
+		if err := os.MkdirAll(filepath.Join(dir, "dist"), 0755); err != nil {
+			return nil, err
+		}
+		if err := os.WriteFile(filepath.Join(dir, "package.json"), []byte(`{"version":"`+r.published+`"}`), 0644); err != nil {
+			return nil, err
+		}
+		return nil, os.WriteFile(filepath.Join(dir, "dist", "plugin-entry.js"), []byte("export default async function CursorPluginEntry() { return {}; }\n"), 0644)
+	case line == "bun -e "+pluginExportCheck:
+		return nil, nil
+	case line == "opencode debug paths", line == "node --version", strings.HasPrefix(line, "pacman "):
+		// Not installed, or too old to say; the install falls back
+		return nil, exec.ErrNotFound
+	}
+	r.t.Errorf("unexpected command: %s", line)
+	return nil, fmt.Errorf("%s: not faked", line)
+}
+
+func (r *fakeRunner) hasRun(line string) bool {
+	return r.count(line) > 0
+}
+
+// count is how many times line was run, and forget starts counting afresh
+func (r *fakeRunner) count(line string) int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	n := 0
+	for _, ran := range r.ran {
+		if ran == line {
+			n++
+		}
+	}
+	return n
+}
+
+func (r *fakeRunner) forget() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.ran = nil
+}
+
+// fakeEnvironment points HOME at a temp dir and PATH at stubs of bun,
+// cursor-agent, opencode and any extra tools, which the install looks for.
+// The stubs only note that they ran: every command must go through the
+// runner, so the note failing the test means one bypassed it.
+func fakeEnvironment(t *testing.T, extra ...string) (home, projectDir string) {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("the PATH stubs are shell scripts")
+	}
+
+	home = t.TempDir()
+	bin := t.TempDir()
+	projectDir = t.TempDir()
+	bypassed := filepath.Join(bin, "bypassed")
+	for _, name := range append([]string{"bun", "cursor-agent", "opencode"}, extra...) {
+		stub := fmt.Sprintf("#!/bin/sh\necho \"%s $*\" >> %q\nexit 1\n", name, bypassed)
+		if err := os.WriteFile(filepath.Join(bin, name), []byte(stub), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	t.Cleanup(func() {
+		if ran, err := os.ReadFile(bypassed); err == nil {
+			t.Errorf("commands run outside the runner:\n%s", ran)
+		}
+	})
+	if err := os.WriteFile(filepath.Join(projectDir, "package.json"), []byte(`{"name":"opencode-cursor","type":"module"}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Setenv("HOME", home)
+	t.Setenv("PATH", bin)
+	t.Setenv("XDG_CONFIG_HOME", "")
+	t.Setenv("SUDO_USER", "")
+	t.Setenv("OPENCODE_CURSOR_CONTAINER", "0")
+	t.Setenv("OPENCODE_CURSOR_PROJECT_DIR", "")
+	t.Setenv("CURSOR_ACP_NPM_TAG", "")
+	return home, projectDir
+}
+
+// drive plays the part of the Bubble Tea program: it runs each command,
+// hands the task results to Update and presses enter at the model picker
+// and the config preview, until nothing is left to run. Animation and
+// spinner ticks are dropped so the loop ends.
+func drive(m model, cmd tea.Cmd) model {
+	queue := []tea.Cmd{cmd}
+	for len(queue) > 0 {
+		next := queue[0]
+		queue = queue[1:]
+		if next == nil {
+			continue
+		}
+		switch msg := next().(type) {
+		case tea.BatchMsg:
+			queue = append(queue, msg...)
+		case taskCompleteMsg:
+			updated, cmd := m.Update(msg)
+			m = updated.(model)
+			queue = append(queue, cmd)
+			for m.step == stepSelectModels || m.step == stepConfirmConfig {
+				updated, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+				m = updated.(model)
+				queue = append(queue, cmd)
+			}
+		}
+	}
+	return m
+}
+
+func TestStartInstallation(t *testing.T) {
+	home, projectDir := fakeEnvironment(t)
+
+	opts, err := parseArgs([]string{"--project-dir", projectDir, "--no-log", "--no-animation"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	runner := &fakeRunner{t: t}
+	opts.runner = runner
+	m := newModel(context.Background(), opts, nil)
+	defer m.cancel()
+
+	started, cmd := m.startInstallation()
+	m = drive(started.(model), cmd)
+
+	if m.step != stepComplete {
+		t.Fatalf("install stopped at step %v", m.step)
+	}
+	if len(m.errors) > 0 {
+		t.Fatalf("install failed: %s", strings.Join(m.errors, "; "))
+	}
+	for _, task := range m.tasks {
+		if task.status != statusComplete && task.status != statusSkipped {
+			t.Errorf("%s: %s", task.name, task.status)
+		}
+	}
+	for _, line := range []string{"bun install", "bun run build", "bun add @ai-sdk/openai-compatible", "bun add @agentclientprotocol/sdk@" + defaultAcpSdkVersion, "opencode models"} {
+		if !runner.hasRun(line) {
+			t.Errorf("%q was not run", line)
+		}
+	}
+
+	configDir := filepath.Join(home, ".config", "opencode")
+	data, err := os.ReadFile(filepath.Join(configDir, "opencode.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var config struct {
+		Provider map[string]struct {
+			Models map[string]interface{} `json:"models"`
+		} `json:"provider"`
+	}
+	if err := json.Unmarshal(data, &config); err != nil {
+		t.Fatalf("opencode.json: %v", err)
+	}
+	models := config.Provider["cursor-acp"].Models
+	for _, id := range []string{"auto", "gpt-5"} {
+		if _, ok := models[id]; !ok {
+			t.Errorf("opencode.json has no cursor-acp model %q:\n%s", id, data)
+		}
+	}
+
+	link := filepath.Join(m.pluginDir, "cursor-acp.js")
+	target, err := os.Readlink(link)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := filepath.Join(projectDir, "dist", "plugin-entry.js"); target != want {
+		t.Errorf("%s -> %s, want %s", link, target, want)
+	}
+	if manifest, err := readManifest(m.configPath); err != nil || manifest == nil {
+		t.Errorf("no manifest written: %v", err)
+	}
+}
+
+// configIn returns the opencode.json under home
+func configIn(t *testing.T, home string) []byte {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join(home, ".config", "opencode", "opencode.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+// A second install finds everything in place: nothing is rebuilt or
+// reinstalled, and the AI SDK installed by the first is in the range asked for
+func TestInstallTwice(t *testing.T) {
+	home, projectDir := fakeEnvironment(t)
+	runner := &fakeRunner{t: t}
+	opts := Options{ProjectDir: projectDir, AiSdkVersion: "^1.0", Runner: runner}
+
+	first, err := Install(context.Background(), opts)
+	if err != nil {
+		t.Fatalf("first install: %v", err)
+	}
+	if first.NoChanges {
+		t.Fatal("first install reported no changes")
+	}
+	config := configIn(t, home)
+
+	runner.forget()
+	second, err := Install(context.Background(), opts)
+	if err != nil {
+		t.Fatalf("second install: %v", err)
+	}
+	if !second.NoChanges {
+		for _, task := range second.Tasks {
+			t.Logf("%s: %s %s", task.Name, task.Status, task.Note)
+		}
+		t.Error("second install changed something")
+	}
+	for _, line := range []string{"bun install", "bun run build", "bun add @ai-sdk/openai-compatible@^1.0", "bun add @agentclientprotocol/sdk@" + defaultAcpSdkVersion} {
+		if runner.hasRun(line) {
+			t.Errorf("second install ran %q", line)
+		}
+	}
+	if again := configIn(t, home); string(again) != string(config) {
+		t.Errorf("second install rewrote opencode.json:\n%s\nwas:\n%s", again, config)
+	}
+}
+
+// The npm package is kept while it is the latest release and reinstalled
+// once a newer one is published
+func TestInstallUpgradesNpmPlugin(t *testing.T) {
+	_, projectDir := fakeEnvironment(t, "npm")
+	runner := &fakeRunner{t: t, npmRoot: t.TempDir(), published: "2.3.0"}
+	opts := Options{ProjectDir: projectDir, Runner: runner}
+	install := "npm install -g " + npmPackage + "@latest"
+
+	if _, err := Install(context.Background(), opts); err != nil {
+		t.Fatalf("first install: %v", err)
+	}
+	if runner.count(install) != 1 {
+		t.Fatalf("first install ran %q %d times", install, runner.count(install))
+	}
+
+	runner.forget()
+	if _, err := Install(context.Background(), opts); err != nil {
+		t.Fatalf("second install: %v", err)
+	}
+	if runner.hasRun(install) {
+		t.Errorf("%s is current but was reinstalled", npmPackage)
+	}
+
+	runner.forget()
+	runner.published = "2.4.0"
+	if _, err := Install(context.Background(), opts); err != nil {
+		t.Fatalf("install after a release: %v", err)
+	}
+	if !runner.hasRun(install) {
+		t.Errorf("%s 2.4.0 was published but 2.3.0 was kept", npmPackage)
+	}
+	entry := filepath.Join(runner.npmRoot, filepath.FromSlash(npmPackage), "dist", "plugin-entry.js")
+	if version := pluginVersion(entry); version != "2.4.0" {
+		t.Errorf("installed %s, want 2.4.0", version)
+	}
+}
+
+// AcpSdkVersion reaches the package manager, and the version installed is
+// recorded for doctor and status
+func TestInstallAcpSdkVersion(t *testing.T) {
+	home, projectDir := fakeEnvironment(t)
+	runner := &fakeRunner{t: t}
+
+	if _, err := Install(context.Background(), Options{ProjectDir: projectDir, AcpSdkVersion: "0.14.2", Runner: runner}); err != nil {
+		t.Fatal(err)
+	}
+	if !runner.hasRun("bun add @agentclientprotocol/sdk@0.14.2") {
+		t.Error("the ACP SDK was not added at 0.14.2")
+	}
+	manifest, err := readManifest(filepath.Join(home, ".config", "opencode", "opencode.json"))
+	if err != nil || manifest == nil {
+		t.Fatalf("no manifest: %v", err)
+	}
+	if version := manifest.PackageVersions["@agentclientprotocol/sdk"]; version != "0.14.2" {
+		t.Errorf("manifest records @agentclientprotocol/sdk %q, want 0.14.2", version)
+	}
+}
+
+// Uninstall runs none of the pre-install checks, and so none of their commands
+func TestUninstallSkipsChecks(t *testing.T) {
+	_, projectDir := fakeEnvironment(t)
+	runner := &fakeRunner{t: t}
+
+	if _, err := Install(context.Background(), Options{ProjectDir: projectDir, Runner: runner}); err != nil {
+		t.Fatal(err)
+	}
+	runner.forget()
+	if _, err := Uninstall(context.Background(), Options{ProjectDir: projectDir, Runner: runner}); err != nil {
+		t.Fatal(err)
+	}
+	for _, line := range []string{"cursor-agent whoami", "cursor-agent --version", "bun --version"} {
+		if runner.hasRun(line) {
+			t.Errorf("Uninstall ran the pre-install check %q", line)
+		}
+	}
+}
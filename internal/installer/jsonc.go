@@ -0,0 +1,470 @@
+// internal/installer/jsonc.go
+package installer
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// opencode.json may contain JSONC comments and hand-maintained key ordering.
+// Rather than round-tripping the whole file through map[string]interface{},
+// the installer parses a comment-stripped copy and then patches only the
+// subtrees it owns back into the original bytes.
+
+// jsoncMember is an object member located in a JSONC document
+type jsoncMember struct {
+	key        string
+	keyStart   int
+	valueStart int
+	valueEnd   int
+}
+
+// parseJSONC unmarshals JSON that may contain comments and trailing commas
+func parseJSONC(data []byte, v interface{}) error {
+	return json.Unmarshal(stripJSONC(data), v)
+}
+
+// describeJSONError says where in data a parse error happened. For a syntax
+// error that is the byte offset plus the line and column, which stay accurate
+// because stripJSONC keeps offsets intact.
+func describeJSONError(data []byte, err error) string {
+	var syntaxErr *json.SyntaxError
+	if !errors.As(err, &syntaxErr) {
+		return err.Error()
+	}
+	// Offset counts the offending byte, so it sits at Offset-1
+	pos := min(max(int(syntaxErr.Offset)-1, 0), len(data))
+	line := 1 + bytes.Count(data[:pos], []byte("\n"))
+	column := pos - bytes.LastIndexByte(data[:pos], '\n')
+	return fmt.Sprintf("%v at byte %d (line %d, column %d)", syntaxErr, syntaxErr.Offset, line, column)
+}
+
+// stripJSONC blanks out comments and trailing commas with spaces, so byte
+// offsets in any resulting syntax error still match the original file.
+func stripJSONC(data []byte) []byte {
+	out := make([]byte, 0, len(data))
+	for i := 0; i < len(data); {
+		c := data[i]
+		switch {
+		case c == '"':
+			end, err := scanJSONCString(data, i)
+			if err != nil {
+				return append(out, data[i:]...)
+			}
+			out = append(out, data[i:end]...)
+			i = end
+		case c == '/' && i+1 < len(data) && (data[i+1] == '/' || data[i+1] == '*'):
+			end := skipJSONCComment(data, i)
+			for _, b := range data[i:end] {
+				if b == '\n' {
+					out = append(out, '\n')
+				} else {
+					out = append(out, ' ')
+				}
+			}
+			i = end
+		case c == ',':
+			next := skipJSONCSpace(data, i+1)
+			if next < len(data) && (data[next] == '}' || data[next] == ']') {
+				out = append(out, ' ')
+			} else {
+				out = append(out, ',')
+			}
+			i++
+		default:
+			out = append(out, c)
+			i++
+		}
+	}
+	return out
+}
+
+// skipJSONCComment returns the offset just past the comment starting at i
+func skipJSONCComment(data []byte, i int) int {
+	if data[i+1] == '/' {
+		for i < len(data) && data[i] != '\n' {
+			i++
+		}
+		return i
+	}
+	end := bytes.Index(data[i+2:], []byte("*/"))
+	if end < 0 {
+		return len(data)
+	}
+	return i + 2 + end + 2
+}
+
+// skipJSONCSpace returns the offset of the next byte that is neither whitespace nor part of a comment
+func skipJSONCSpace(data []byte, i int) int {
+	for i < len(data) {
+		switch {
+		case data[i] == ' ' || data[i] == '\t' || data[i] == '\n' || data[i] == '\r':
+			i++
+		case data[i] == '/' && i+1 < len(data) && (data[i+1] == '/' || data[i+1] == '*'):
+			i = skipJSONCComment(data, i)
+		default:
+			return i
+		}
+	}
+	return i
+}
+
+func scanJSONCString(data []byte, i int) (int, error) {
+	for j := i + 1; j < len(data); j++ {
+		switch data[j] {
+		case '\\':
+			j++
+		case '"':
+			return j + 1, nil
+		}
+	}
+	return 0, fmt.Errorf("unterminated string at offset %d", i)
+}
+
+// scanJSONCValue returns the offset just past the value starting at i
+func scanJSONCValue(data []byte, i int) (int, error) {
+	if i >= len(data) {
+		return 0, fmt.Errorf("unexpected end of input")
+	}
+	switch data[i] {
+	case '"':
+		return scanJSONCString(data, i)
+	case '{', '[':
+		depth := 0
+		for j := i; j < len(data); {
+			j = skipJSONCSpace(data, j)
+			if j >= len(data) {
+				break
+			}
+			switch data[j] {
+			case '"':
+				end, err := scanJSONCString(data, j)
+				if err != nil {
+					return 0, err
+				}
+				j = end
+				continue
+			case '{', '[':
+				depth++
+			case '}', ']':
+				depth--
+				if depth == 0 {
+					return j + 1, nil
+				}
+			}
+			j++
+		}
+		return 0, fmt.Errorf("unterminated %c at offset %d", data[i], i)
+	default:
+		j := i
+		for j < len(data) && !strings.ContainsRune(",}] \t\r\n/", rune(data[j])) {
+			j++
+		}
+		if j == i {
+			return 0, fmt.Errorf("unexpected %q at offset %d", data[i], i)
+		}
+		return j, nil
+	}
+}
+
+// jsoncMembers lists the members of the object whose '{' is at start, and
+// returns the offset of its closing '}'.
+func jsoncMembers(data []byte, start int) ([]jsoncMember, int, error) {
+	var members []jsoncMember
+	i := start + 1
+	for {
+		i = skipJSONCSpace(data, i)
+		if i >= len(data) {
+			return nil, 0, fmt.Errorf("unterminated object at offset %d", start)
+		}
+		switch data[i] {
+		case '}':
+			return members, i, nil
+		case ',':
+			i++
+		case '"':
+			keyEnd, err := scanJSONCString(data, i)
+			if err != nil {
+				return nil, 0, err
+			}
+			var key string
+			if err := json.Unmarshal(data[i:keyEnd], &key); err != nil {
+				return nil, 0, fmt.Errorf("invalid key at offset %d: %w", i, err)
+			}
+			colon := skipJSONCSpace(data, keyEnd)
+			if colon >= len(data) || data[colon] != ':' {
+				return nil, 0, fmt.Errorf("expected ':' after key %q at offset %d", key, keyEnd)
+			}
+			valueStart := skipJSONCSpace(data, colon+1)
+			valueEnd, err := scanJSONCValue(data, valueStart)
+			if err != nil {
+				return nil, 0, err
+			}
+			members = append(members, jsoncMember{key: key, keyStart: i, valueStart: valueStart, valueEnd: valueEnd})
+			i = valueEnd
+		default:
+			return nil, 0, fmt.Errorf("unexpected %q at offset %d", data[i], i)
+		}
+	}
+}
+
+// findJSONCMember returns the index of the last member named key (encoding/json semantics), or -1
+func findJSONCMember(members []jsoncMember, key string) int {
+	for i := len(members) - 1; i >= 0; i-- {
+		if members[i].key == key {
+			return i
+		}
+	}
+	return -1
+}
+
+// patchJSONC rewrites only the members at the given key paths in original,
+// taking each new value from config; a path missing from config is deleted.
+// Everything else (comments, key order, formatting) is left byte-for-byte
+// intact, except that the result always ends in a newline as OpenCode's own
+// files do. New values are written with sorted keys, so patching the same
+// config twice gives identical bytes.
+func patchJSONC(original []byte, config map[string]interface{}, paths ...[]string) ([]byte, error) {
+	if len(bytes.TrimSpace(original)) == 0 {
+		out, err := marshalJSON(config, "")
+		if err != nil {
+			return nil, err
+		}
+		return append(out, '\n'), nil
+	}
+
+	out := original
+	for _, path := range paths {
+		value, ok := lookupJSONPath(config, path)
+		var err error
+		if ok {
+			out, err = setJSONCValue(out, path, value)
+		} else {
+			out, err = deleteJSONCMember(out, path)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	if !bytes.HasSuffix(out, []byte("\n")) {
+		out = append(out, '\n')
+	}
+	return out, nil
+}
+
+func lookupJSONPath(config map[string]interface{}, path []string) (interface{}, bool) {
+	var current interface{} = config
+	for _, key := range path {
+		obj, ok := current.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		if current, ok = obj[key]; !ok {
+			return nil, false
+		}
+	}
+	return current, true
+}
+
+func jsoncRoot(data []byte) (int, error) {
+	root := skipJSONCSpace(data, 0)
+	if root >= len(data) || data[root] != '{' {
+		return 0, fmt.Errorf("config root is not a JSON object")
+	}
+	return root, nil
+}
+
+func setJSONCValue(data []byte, path []string, value interface{}) ([]byte, error) {
+	root, err := jsoncRoot(data)
+	if err != nil {
+		return nil, err
+	}
+	return setJSONCInObject(data, root, path, value)
+}
+
+func setJSONCInObject(data []byte, objStart int, path []string, value interface{}) ([]byte, error) {
+	members, closeAt, err := jsoncMembers(data, objStart)
+	if err != nil {
+		return nil, err
+	}
+
+	idx := findJSONCMember(members, path[0])
+	if idx < 0 {
+		return insertJSONCMember(data, objStart, closeAt, members, path[0], nestJSONValue(path[1:], value))
+	}
+
+	member := members[idx]
+	if len(path) > 1 && data[member.valueStart] == '{' {
+		return setJSONCInObject(data, member.valueStart, path[1:], value)
+	}
+
+	encoded, err := marshalJSONCValue(nestJSONValue(path[1:], value), lineIndent(data, member.keyStart))
+	if err != nil {
+		return nil, err
+	}
+	return splice(data, member.valueStart, member.valueEnd, encoded), nil
+}
+
+// nestJSONValue wraps value in objects for each remaining key in path
+func nestJSONValue(path []string, value interface{}) interface{} {
+	for i := len(path) - 1; i >= 0; i-- {
+		value = map[string]interface{}{path[i]: value}
+	}
+	return value
+}
+
+func insertJSONCMember(data []byte, objStart, closeAt int, members []jsoncMember, key string, value interface{}) ([]byte, error) {
+	quotedKey, err := json.Marshal(key)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(members) == 0 {
+		outer := lineIndent(data, objStart)
+		indent := outer + "  "
+		encoded, err := marshalJSONCValue(value, indent)
+		if err != nil {
+			return nil, err
+		}
+		text := "\n" + indent + string(quotedKey) + ": " + encoded + "\n" + outer
+		return splice(data, objStart+1, closeAt, text), nil
+	}
+
+	last := members[len(members)-1]
+	indent := lineIndent(data, last.keyStart)
+	encoded, err := marshalJSONCValue(value, indent)
+	if err != nil {
+		return nil, err
+	}
+	text := "\n" + indent + string(quotedKey) + ": " + encoded
+
+	// Keep a comma (and any same-line comment) attached to the previous member
+	pos := skipInlineSpace(data, last.valueEnd)
+	hasComma := pos < len(data) && data[pos] == ','
+	if hasComma {
+		pos = skipInlineSpace(data, pos+1)
+		text += ","
+	} else {
+		pos = last.valueEnd
+	}
+	if c := skipInlineSpace(data, pos); c+1 < len(data) && data[c] == '/' && data[c+1] == '/' {
+		pos = skipJSONCComment(data, c)
+	}
+
+	if hasComma {
+		return splice(data, pos, pos, text), nil
+	}
+	out := splice(data, pos, pos, text)
+	return splice(out, last.valueEnd, last.valueEnd, ","), nil
+}
+
+func deleteJSONCMember(data []byte, path []string) ([]byte, error) {
+	objStart, err := jsoncRoot(data)
+	if err != nil {
+		return nil, err
+	}
+
+	for depth := 0; ; depth++ {
+		members, closeAt, err := jsoncMembers(data, objStart)
+		if err != nil {
+			return nil, err
+		}
+		idx := findJSONCMember(members, path[depth])
+		if idx < 0 {
+			return data, nil
+		}
+		member := members[idx]
+
+		if depth < len(path)-1 {
+			if data[member.valueStart] != '{' {
+				return data, nil
+			}
+			objStart = member.valueStart
+			continue
+		}
+
+		if len(members) == 1 {
+			return splice(data, objStart+1, closeAt, ""), nil
+		}
+
+		// Remove the member, its own line, and any same-line trailing comment
+		from := member.keyStart
+		if ls := lineStart(data, from); strings.TrimSpace(string(data[ls:from])) == "" && ls > 0 {
+			from = ls - 1
+		}
+		to := skipInlineSpace(data, member.valueEnd)
+		if to < len(data) && data[to] == ',' && idx < len(members)-1 {
+			to++
+		} else {
+			to = member.valueEnd
+		}
+		if c := skipInlineSpace(data, to); c+1 < len(data) && data[c] == '/' && data[c+1] == '/' {
+			to = skipJSONCComment(data, c)
+		}
+
+		if idx < len(members)-1 {
+			return splice(data, from, to, ""), nil
+		}
+
+		// Last member: also drop the comma separating it from the previous one
+		out := splice(data, from, to, "")
+		prev := members[idx-1]
+		comma := skipJSONCSpace(out, prev.valueEnd)
+		if comma < len(out) && out[comma] == ',' {
+			out = splice(out, comma, comma+1, "")
+		}
+		return out, nil
+	}
+}
+
+func marshalJSONCValue(value interface{}, indent string) (string, error) {
+	encoded, err := marshalJSON(value, indent)
+	if err != nil {
+		return "", err
+	}
+	return string(encoded), nil
+}
+
+// marshalJSON is json.MarshalIndent with a two-space indent that leaves <, >
+// and & as they are, the way OpenCode writes them, rather than as \u003c etc.
+func marshalJSON(value interface{}, prefix string) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent(prefix, "  ")
+	if err := enc.Encode(value); err != nil {
+		return nil, err
+	}
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), nil
+}
+
+func splice(data []byte, from, to int, text string) []byte {
+	out := make([]byte, 0, len(data)-(to-from)+len(text))
+	out = append(out, data[:from]...)
+	out = append(out, text...)
+	return append(out, data[to:]...)
+}
+
+func lineStart(data []byte, pos int) int {
+	return bytes.LastIndexByte(data[:pos], '\n') + 1
+}
+
+// lineIndent returns the leading whitespace of the line containing pos
+func lineIndent(data []byte, pos int) string {
+	start := lineStart(data, pos)
+	end := start
+	for end < len(data) && (data[end] == ' ' || data[end] == '\t') {
+		end++
+	}
+	return string(data[start:end])
+}
+
+func skipInlineSpace(data []byte, i int) int {
+	for i < len(data) && (data[i] == ' ' || data[i] == '\t') {
+		i++
+	}
+	return i
+}
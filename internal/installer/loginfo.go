@@ -0,0 +1,117 @@
+// internal/installer/loginfo.go
+package installer
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"slices"
+	"strings"
+	"time"
+)
+
+// writeLogHeader opens the log with the environment a bug report needs: the
+// platform, tool versions, the OpenCode in use and the flags given. It is all
+// gathered locally, and home directories are shown as ~.
+func writeLogHeader(m *model, opts cliOptions) {
+	if m.logFile == nil {
+		return
+	}
+
+	var b strings.Builder
+	line := func(name string, value interface{}) {
+		b.WriteString(fmt.Sprintf("%-14s %v\n", name+":", value))
+	}
+
+	b.WriteString("=== OpenCode-Cursor Installer Log ===\n")
+	line("Started", time.Now().Format("2006-01-02 15:04:05"))
+	line("Installer", installerVersion)
+	line("OS/Arch", runtime.GOOS+"/"+runtime.GOARCH)
+	if m.container {
+		line("Container", "running in container")
+	}
+	line("Go", runtime.Version())
+
+	if pm, err := detectPackageManager(opts.pkgManager); err == nil {
+		line(pm.name, logToolVersion(m.runner, pm.name))
+	} else {
+		line("Package mgr", "none found")
+	}
+	if commandExists("cursor-agent") {
+		line("cursor-agent", logToolVersion(m.runner, "cursor-agent"))
+	} else {
+		line("cursor-agent", "not found")
+	}
+	if m.opencodeBin != "" {
+		opencode := OpenCodeInfo{BinaryPath: m.opencodeBin}
+		for _, install := range m.opencodeInstalls {
+			if install.BinaryPath == m.opencodeBin {
+				opencode = install
+			}
+		}
+		line("opencode", opencode.describe()+" at "+opencode.BinaryPath)
+	} else {
+		line("opencode", "not found")
+	}
+
+	line("Proxy", proxySummary(opts.proxy))
+	line("Config", m.configPath)
+	line("Project", m.projectDir)
+	flags := "(none)"
+	if len(opts.flags) > 0 {
+		flags = strings.Join(redactFlags(opts.flags), " ")
+	}
+	line("Flags", flags)
+	line("Debug Mode", opts.debugMode)
+	line("Dry Run", opts.dryRun)
+	line("Headless", opts.headless)
+	b.WriteString("\n")
+
+	m.logFile.WriteString(redactHome(b.String()))
+}
+
+// redactFlags masks the password of a --proxy URL in the arguments
+func redactFlags(flags []string) []string {
+	shown := slices.Clone(flags)
+	for i, arg := range shown {
+		if value, ok := strings.CutPrefix(arg, "--proxy="); ok {
+			shown[i] = "--proxy=" + redactProxyURL(value)
+		} else if arg == "--proxy" && i+1 < len(shown) {
+			shown[i+1] = redactProxyURL(shown[i+1])
+		}
+	}
+	return shown
+}
+
+// logToolVersion is a tool's --version output, or why there is none
+func logToolVersion(runner CommandRunner, command string) string {
+	raw, err := toolVersion(runner, command)
+	if err != nil {
+		return fmt.Sprintf("unknown (%v)", err)
+	}
+	return summarizeRawOutput(raw)
+}
+
+// redactHome replaces home directory paths in s with ~, for the invoking
+// user and, under sudo, for root
+func redactHome(s string) string {
+	var homes []string
+	if home, err := actualHomeDir(); err == nil {
+		homes = append(homes, home)
+	}
+	if home, err := os.UserHomeDir(); err == nil {
+		homes = append(homes, home)
+	}
+	for _, home := range homes {
+		home = filepath.Clean(home)
+		if home == string(filepath.Separator) || home == "." {
+			continue
+		}
+		// Only whole path elements, so /home/al leaves /home/alice alone
+		pattern := regexp.MustCompile(`(?m)` + regexp.QuoteMeta(home) + `([/\\\s"',;:]|$)`)
+		s = pattern.ReplaceAllString(s, "~$1")
+	}
+	return s
+}
@@ -0,0 +1,277 @@
+// internal/installer/manifest.go
+package installer
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	manifestFileName      = ".cursor-acp-manifest.json"
+	manifestSchemaVersion = 1
+)
+
+// installManifest records exactly what an install created so uninstall can
+// remove it without touching anything the user added.
+//
+// ConfigKeys uses a small path syntax: "provider.cursor-acp" (the whole
+// provider was created), "provider.cursor-acp.models.<id>" (a model added to a
+// provider the user already had) and "plugin[cursor-acp]" (plugin array entry).
+type installManifest struct {
+	Schema           int       `json:"schema"`
+	InstallerVersion string    `json:"installer_version"`
+	InstalledAt      time.Time `json:"installed_at"`
+	ConfigPath       string    `json:"config_path"`
+	BaseURL          string    `json:"base_url,omitempty"`
+	PluginVersion    string    `json:"plugin_version,omitempty"`
+	PluginPath       string    `json:"plugin_path,omitempty"`
+	PluginTarget     string    `json:"plugin_target,omitempty"`
+	PluginCopied     bool      `json:"plugin_copied,omitempty"`
+	PackagesDir      string    `json:"packages_dir,omitempty"`
+	Packages         []string  `json:"packages,omitempty"`
+	// PackageVersions is the version of each package present after install,
+	// whether or not the installer added it
+	PackageVersions map[string]string `json:"package_versions,omitempty"`
+	ConfigKeys      []string          `json:"config_keys,omitempty"`
+}
+
+const (
+	manifestProviderKey = "provider.cursor-acp"
+	manifestModelPrefix = "provider.cursor-acp.models."
+	manifestPluginKey   = "plugin[cursor-acp]"
+)
+
+// pluginVersion reads the version from the package.json owning a plugin entry
+// (<package>/dist/plugin-entry.js), or "" if it cannot be determined.
+func pluginVersion(entry string) string {
+	return packageJSONVersion(filepath.Join(filepath.Dir(filepath.Dir(entry)), "package.json"))
+}
+
+// installedPackageVersion returns the version of pkg under dir/node_modules
+func installedPackageVersion(dir, pkg string) string {
+	return packageJSONVersion(filepath.Join(dir, "node_modules", pkg, "package.json"))
+}
+
+func packageJSONVersion(path string) string {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	var pkg struct {
+		Version string `json:"version"`
+	}
+	if json.Unmarshal(data, &pkg) != nil {
+		return ""
+	}
+	return pkg.Version
+}
+
+// getManifestPath keeps the manifest next to the opencode.json it describes
+func getManifestPath(configPath string) string {
+	return filepath.Join(filepath.Dir(configPath), manifestFileName)
+}
+
+// readManifest returns nil (and no error) when no manifest exists.
+func readManifest(configPath string) (*installManifest, error) {
+	data, err := os.ReadFile(getManifestPath(configPath))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var manifest installManifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("invalid install manifest: %w", err)
+	}
+	if manifest.Schema > manifestSchemaVersion {
+		return nil, fmt.Errorf("install manifest schema %d is newer than this installer supports", manifest.Schema)
+	}
+	return &manifest, nil
+}
+
+// installerVersionWarning describes a config last written by a different
+// installer version (per the manifest beside it), or returns "".
+func installerVersionWarning(configPath string) string {
+	manifest, err := readManifest(configPath)
+	if err != nil || manifest == nil || manifest.InstallerVersion == "" || manifest.InstallerVersion == installerVersion {
+		return ""
+	}
+	return fmt.Sprintf("%s was written by installer %s; this is installer %s", configPath, manifest.InstallerVersion, installerVersion)
+}
+
+// hasKey reports whether the manifest recorded inserting key
+func (im *installManifest) hasKey(key string) bool {
+	for _, k := range im.ConfigKeys {
+		if k == key {
+			return true
+		}
+	}
+	return false
+}
+
+// addedModels returns the model IDs added to a provider the user already had
+func (im *installManifest) addedModels() []string {
+	var ids []string
+	for _, k := range im.ConfigKeys {
+		if id, ok := strings.CutPrefix(k, manifestModelPrefix); ok {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+// recordConfigKeys compares the config before and after updateConfig and
+// records what the installer inserted.
+func (im *installManifest) recordConfigKeys(before, after map[string]interface{}) {
+	providers, _ := before["provider"].(map[string]interface{})
+	if _, existed := providers["cursor-acp"]; !existed {
+		im.ConfigKeys = appendUnique(im.ConfigKeys, manifestProviderKey)
+	} else {
+		existing := configuredModels(before)
+		for id := range configuredModels(after) {
+			if _, had := existing[id]; !had {
+				im.ConfigKeys = appendUnique(im.ConfigKeys, manifestModelPrefix+id)
+			}
+		}
+	}
+
+	plugins, _ := before["plugin"].([]interface{})
+	hadPlugin := false
+	for _, p := range plugins {
+		if p == "cursor-acp" {
+			hadPlugin = true
+		}
+	}
+	if !hadPlugin {
+		im.ConfigKeys = appendUnique(im.ConfigKeys, manifestPluginKey)
+	}
+}
+
+// merge folds a manifest from an earlier install into this one so a reinstall
+// still remembers what the first install created.
+func (im *installManifest) merge(previous *installManifest) {
+	if previous == nil {
+		return
+	}
+	for _, pkg := range previous.Packages {
+		im.Packages = appendUnique(im.Packages, pkg)
+	}
+	if im.PackagesDir == "" {
+		im.PackagesDir = previous.PackagesDir
+	}
+	for pkg, version := range previous.PackageVersions {
+		if _, ok := im.PackageVersions[pkg]; !ok {
+			im.recordPackageVersion(pkg, version)
+		}
+	}
+	for _, key := range previous.ConfigKeys {
+		im.ConfigKeys = appendUnique(im.ConfigKeys, key)
+	}
+	// A created provider subsumes any per-model entries
+	if im.hasKey(manifestProviderKey) {
+		var keys []string
+		for _, key := range im.ConfigKeys {
+			if !strings.HasPrefix(key, manifestModelPrefix) {
+				keys = append(keys, key)
+			}
+		}
+		im.ConfigKeys = keys
+	}
+}
+
+// recordPackageVersion notes the installed version of pkg
+func (im *installManifest) recordPackageVersion(pkg, version string) {
+	if version == "" {
+		return
+	}
+	if im.PackageVersions == nil {
+		im.PackageVersions = map[string]string{}
+	}
+	im.PackageVersions[pkg] = version
+}
+
+func appendUnique(list []string, value string) []string {
+	for _, v := range list {
+		if v == value {
+			return list
+		}
+	}
+	return append(list, value)
+}
+
+// writeManifest persists what this install created, merged with any earlier manifest.
+func writeManifest(ic *installContext) error {
+	manifestPath := getManifestPath(ic.configPath)
+	if ic.dryRun {
+		return skipTask("would write %s", manifestPath)
+	}
+
+	manifest := ic.state.manifest
+	if manifest == nil {
+		manifest = &installManifest{}
+	}
+	manifest.Schema = manifestSchemaVersion
+	manifest.InstallerVersion = installerVersion
+	manifest.InstalledAt = time.Now()
+	manifest.ConfigPath = ic.configPath
+
+	previous, err := readManifest(ic.configPath)
+	if err != nil && ic.logFile != nil {
+		ic.logFile.WriteString(fmt.Sprintf("Warning: ignoring existing manifest: %v\n", err))
+	}
+	manifest.merge(previous)
+	sort.Strings(manifest.Packages)
+	sort.Strings(manifest.ConfigKeys)
+
+	// A re-install that recorded nothing new keeps the manifest, and with it
+	// the date of the install that did the work
+	if previous != nil {
+		installedAt := manifest.InstalledAt
+		manifest.InstalledAt = previous.InstalledAt
+		existing, readErr := os.ReadFile(manifestPath)
+		if data, err := json.MarshalIndent(manifest, "", "  "); err == nil && readErr == nil && bytes.Equal(existing, append(data, '\n')) {
+			return alreadyDone("%s is up to date", manifestPath)
+		}
+		manifest.InstalledAt = installedAt
+	}
+
+	if err := createBackup(ic, manifestPath); err != nil {
+		return fmt.Errorf("failed to backup manifest: %w", err)
+	}
+
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to serialize manifest: %w", err)
+	}
+	if err := writeFileAtomic(manifestPath, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write manifest: %w", err)
+	}
+	ic.chownToUser(false, manifestPath)
+	return nil
+}
+
+// removeManifest deletes the manifest once everything it lists is gone.
+func removeManifest(ic *installContext) error {
+	manifestPath := getManifestPath(ic.configPath)
+	if ic.dryRun {
+		return skipTask("would remove %s", manifestPath)
+	}
+	if err := createBackup(ic, manifestPath); err != nil {
+		return err
+	}
+	if err := os.Remove(manifestPath); err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to remove manifest: %w", err)
+	}
+	ic.state.removed = append(ic.state.removed, manifestPath)
+	return nil
+}
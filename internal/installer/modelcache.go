@@ -0,0 +1,188 @@
+// internal/installer/modelcache.go
+package installer
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/user"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	modelCacheFile       = "models.json"
+	defaultModelCacheTTL = time.Hour
+)
+
+// modelCache is the on-disk copy of the last successful cursor-agent fetch.
+type modelCache struct {
+	FetchedAt time.Time              `json:"fetched_at"`
+	Models    map[string]interface{} `json:"models"`
+}
+
+// getCacheDir returns ~/.cache/opencode-cursor for the actual user (not root
+// when using sudo)
+func getCacheDir() (string, error) {
+	if sudoUser := os.Getenv("SUDO_USER"); sudoUser != "" && sudoUser != "root" {
+		u, err := user.Lookup(sudoUser)
+		if err == nil {
+			return filepath.Join(u.HomeDir, ".cache", "opencode-cursor"), nil
+		}
+	}
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(homeDir, ".cache", "opencode-cursor"), nil
+}
+
+func readModelCache() (*modelCache, error) {
+	dir, err := getCacheDir()
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(filepath.Join(dir, modelCacheFile))
+	if err != nil {
+		return nil, err
+	}
+	var cache modelCache
+	if err := json.Unmarshal(data, &cache); err != nil {
+		return nil, fmt.Errorf("invalid model cache: %w", err)
+	}
+	if len(cache.Models) == 0 {
+		return nil, fmt.Errorf("model cache is empty")
+	}
+	return &cache, nil
+}
+
+func writeModelCache(models map[string]interface{}) error {
+	dir, err := getCacheDir()
+	if err != nil {
+		return err
+	}
+	created, err := mkdirAllForUser(dir)
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(modelCache{FetchedAt: time.Now(), Models: models}, "", "  ")
+	if err != nil {
+		return err
+	}
+	path := filepath.Join(dir, modelCacheFile)
+	if err := writeFileAtomic(path, data, 0644); err != nil {
+		return err
+	}
+	for _, p := range append(created, path) {
+		if err := chownToActualUser(p); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// loadModels returns the cursor-agent models, narrowed by --exclude-models
+// and --only-models, and a short description of where they came from.
+func loadModels(ic *installContext) (map[string]interface{}, string, error) {
+	models, source, err := loadAllModels(ic)
+	if err != nil {
+		return nil, "", err
+	}
+	filtered, err := filterModels(models, ic.excludeModels, ic.onlyModels)
+	if err != nil {
+		return nil, "", err
+	}
+	if dropped := len(models) - len(filtered); dropped > 0 {
+		source = fmt.Sprintf("%s, %d filtered out", source, dropped)
+	}
+	return filtered, source, nil
+}
+
+// loadAllModels returns every model before filtering. --models-from-file
+// bypasses cursor-agent entirely; --models-endpoint is tried before its CLI
+// (see fetchLiveModels). A cache younger than ic.modelsTTL is used
+// as-is unless --refresh-models or --capture-models-output was given; an
+// older cache is still used when cursor-agent cannot be run at all.
+func loadAllModels(ic *installContext) (map[string]interface{}, string, error) {
+	if ic.modelsFile != "" {
+		models, err := readModelsFile(ic.modelsFile)
+		if err != nil {
+			return nil, "", err
+		}
+		return models, ic.modelsFile, nil
+	}
+
+	cache, cacheErr := readModelCache()
+	if cacheErr == nil && !ic.refreshModels && ic.captureModels == "" && time.Since(cache.FetchedAt) < ic.modelsTTL {
+		return cache.Models, fmt.Sprintf("cache (%s old)", modelCacheAge(cache)), nil
+	}
+
+	models, source, err := fetchLiveModels(ic)
+	if err != nil {
+		var installerErr *InstallerError
+		if cacheErr == nil && errors.As(err, &installerErr) && installerErr.Category == "EXEC" {
+			if ic.logFile != nil {
+				ic.logFile.WriteString(fmt.Sprintf("cursor-agent unavailable, using cached models: %v\n", err))
+			}
+			return cache.Models, fmt.Sprintf("cache (%s old, cursor-agent unavailable)", modelCacheAge(cache)), nil
+		}
+		return nil, "", fmt.Errorf("failed to fetch models from cursor-agent: %w", err)
+	}
+	models, dropped := normalizeModelIDs(models)
+	for _, problem := range dropped {
+		if ic.logFile != nil {
+			ic.logFile.WriteString("Warning: " + problem + "\n")
+		}
+	}
+	if len(models) == 0 {
+		return nil, "", NewParseError("no usable model IDs from cursor-agent", strings.Join(dropped, "\n"), nil)
+	}
+
+	if !ic.dryRun {
+		if err := writeModelCache(models); err != nil && ic.logFile != nil {
+			ic.logFile.WriteString(fmt.Sprintf("Warning: failed to write model cache: %v\n", err))
+		}
+	}
+	return models, source, nil
+}
+
+// readModelsFile loads a pre-staged models map (--models-from-file) in the
+// same shape as the provider config: {"<id>": {"name": "<display name>"}}.
+func readModelsFile(path string) (map[string]interface{}, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, NewConfigError("failed to read models file", path, err)
+	}
+
+	var models map[string]interface{}
+	if err := parseJSONC(data, &models); err != nil {
+		return nil, NewConfigError("models file must be a JSON object of model IDs", path, err)
+	}
+	if len(models) == 0 {
+		return nil, NewConfigError("models file contains no models", path, nil)
+	}
+
+	ids := make([]string, 0, len(models))
+	for id := range models {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		value := models[id]
+		entry, ok := value.(map[string]interface{})
+		if !ok {
+			return nil, NewConfigError(fmt.Sprintf("model %q must be an object, got %T", id, value), path, nil)
+		}
+		if name, _ := entry["name"].(string); strings.TrimSpace(name) == "" {
+			return nil, NewConfigError(fmt.Sprintf("model %q is missing a \"name\"", id), path, nil)
+		}
+	}
+	return models, nil
+}
+
+func modelCacheAge(cache *modelCache) string {
+	return time.Since(cache.FetchedAt).Round(time.Second).String()
+}
@@ -0,0 +1,68 @@
+// internal/installer/modelfilter.go
+package installer
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+)
+
+// parseModelPatterns splits a comma-separated --exclude-models/--only-models
+// value into model IDs or glob patterns (e.g. "gpt-5*,opus-*").
+func parseModelPatterns(flag, value string) ([]string, error) {
+	var patterns []string
+	for _, p := range strings.Split(value, ",") {
+		p = strings.TrimSpace(p)
+		if p == "" {
+			continue
+		}
+		if _, err := path.Match(p, ""); err != nil {
+			return nil, fmt.Errorf("invalid %s pattern %q: %v", flag, p, err)
+		}
+		patterns = append(patterns, p)
+	}
+	if len(patterns) == 0 {
+		return nil, fmt.Errorf("%s requires at least one model ID or pattern", flag)
+	}
+	return patterns, nil
+}
+
+func matchesAny(id string, patterns []string) bool {
+	for _, p := range patterns {
+		if ok, _ := path.Match(p, id); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// filterModels keeps the models matching only (when given) and drops those
+// matching exclude. An empty result is an error: writing a provider with no
+// models would leave cursor-acp unusable.
+func filterModels(models map[string]interface{}, exclude, only []string) (map[string]interface{}, error) {
+	if len(exclude) == 0 && len(only) == 0 {
+		return models, nil
+	}
+	filtered := make(map[string]interface{}, len(models))
+	for id, entry := range models {
+		if len(only) > 0 && !matchesAny(id, only) {
+			continue
+		}
+		if matchesAny(id, exclude) {
+			continue
+		}
+		filtered[id] = entry
+	}
+	if len(filtered) == 0 {
+		ids := make([]string, 0, len(models))
+		for id := range models {
+			ids = append(ids, id)
+		}
+		sort.Strings(ids)
+		err := NewValidationError("--exclude-models/--only-models removed every model", "available: "+strings.Join(ids, ", "), nil)
+		err.Remediation = "Loosen --exclude-models or --only-models so at least one of the available models remains"
+		return nil, err
+	}
+	return filtered, nil
+}
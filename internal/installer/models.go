@@ -0,0 +1,149 @@
+// internal/installer/models.go
+package installer
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+)
+
+// configuredModel is one model of the cursor-acp provider, as `models` lists it
+type configuredModel struct {
+	ID   string `json:"id"`
+	Name string `json:"name,omitempty"`
+}
+
+// runModels lists the models configured for cursor-acp, or with
+// `models remove <id>` takes one out of opencode.json.
+func runModels(m model, args []string) int {
+	if len(args) > 0 {
+		switch args[0] {
+		case "remove":
+			if len(args) != 2 {
+				fmt.Fprintln(os.Stderr, "Error: usage: models remove <id>")
+				return 2
+			}
+			return removeModel(m, args[1])
+		default:
+			fmt.Fprintf(os.Stderr, "Error: unknown models command %q (available: remove)\n", args[0])
+			return 2
+		}
+	}
+
+	config, _, err := readConfig(m.configPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+	providers, _ := config["provider"].(map[string]interface{})
+	if _, ok := providers["cursor-acp"]; !ok {
+		fmt.Fprintf(os.Stderr, "Error: cursor-acp provider not found in %s - run a full install first\n", m.configPath)
+		return 1
+	}
+	models := listConfiguredModels(config)
+
+	if m.jsonOutput {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetEscapeHTML(false)
+		enc.Encode(models)
+		return 0
+	}
+
+	if len(models) == 0 {
+		fmt.Printf("No models configured for cursor-acp in %s\n", m.configPath)
+		return 0
+	}
+	width := len("ID")
+	for _, model := range models {
+		width = max(width, len(model.ID))
+	}
+	fmt.Printf("cursor-acp models in %s:\n\n", m.configPath)
+	fmt.Printf("  %-*s  %s\n", width, "ID", "NAME")
+	for _, model := range models {
+		fmt.Printf("  %-*s  %s\n", width, model.ID, model.Name)
+	}
+	return 0
+}
+
+// listConfiguredModels returns the cursor-acp models of config sorted by ID
+func listConfiguredModels(config map[string]interface{}) []configuredModel {
+	configured := configuredModels(config)
+	models := make([]configuredModel, 0, len(configured))
+	for id, entry := range configured {
+		model := configuredModel{ID: id}
+		if fields, ok := entry.(map[string]interface{}); ok {
+			model.Name, _ = fields["name"].(string)
+		}
+		models = append(models, model)
+	}
+	sort.Slice(models, func(i, j int) bool { return models[i].ID < models[j].ID })
+	return models
+}
+
+// removeModel deletes one model from the cursor-acp provider. The config is
+// backed up first and put back if it no longer validates.
+func removeModel(m model, id string) int {
+	ic := &m.installContext
+	if !ic.dryRun {
+		unlock, err := lockConfig(ic.configPath)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return 1
+		}
+		defer unlock()
+	}
+
+	config, original, err := readConfig(ic.configPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+	models := configuredModels(config)
+	if _, ok := models[id]; !ok {
+		fmt.Fprintf(os.Stderr, "Error: model %q is not configured for cursor-acp in %s\n", id, ic.configPath)
+		return 1
+	}
+	// OpenCode needs at least one model for the provider
+	if len(models) == 1 {
+		fmt.Fprintf(os.Stderr, "Error: %q is the only cursor-acp model; use --uninstall to remove the provider\n", id)
+		return 1
+	}
+	delete(models, id)
+
+	output, err := patchJSONC(original, config, []string{"provider", "cursor-acp", "models"})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: failed to serialize config: %v\n", err)
+		return 1
+	}
+	if ic.dryRun {
+		fmt.Println(plannedWriteNote(ic.configPath, original, output))
+		return 0
+	}
+
+	if err := createBackup(ic, ic.configPath); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: failed to backup config: %v\n", err)
+		return 1
+	}
+	if err := writeFileAtomic(ic.configPath, output, 0644); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", configWriteError(ic.configPath, err))
+		return 1
+	}
+	ic.chownToUser(false, ic.configPath)
+
+	if err := validateConfig(ic); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		if err := restoreBackup(ic, ic.configPath); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		} else {
+			fmt.Fprintf(os.Stderr, "Restored %s\n", ic.configPath)
+		}
+		return 1
+	}
+
+	fmt.Printf("Removed %s from %s\n", id, ic.configPath)
+	if backupPath, ok := ic.diskBackups[ic.configPath]; ok {
+		fmt.Printf("Backup: %s\n", backupPath)
+	}
+	return 0
+}
@@ -0,0 +1,79 @@
+// internal/installer/modelscapture.go
+package installer
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// modelsCapture collects what each `cursor-agent models` run printed for
+// --capture-models-output, so a parsing bug report can include the exact
+// bytes. A nil capture records nothing.
+type modelsCapture struct {
+	runs []capturedRun
+}
+
+// capturedRun is one cursor-agent invocation and what became of its output
+type capturedRun struct {
+	args   []string
+	raw    []byte // stdout, or stdout and stderr together for the text variants
+	stderr []byte // stderr of a failed JSON variant, which Output keeps apart
+	err    error
+	result string // how the output was parsed, or why it was not
+}
+
+// record adds a run; result says what the parser made of it
+func (c *modelsCapture) record(args []string, raw, stderr []byte, err error, result string) {
+	if c == nil {
+		return
+	}
+	c.runs = append(c.runs, capturedRun{args: args, raw: raw, stderr: stderr, err: err, result: result})
+}
+
+// String renders the runs for the capture file: each run's raw output
+// byte for byte, ANSI escapes and all, then the text the parser was given
+func (c *modelsCapture) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# cursor-agent models output captured by the opencode-cursor installer, %s\n", time.Now().Format(time.RFC3339))
+	if len(c.runs) == 0 {
+		b.WriteString("# cursor-agent was not run\n")
+	}
+	for _, run := range c.runs {
+		status := "exit status 0"
+		if run.err != nil {
+			status = run.err.Error()
+		}
+		fmt.Fprintf(&b, "\n=== cursor-agent %s (%s) ===\n", strings.Join(run.args, " "), status)
+		fmt.Fprintf(&b, "result: %s\n", run.result)
+		writeCaptureSection(&b, fmt.Sprintf("raw output (%d bytes)", len(run.raw)), string(run.raw))
+		if len(run.stderr) > 0 {
+			writeCaptureSection(&b, fmt.Sprintf("raw stderr (%d bytes)", len(run.stderr)), string(run.stderr))
+		}
+		writeCaptureSection(&b, "cleaned output", stripANSI(string(run.raw)))
+	}
+	return b.String()
+}
+
+// writeCaptureSection writes a titled block of output, ending it with a
+// newline only when the output does not
+func writeCaptureSection(b *strings.Builder, title, output string) {
+	fmt.Fprintf(b, "--- %s ---\n", title)
+	b.WriteString(output)
+	if output != "" && !strings.HasSuffix(output, "\n") {
+		b.WriteString("\n")
+	}
+}
+
+// writeModelsCapture writes the capture to path. It is a debugging aid, so
+// failing to write it is a warning rather than a failed install.
+func writeModelsCapture(ic *installContext, capture *modelsCapture) {
+	if err := os.WriteFile(ic.captureModels, []byte(capture.String()), 0644); err != nil {
+		ic.state.warn("capture", "could not write --capture-models-output %s: %v", ic.captureModels, err)
+		return
+	}
+	if ic.logFile != nil {
+		ic.logFile.WriteString(fmt.Sprintf("cursor-agent models output captured to %s\n", ic.captureModels))
+	}
+}
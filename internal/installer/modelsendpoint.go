@@ -0,0 +1,101 @@
+// internal/installer/modelsendpoint.go
+package installer
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// modelsEndpointTimeout bounds a --models-endpoint request; the CLI is the
+// fallback, so an unresponsive endpoint should not hold the install up
+const modelsEndpointTimeout = 5 * time.Second
+
+// parseModelsEndpoint validates a --models-endpoint URL
+func parseModelsEndpoint(value string) (string, error) {
+	u, err := url.Parse(value)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Hostname() == "" {
+		return "", fmt.Errorf("invalid --models-endpoint %q (expected an http(s) URL, e.g. http://127.0.0.1:32124/models)", value)
+	}
+	return u.String(), nil
+}
+
+// fetchEndpointModels asks a long-running cursor-agent session for its
+// models over HTTP instead of spawning `cursor-agent models`. The response
+// is JSON in any of the shapes parseCursorModelsJSON accepts; the entries it
+// rejects are returned too.
+func fetchEndpointModels(parent context.Context, proxy, endpoint string) (map[string]interface{}, []string, error) {
+	ctx, cancel := context.WithTimeout(parent, modelsEndpointTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	resp, err := httpClient(proxy).Do(req)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, nil, fmt.Errorf("%s returned %s: %s", endpoint, resp.Status, summarizeRawOutput(string(data)))
+	}
+	models, rejected, err := parseCursorModelsJSON(data)
+	if err != nil {
+		return nil, nil, NewParseError("no models in the --models-endpoint response", string(data), err)
+	}
+	return models, rejected, nil
+}
+
+// fetchLiveModels fetches the models from --models-endpoint when it is set
+// and answers, else from the cursor-agent CLI. It returns where they came from.
+// With --capture-models-output, what cursor-agent printed is written out too.
+// Rows rejected as not valid models become a warning.
+func fetchLiveModels(ic *installContext) (map[string]interface{}, string, error) {
+	var capture *modelsCapture
+	if ic.captureModels != "" {
+		capture = &modelsCapture{}
+		defer writeModelsCapture(ic, capture)
+	}
+
+	if ic.modelsURL != "" {
+		models, rejected, err := fetchEndpointModels(ic.ctx, ic.proxy, ic.modelsURL)
+		if err == nil {
+			warnRejectedModels(ic, ic.modelsURL, rejected)
+			return models, ic.modelsURL, nil
+		}
+		if ic.logFile != nil {
+			ic.logFile.WriteString(fmt.Sprintf("--models-endpoint unavailable, running cursor-agent instead: %v\n", err))
+		}
+	}
+	models, rejected, err := fetchCursorModels(ic.ctx, ic.runner, capture)
+	if err == nil {
+		warnRejectedModels(ic, "cursor-agent", rejected)
+	}
+	return models, "live", err
+}
+
+// warnRejectedModels tells the user some rows from source were skipped
+func warnRejectedModels(ic *installContext, source string, rejected []string) {
+	if len(rejected) == 0 {
+		return
+	}
+	rows := "rows"
+	if len(rejected) == 1 {
+		rows = "row"
+	}
+	message := fmt.Sprintf("skipped %d %s from %s that are not valid models: %s", len(rejected), rows, source, describeRejectedModels(rejected))
+	ic.state.warn("models", "%s", message)
+	if ic.logFile != nil {
+		ic.logFile.WriteString("Warning: " + message + "\n")
+	}
+}
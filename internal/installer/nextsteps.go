@@ -0,0 +1,89 @@
+// internal/installer/nextsteps.go
+package installer
+
+import (
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sort"
+)
+
+// nextStep is one thing to do after a successful install
+type nextStep struct {
+	Command     string `json:"command"`
+	Description string `json:"description"`
+}
+
+// nextSteps is what the user should do once the install has succeeded,
+// worked out from the pre-install checks and what the install did: log in
+// first if cursor-agent is not, get OpenCode onto PATH if it is not there,
+// then start it and pick a cursor-acp model.
+func (m model) nextSteps() []nextStep {
+	if m.isUninstall || m.dryRun {
+		return nil
+	}
+
+	var steps []nextStep
+	if m.needsLogin() || !cursorAgentLoggedIn(m.runner) {
+		steps = append(steps, nextStep{"cursor-agent login", "Log in so cursor-acp can reach Cursor"})
+	}
+
+	switch {
+	case !m.openCodeFound():
+		steps = append(steps, nextStep{installCommand("opencode"), "Install OpenCode"})
+	case !onPath("opencode", m.opencodeBin):
+		dir := filepath.Dir(m.opencodeBin)
+		if runtime.GOOS == "windows" {
+			steps = append(steps, nextStep{`setx PATH "%PATH%;` + dir + `"`, "Put OpenCode on your PATH, then open a new terminal"})
+		} else {
+			steps = append(steps, nextStep{`export PATH="` + dir + `:$PATH"`, "Put OpenCode on your PATH (add it to your shell profile)"})
+		}
+	}
+	steps = append(steps,
+		nextStep{"opencode", "Start OpenCode"},
+		nextStep{"/models", "Pick " + m.exampleModel() + " (or another cursor-acp model) in OpenCode"},
+	)
+	return steps
+}
+
+// openCodeFound reports whether the pre-install checks found OpenCode
+func (m model) openCodeFound() bool {
+	if m.opencodeBin == "" {
+		return false
+	}
+	for _, check := range m.checks {
+		if check.name == "OpenCode" {
+			return check.passed
+		}
+	}
+	return true
+}
+
+// onPath reports whether command on PATH resolves to bin
+func onPath(command, bin string) bool {
+	path, err := exec.LookPath(command)
+	if err != nil {
+		return false
+	}
+	resolve := func(p string) string {
+		if real, err := filepath.EvalSymlinks(p); err == nil {
+			return real
+		}
+		return p
+	}
+	return resolve(path) == resolve(bin)
+}
+
+// exampleModel names a configured model as OpenCode shows it, preferring auto
+func (m model) exampleModel() string {
+	models := configuredModels(m.currentConfig())
+	if _, ok := models["auto"]; ok || len(models) == 0 {
+		return "cursor-acp/auto"
+	}
+	ids := make([]string, 0, len(models))
+	for id := range models {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return "cursor-acp/" + ids[0]
+}
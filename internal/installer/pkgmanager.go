@@ -0,0 +1,66 @@
+// internal/installer/pkgmanager.go
+package installer
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// packageManager translates the installer's package operations into one
+// manager's command line. Arguments are appended to each verb.
+type packageManager struct {
+	name    string
+	install []string // install a project's dependencies
+	add     []string // add packages to a directory's package.json
+	run     []string // run a package.json script
+	repair  []string // reinstall dependencies, bypassing caches
+}
+
+// packageManagers lists the supported managers in detection order
+var packageManagers = []packageManager{
+	{name: "bun", install: []string{"install"}, add: []string{"add"}, run: []string{"run"}, repair: []string{"install", "--force", "--no-cache"}},
+	{name: "pnpm", install: []string{"install"}, add: []string{"add"}, run: []string{"run"}, repair: []string{"install", "--force"}},
+	{name: "npm", install: []string{"install"}, add: []string{"install"}, run: []string{"run"}, repair: []string{"install", "--force", "--prefer-online"}},
+}
+
+func packageManagerNames() []string {
+	names := make([]string, len(packageManagers))
+	for i, pm := range packageManagers {
+		names[i] = pm.name
+	}
+	return names
+}
+
+// detectPackageManager returns the manager named by --package-manager, or
+// the first one installed (bun > pnpm > npm).
+func detectPackageManager(preferred string) (packageManager, error) {
+	for _, pm := range packageManagers {
+		if preferred != "" && pm.name != preferred {
+			continue
+		}
+		if commandExists(pm.name) {
+			return pm, nil
+		}
+		if preferred != "" {
+			return pm, fmt.Errorf("%s not found (requested by --package-manager)", pm.name)
+		}
+	}
+	if preferred != "" {
+		return packageManager{}, fmt.Errorf("unknown package manager %q (supported: %s)", preferred, strings.Join(packageManagerNames(), ", "))
+	}
+	return packageManager{}, fmt.Errorf("no package manager found - install bun with: %s (pnpm and npm also work)", installCommand("bun"))
+}
+
+// command builds `<manager> <verb...> <args...>` running in dir
+func (pm packageManager) command(ctx context.Context, dir string, verb []string, args ...string) *exec.Cmd {
+	cmd := exec.CommandContext(ctx, pm.name, append(append([]string{}, verb...), args...)...)
+	cmd.Dir = dir
+	return cmd
+}
+
+// describe renders the command line for logs and dry-run notes
+func (pm packageManager) describe(verb []string, args ...string) string {
+	return strings.Join(append(append([]string{pm.name}, verb...), args...), " ")
+}
@@ -0,0 +1,122 @@
+// internal/installer/plugindir.go
+package installer
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// openCodeConfigRoot is a config directory OpenCode scans for plugin/ and
+// plugins/, and how the installer learned of it
+type openCodeConfigRoot struct {
+	dir    string
+	source string
+}
+
+// openCodeConfigRoots lists the config directories OpenCode loads plugins
+// from: its global one, as the opencode binary reports it when it can (so a
+// custom XDG_CONFIG_HOME or packaged layout is followed), then
+// $OPENCODE_CONFIG_DIR
+func openCodeConfigRoots(runner CommandRunner, opencode OpenCodeInfo) []openCodeConfigRoot {
+	var roots []openCodeConfigRoot
+	if dir := openCodeReportedConfigDir(runner, opencode); dir != "" {
+		roots = append(roots, openCodeConfigRoot{dir, "OpenCode's config directory (opencode debug paths)"})
+	} else if opencode.ConfigDir != "" {
+		roots = append(roots, openCodeConfigRoot{opencode.ConfigDir, "OpenCode's default config directory"})
+	}
+	if dir := os.Getenv("OPENCODE_CONFIG_DIR"); dir != "" && filepath.IsAbs(dir) {
+		roots = append(roots, openCodeConfigRoot{filepath.Clean(dir), "$OPENCODE_CONFIG_DIR"})
+	}
+	return roots
+}
+
+// openCodeReportedConfigDir asks opencode which config directory it uses.
+// It returns "" when it cannot say: no opencode, a version without `debug
+// paths`, or running under sudo, where it would answer for root.
+func openCodeReportedConfigDir(runner CommandRunner, opencode OpenCodeInfo) string {
+	if !opencode.Installed {
+		return ""
+	}
+	if sudoUser := os.Getenv("SUDO_USER"); sudoUser != "" && sudoUser != "root" {
+		return ""
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	output, err := runner.Output(exec.CommandContext(ctx, opencode.BinaryPath, "debug", "paths"))
+	if err != nil {
+		return ""
+	}
+	for _, line := range strings.Split(stripANSI(string(output)), "\n") {
+		key, value, ok := strings.Cut(strings.TrimSpace(line), " ")
+		if !ok || key != "config" {
+			continue
+		}
+		if dir := strings.TrimSpace(value); filepath.IsAbs(dir) {
+			return filepath.Clean(dir)
+		}
+	}
+	return ""
+}
+
+// detectPluginDir picks where to link the plugin when --plugin-dir is not
+// given, and says why. The directory beside the config is used when OpenCode
+// scans it; a config kept elsewhere (--config) has no plugin directory
+// OpenCode looks at, so the plugin goes under the first config directory it
+// does scan. The directory may not exist yet; createSymlink makes it.
+func detectPluginDir(runner CommandRunner, opencode OpenCodeInfo, configPath string) (string, string) {
+	beside := pluginDirNextTo(configPath)
+	roots := openCodeConfigRoots(runner, opencode)
+	configDir := filepath.Clean(filepath.Dir(configPath))
+	for _, root := range roots {
+		if root.dir == configDir {
+			return beside, root.source
+		}
+	}
+	if len(roots) > 0 {
+		root := roots[0]
+		return pluginDirNextTo(filepath.Join(root.dir, "opencode.json")), fmt.Sprintf("%s; OpenCode does not scan %s", root.source, configDir)
+	}
+	return beside, "beside " + configPath
+}
+
+// pluginDirReport is the result of --detect-plugin-dir --json
+type pluginDirReport struct {
+	Type       string `json:"type"` // "plugin_dir"
+	PluginDir  string `json:"plugin_dir"`
+	PluginPath string `json:"plugin_path"`
+	Source     string `json:"source"` // why this directory
+	Exists     bool   `json:"exists"` // false when installing would create it
+}
+
+// runDetectPluginDir is --detect-plugin-dir: it prints where the plugin
+// would be linked and why, and exits. Nothing is changed.
+func runDetectPluginDir(m model) int {
+	_, statErr := os.Stat(m.pluginDir)
+	report := pluginDirReport{
+		Type:       "plugin_dir",
+		PluginDir:  m.pluginDir,
+		PluginPath: filepath.Join(m.pluginDir, "cursor-acp.js"),
+		Source:     m.pluginDirFrom,
+		Exists:     statErr == nil,
+	}
+
+	if m.jsonOutput {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetEscapeHTML(false)
+		enc.Encode(report)
+		return 0
+	}
+	fmt.Println(report.PluginPath)
+	fmt.Printf("  from: %s\n", report.Source)
+	if !report.Exists {
+		fmt.Printf("  %s does not exist yet; installing creates it\n", report.PluginDir)
+	}
+	return 0
+}
@@ -0,0 +1,139 @@
+// internal/installer/ports.go
+package installer
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"time"
+)
+
+const (
+	portProbeTimeout = 500 * time.Millisecond
+	autoPortRange    = 100 // ports tried after the default by --port auto
+)
+
+// pickFreePort rewrites baseURL to the first port from the plugin's default
+// upwards that can be bound on its host (--port auto).
+func pickFreePort(baseURL string) (string, error) {
+	u, err := url.Parse(baseURL)
+	if err != nil {
+		return "", err
+	}
+	defaultURL, _ := url.Parse(defaultBaseURL)
+	start, _ := strconv.Atoi(defaultURL.Port())
+
+	host := u.Hostname()
+	for port := start; port < start+autoPortRange; port++ {
+		addr := net.JoinHostPort(host, strconv.Itoa(port))
+		ln, err := net.Listen("tcp", addr)
+		if err != nil {
+			continue
+		}
+		ln.Close()
+		u.Host = addr
+		return u.String(), nil
+	}
+	return "", fmt.Errorf("no free port on %s between %d and %d - pass --port <n> explicitly", host, start, start+autoPortRange-1)
+}
+
+// checkPortAvailable warns when something other than a cursor-acp proxy is
+// already listening on the baseURL's port. Non-loopback hosts are not ours to
+// bind and are not probed.
+func checkPortAvailable(baseURL string) checkResult {
+	u, err := url.Parse(baseURL)
+	if err != nil {
+		return checkResult{name: "proxy port", passed: false, message: "cannot parse " + baseURL, warning: true}
+	}
+	host, port := u.Hostname(), urlPort(u)
+	if !isLoopbackHost(host) {
+		return checkResult{name: "proxy port", passed: true, message: fmt.Sprintf("%s is not local; skipped", u.Host)}
+	}
+
+	addr := net.JoinHostPort(host, port)
+	conn, err := net.DialTimeout("tcp", addr, portProbeTimeout)
+	if err != nil {
+		return checkResult{name: "proxy port", passed: true, message: fmt.Sprintf("%s is free", addr)}
+	}
+	conn.Close()
+
+	// An already-running cursor-acp proxy is reused by the plugin, so that's fine
+	if isCursorProxy(addr) {
+		return checkResult{name: "proxy port", passed: true, message: fmt.Sprintf("%s in use by a cursor-acp proxy", addr)}
+	}
+	return checkResult{
+		name:    "proxy port",
+		passed:  false,
+		message: fmt.Sprintf("port %s is already in use on %s - choose another with --port <n>", port, host),
+		warning: true,
+	}
+}
+
+// isCursorProxy reports whether addr answers the plugin's /health endpoint
+func isCursorProxy(addr string) bool {
+	client := http.Client{Timeout: portProbeTimeout}
+	resp, err := client.Get("http://" + addr + "/health")
+	if err != nil {
+		return false
+	}
+	defer resp.Body.Close()
+
+	var health struct {
+		OK bool `json:"ok"`
+	}
+	return resp.StatusCode == http.StatusOK && json.NewDecoder(resp.Body).Decode(&health) == nil && health.OK
+}
+
+// urlPort is u's port, or its scheme's default
+func urlPort(u *url.URL) string {
+	if port := u.Port(); port != "" {
+		return port
+	}
+	if u.Scheme == "https" {
+		return "443"
+	}
+	return "80"
+}
+
+func isLoopbackHost(host string) bool {
+	ip := net.ParseIP(host)
+	return host == "localhost" || (ip != nil && ip.IsLoopback())
+}
+
+// providersSharingPort lists the providers in config, other than cursor-acp,
+// whose options.baseURL points at the same host and port as baseURL. Every
+// loopback spelling (localhost, 127.0.0.1, ::1) counts as one host.
+func providersSharingPort(config map[string]interface{}, baseURL string) []string {
+	endpoint := func(raw string) string {
+		u, err := url.Parse(raw)
+		if err != nil || u.Hostname() == "" {
+			return ""
+		}
+		host := u.Hostname()
+		if isLoopbackHost(host) {
+			host = "loopback"
+		}
+		return host + ":" + urlPort(u)
+	}
+
+	ours := endpoint(baseURL)
+	if ours == "" {
+		return nil
+	}
+	var names []string
+	providers, _ := config["provider"].(map[string]interface{})
+	for name, value := range providers {
+		provider, _ := value.(map[string]interface{})
+		options, _ := provider["options"].(map[string]interface{})
+		other, _ := options["baseURL"].(string)
+		if name != "cursor-acp" && other != "" && endpoint(other) == ours {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
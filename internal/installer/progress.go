@@ -0,0 +1,210 @@
+// internal/installer/progress.go
+package installer
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+)
+
+const progressFileName = "install-progress.json"
+
+// installProgress is the state file --resume picks up: the tasks an install
+// got through before it failed, and what later tasks need from them. It is
+// rewritten after every task and removed once an install succeeds.
+type installProgress struct {
+	ConfigPath  string           `json:"config_path"`
+	ProjectDir  string           `json:"project_dir"`
+	Completed   []string         `json:"completed"`
+	PluginEntry string           `json:"plugin_entry,omitempty"`
+	Manifest    *installManifest `json:"manifest,omitempty"`
+	UpdatedAt   time.Time        `json:"updated_at"`
+}
+
+func progressPath() (string, error) {
+	dir, err := getCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, progressFileName), nil
+}
+
+// readProgress loads the progress of the last failed install
+func readProgress() (*installProgress, error) {
+	path, err := progressPath()
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("no earlier install to resume")
+		}
+		return nil, err
+	}
+	var progress installProgress
+	if err := json.Unmarshal(data, &progress); err != nil {
+		return nil, fmt.Errorf("invalid progress file %s: %w", path, err)
+	}
+	return &progress, nil
+}
+
+// loadResume sets up --resume: it restores the state later tasks need from
+// the earlier run, so the tasks it completed can be skipped. It returns why
+// there is nothing to resume, or "".
+func (m *model) loadResume() string {
+	progress, err := readProgress()
+	if err != nil {
+		return err.Error()
+	}
+	if progress.ConfigPath != m.configPath || progress.ProjectDir != m.projectDir {
+		return fmt.Sprintf("the earlier install used %s and %s", progress.ConfigPath, progress.ProjectDir)
+	}
+	m.resumeFrom = progress
+	m.state.pluginEntry = progress.PluginEntry
+	m.state.manifest = progress.Manifest
+	if progress.Manifest != nil {
+		m.state.pluginCopied = progress.Manifest.PluginCopied
+	}
+	return ""
+}
+
+// resumable reports whether --resume may skip task: the earlier run
+// completed it and its effects still hold
+func (m *model) resumable(task *installTask) bool {
+	return m.resumeFrom != nil && task.verify != nil &&
+		slices.Contains(m.resumeFrom.Completed, task.name) && task.verify(&m.installContext)
+}
+
+// recordProgress saves how far an install has got, or removes the record
+// once it has succeeded. Dry runs and uninstalls leave nothing to resume.
+func (m model) recordProgress() {
+	if m.isUninstall || m.dryRun {
+		return
+	}
+	path, err := progressPath()
+	if err != nil {
+		return
+	}
+	if _, failed := m.summary(); m.step == stepComplete && !failed && !m.cancelled {
+		os.Remove(path)
+		return
+	}
+
+	progress := installProgress{
+		ConfigPath:  m.configPath,
+		ProjectDir:  m.projectDir,
+		Completed:   []string{},
+		PluginEntry: m.state.pluginEntry,
+		Manifest:    m.state.manifest,
+		UpdatedAt:   time.Now(),
+	}
+	for _, task := range m.tasks {
+		if task.status == statusComplete || task.resumed {
+			progress.Completed = append(progress.Completed, task.name)
+		}
+	}
+	data, err := json.MarshalIndent(progress, "", "  ")
+	if err != nil {
+		return
+	}
+	created, err := mkdirAllForUser(filepath.Dir(path))
+	if err != nil {
+		return
+	}
+	if err := writeFileAtomic(path, data, 0644); err == nil {
+		for _, p := range append(created, path) {
+			chownToActualUser(p)
+		}
+	}
+}
+
+// The verify funcs below tell --resume whether a task's work from an earlier
+// run is still in place. Tasks without one always run again.
+
+// pluginBuilt holds while the plugin entry built (or installed) earlier exists
+// and, for a local build, no source has changed since
+func pluginBuilt(ic *installContext) bool {
+	return !ic.force && pluginEntryCurrent(ic.projectDir, ic.state.pluginEntry)
+}
+
+// pluginEntryCurrent reports whether entry exists and, if it is the local
+// build in projectDir, no source has changed since it was built
+func pluginEntryCurrent(projectDir, entry string) bool {
+	if entry == "" {
+		return false
+	}
+	info, err := os.Stat(entry)
+	if err != nil || info.Size() == 0 {
+		return false
+	}
+	if entry != filepath.Join(projectDir, "dist", "plugin-entry.js") {
+		return true
+	}
+	return !distStale(projectDir, info.ModTime())
+}
+
+// changedSince reports whether any file under paths was modified after t
+func changedSince(t time.Time, paths ...string) bool {
+	errChanged := errors.New("changed")
+	for _, root := range paths {
+		err := filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+			if err != nil {
+				return nil
+			}
+			if entry.IsDir() {
+				if entry.Name() == "node_modules" {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if info, err := entry.Info(); err == nil && info.ModTime().After(t) {
+				return errChanged
+			}
+			return nil
+		})
+		if err == errChanged {
+			return true
+		}
+	}
+	return false
+}
+
+// aiSdkInstalled holds while @ai-sdk/openai-compatible is in OpenCode's node_modules
+func aiSdkInstalled(ic *installContext) bool {
+	return opencodePackageInstalled("@ai-sdk/openai-compatible")
+}
+
+// acpSdkInstalled holds while @agentclientprotocol/sdk is in OpenCode's node_modules
+func acpSdkInstalled(ic *installContext) bool {
+	return opencodePackageInstalled("@agentclientprotocol/sdk")
+}
+
+// opencodePackageInstalled reports whether pkg is in OpenCode's node_modules
+func opencodePackageInstalled(pkg string) bool {
+	configDir, err := getConfigDir()
+	if err != nil {
+		return false
+	}
+	_, err = os.Stat(filepath.Join(configDir, "opencode", "node_modules", filepath.FromSlash(pkg), "package.json"))
+	return err == nil
+}
+
+// pluginLinked holds while the plugin link (or Windows copy) still points at
+// the plugin entry
+func pluginLinked(ic *installContext) bool {
+	if ic.state.pluginEntry == "" {
+		return false
+	}
+	symlinkPath := filepath.Join(ic.pluginDir, "cursor-acp.js")
+	if ic.state.pluginCopied {
+		return sameFileContent(ic.state.pluginEntry, symlinkPath)
+	}
+	target, err := os.Readlink(symlinkPath)
+	return err == nil && target == ic.state.pluginEntry
+}
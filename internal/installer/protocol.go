@@ -0,0 +1,117 @@
+// internal/installer/protocol.go
+package installer
+
+// --protocol turns the installer into a backend for a graphical front-end.
+// Every line on stdout is one JSON object whose "type" says which of the
+// messages below it is; nothing else is printed there. When the installer
+// needs a decision it sends a "prompt" and waits for a "response" line on
+// stdin with the same id. Closing stdin answers every prompt with a cancel.
+//
+// A run looks like:
+//
+//	-> {"type":"hello","protocol":1,"version":"1.4.0"}
+//	-> {"type":"checks","checks":[...]}
+//	-> {"type":"step","step":"installing"}
+//	-> {"type":"task_start","index":0,"name":"Check prerequisites",...}
+//	-> {"type":"task","name":"Check prerequisites","status":"complete",...}
+//	-> {"type":"prompt","id":"select_models","choices":[...]}
+//	<- {"type":"response","id":"select_models","choices":["auto","gpt-5"]}
+//	...
+//	-> {"type":"summary","completed":9,"failed":0,...}
+//
+// Fields are only ever added, never renamed or removed, within a protocol
+// version; a front-end should ignore fields and message types it does not
+// know.
+
+// protocolVersion is bumped when a message changes incompatibly
+const protocolVersion = 1
+
+// Prompt ids, also used as the "step" of the step message sent with them
+const (
+	promptSelectOpenCode   = "select_opencode"   // pick one of several opencode binaries; choices are their paths
+	promptRepairConfig     = "repair_config"     // opencode.json is not valid JSON; accept backs it up and resets it
+	promptSelectModels     = "select_models"     // choose the models to configure; choices are model IDs
+	promptConfirmConfig    = "confirm_config"    // approve the opencode.json diff; accept applies it
+	promptConfirmUninstall = "confirm_uninstall" // approve removing what is listed; accept backs it up and removes it
+)
+
+// protocolHello is the first message of every run
+type protocolHello struct {
+	Type     string `json:"type"`     // "hello"
+	Protocol int    `json:"protocol"` // protocolVersion
+	Version  string `json:"version"`  // the installer's version
+}
+
+// protocolCheck is one pre-install check
+type protocolCheck struct {
+	Name    string `json:"name"`
+	Passed  bool   `json:"passed"`
+	Warning bool   `json:"warning"` // a failure that does not block the install
+	Message string `json:"message"`
+}
+
+// protocolChecks reports the pre-install checks. The install only starts if
+// none of them failed without being a warning.
+type protocolChecks struct {
+	Type   string          `json:"type"` // "checks"
+	Checks []protocolCheck `json:"checks"`
+}
+
+// protocolStep announces a change of phase: "installing", "uninstalling",
+// one of the prompt ids, or "complete"
+type protocolStep struct {
+	Type string `json:"type"` // "step"
+	Step string `json:"step"`
+}
+
+// protocolTaskStart is sent as a task begins. Its completion is a taskReport
+// (type "task"), the same object --json prints.
+type protocolTaskStart struct {
+	Type        string `json:"type"` // "task_start"
+	Index       int    `json:"index"`
+	Total       int    `json:"total"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Optional    bool   `json:"optional"`
+}
+
+// protocolChoice is one option of a prompt
+type protocolChoice struct {
+	ID       string `json:"id"`
+	Label    string `json:"label"`
+	Selected bool   `json:"selected"` // preselected, or the default
+}
+
+// protocolPrompt asks the front-end for a decision. Prompts with choices are
+// answered with the chosen ids (exactly one for select_opencode); the others
+// with accept.
+type protocolPrompt struct {
+	Type     string           `json:"type"` // "prompt"
+	ID       string           `json:"id"`
+	Message  string           `json:"message"`
+	Choices  []protocolChoice `json:"choices,omitempty"`
+	Multiple bool             `json:"multiple,omitempty"` // more than one choice may be returned
+	Diff     []string         `json:"diff,omitempty"`     // confirm_config: unified diff lines of opencode.json
+	Remove   []uninstallItem  `json:"remove,omitempty"`   // confirm_uninstall: what uninstalling removes
+}
+
+// protocolResponse is what the front-end writes to stdin to answer a prompt.
+// Cancel stops the install and rolls back anything already changed.
+type protocolResponse struct {
+	Type    string   `json:"type"` // "response"
+	ID      string   `json:"id"`
+	Choices []string `json:"choices,omitempty"`
+	Accept  bool     `json:"accept,omitempty"`
+	Cancel  bool     `json:"cancel,omitempty"`
+}
+
+// protocolError reports a problem that is not a task's. A blocked start ends
+// the run with one and no summary; an unusable response only draws one, and
+// its prompt stays open.
+type protocolError struct {
+	Type    string `json:"type"` // "error"
+	Message string `json:"message"`
+}
+
+// The run ends with a summaryReport (type "summary"), the same object --json
+// prints, unless it ended with an error message.
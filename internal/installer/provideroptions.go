@@ -0,0 +1,73 @@
+// internal/installer/provideroptions.go
+package installer
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// parseProviderOption splits a --provider-option key=value. The value is
+// JSON when it parses as JSON (so numbers, booleans, objects and quoted
+// strings keep their type) and a plain string otherwise; one that starts
+// like an object, array or string must be valid JSON.
+func parseProviderOption(arg string) (string, interface{}, error) {
+	key, raw, ok := strings.Cut(arg, "=")
+	key = strings.TrimSpace(key)
+	if !ok || key == "" {
+		return "", nil, fmt.Errorf("invalid --provider-option %q (expected key=value)", arg)
+	}
+	if key == "baseURL" {
+		return "", nil, fmt.Errorf("--provider-option cannot set baseURL; use --base-url or --port")
+	}
+
+	var value interface{}
+	if err := json.Unmarshal([]byte(raw), &value); err != nil {
+		if trimmed := strings.TrimSpace(raw); trimmed != "" && strings.ContainsRune(`{["`, rune(trimmed[0])) {
+			return "", nil, fmt.Errorf("invalid --provider-option %s: value is not valid JSON: %v", key, err)
+		}
+		value = raw
+	}
+	return key, value, nil
+}
+
+// applyProviderOptions merges --provider-option values into the cursor-acp
+// provider's options, which applyCursorAcpProvider has already created. It
+// returns a note for each option that replaced a different configured value.
+func applyProviderOptions(config map[string]interface{}, options map[string]interface{}) []string {
+	if len(options) == 0 {
+		return nil
+	}
+	providers, _ := config["provider"].(map[string]interface{})
+	provider, _ := providers["cursor-acp"].(map[string]interface{})
+	opts, _ := provider["options"].(map[string]interface{})
+	if opts == nil {
+		return nil
+	}
+
+	keys := make([]string, 0, len(options))
+	for key := range options {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var notes []string
+	for _, key := range keys {
+		if existing, ok := opts[key]; ok && !reflect.DeepEqual(existing, options[key]) {
+			notes = append(notes, fmt.Sprintf("options.%s was %s; --provider-option replaced it with %s", key, compactJSON(existing), compactJSON(options[key])))
+		}
+		opts[key] = options[key]
+	}
+	return notes
+}
+
+// compactJSON renders a config value on one line for a warning
+func compactJSON(value interface{}) string {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprintf("%v", value)
+	}
+	return string(data)
+}
@@ -0,0 +1,180 @@
+// internal/installer/proxy.go
+package installer
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"slices"
+	"strings"
+)
+
+// Child processes (bun, npm, cursor-agent, opencode) inherit the installer's
+// environment, so a proxy the shell exports already reaches them. --proxy is
+// for when it doesn't: it is set on each child's environment, under both
+// spellings the tools look for, and on the installer's own HTTP client. The
+// installer's environment is left alone, so a program embedding it keeps its
+// own.
+var (
+	httpsProxyVars = []string{"HTTPS_PROXY", "https_proxy"}
+	httpProxyVars  = []string{"HTTP_PROXY", "http_proxy"}
+	noProxyVars    = []string{"NO_PROXY", "no_proxy"}
+)
+
+// parseProxyURL checks a --proxy value
+func parseProxyURL(raw string) (string, error) {
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" {
+		return "", fmt.Errorf("invalid --proxy %q: want a URL such as http://proxy.example.com:8080", raw)
+	}
+	switch u.Scheme {
+	case "http", "https", "socks5", "socks5h":
+	default:
+		return "", fmt.Errorf("invalid --proxy %q: scheme must be http, https or socks5", raw)
+	}
+	return raw, nil
+}
+
+// proxyEnv is what --proxy adds to a child process's environment. Loopback
+// stays direct, so the checks against the local cursor-acp proxy never go
+// through it.
+func proxyEnv(proxy string) []string {
+	if proxy == "" {
+		return nil
+	}
+	var env []string
+	for _, name := range append(httpsProxyVars, httpProxyVars...) {
+		env = append(env, name+"="+proxy)
+	}
+	noProxy := strings.Join(noProxyHosts(), ",")
+	for _, name := range noProxyVars {
+		env = append(env, name+"="+noProxy)
+	}
+	return env
+}
+
+// noProxyHosts is NO_PROXY as set, plus loopback
+func noProxyHosts() []string {
+	var hosts []string
+	if current := firstEnv(noProxyVars...); current != "" {
+		hosts = strings.Split(current, ",")
+	}
+	for _, host := range []string{"localhost", "127.0.0.1", "::1"} {
+		if !slices.Contains(hosts, host) {
+			hosts = append(hosts, host)
+		}
+	}
+	return hosts
+}
+
+// setProxyEnv puts --proxy on cmd's environment, overriding what the shell
+// set; the later entries win
+func setProxyEnv(cmd *exec.Cmd, proxy string) {
+	if proxy == "" {
+		return
+	}
+	if cmd.Env == nil {
+		cmd.Env = os.Environ()
+	}
+	cmd.Env = append(cmd.Env, proxyEnv(proxy)...)
+}
+
+// proxyRunner sets --proxy on each command before handing it to next
+type proxyRunner struct {
+	next  CommandRunner
+	proxy string
+}
+
+func (r proxyRunner) Run(cmd *exec.Cmd) ([]byte, error) {
+	setProxyEnv(cmd, r.proxy)
+	return r.next.Run(cmd)
+}
+
+func (r proxyRunner) Output(cmd *exec.Cmd) ([]byte, error) {
+	setProxyEnv(cmd, r.proxy)
+	return r.next.Output(cmd)
+}
+
+func (r proxyRunner) CombinedOutput(cmd *exec.Cmd) ([]byte, error) {
+	setProxyEnv(cmd, r.proxy)
+	return r.next.CombinedOutput(cmd)
+}
+
+// httpClient is the client for the installer's own requests. With --proxy
+// they go through it, except to NO_PROXY hosts and loopback; without, the
+// environment decides as usual.
+func httpClient(proxy string) *http.Client {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = http.ProxyFromEnvironment
+	if u, err := url.Parse(proxy); proxy != "" && err == nil {
+		transport.Proxy = func(req *http.Request) (*url.URL, error) {
+			if bypassesProxy(req.URL.Hostname()) {
+				return nil, nil
+			}
+			return u, nil
+		}
+	}
+	return &http.Client{Transport: transport}
+}
+
+// bypassesProxy reports whether host is in noProxyHosts: "*", the host
+// itself, or a domain it is under (".example.com" or "example.com")
+func bypassesProxy(host string) bool {
+	host = strings.ToLower(host)
+	for _, entry := range noProxyHosts() {
+		entry = strings.ToLower(strings.TrimSpace(entry))
+		if h, _, err := net.SplitHostPort(entry); err == nil {
+			entry = h
+		}
+		switch {
+		case entry == "":
+		case entry == "*", entry == host:
+			return true
+		case strings.HasSuffix(host, "."+strings.TrimPrefix(entry, ".")):
+			return true
+		}
+	}
+	return false
+}
+
+// proxySummary says which proxy child processes will use, for the log
+// header. Passwords in proxy URLs are masked.
+func proxySummary(proxy string) string {
+	if proxy != "" {
+		return fmt.Sprintf("HTTPS_PROXY=%s HTTP_PROXY=%s NO_PROXY=%s (from --proxy)", redactProxyURL(proxy), redactProxyURL(proxy), strings.Join(noProxyHosts(), ","))
+	}
+	var parts []string
+	for _, vars := range [][]string{httpsProxyVars, httpProxyVars} {
+		if value := firstEnv(vars...); value != "" {
+			parts = append(parts, vars[0]+"="+redactProxyURL(value))
+		}
+	}
+	if len(parts) == 0 {
+		return "none"
+	}
+	if noProxy := firstEnv(noProxyVars...); noProxy != "" {
+		parts = append(parts, "NO_PROXY="+noProxy)
+	}
+	return strings.Join(parts, " ") + " (from the environment)"
+}
+
+func redactProxyURL(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return "(unparseable)"
+	}
+	return u.Redacted()
+}
+
+// firstEnv returns the first of names that is set and non-empty
+func firstEnv(names ...string) string {
+	for _, name := range names {
+		if value := os.Getenv(name); value != "" {
+			return value
+		}
+	}
+	return ""
+}
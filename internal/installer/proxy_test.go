@@ -0,0 +1,82 @@
+// internal/installer/proxy_test.go
+package installer
+
+import (
+	"net/http"
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+// envRunner keeps the environment of the last command it was given
+type envRunner struct {
+	env []string
+}
+
+func (r *envRunner) Run(cmd *exec.Cmd) ([]byte, error)            { r.env = cmd.Env; return nil, nil }
+func (r *envRunner) Output(cmd *exec.Cmd) ([]byte, error)         { r.env = cmd.Env; return nil, nil }
+func (r *envRunner) CombinedOutput(cmd *exec.Cmd) ([]byte, error) { r.env = cmd.Env; return nil, nil }
+
+// envValue is name's value in env as exec would pass it: the last one wins
+func envValue(env []string, name string) string {
+	for i := len(env) - 1; i >= 0; i-- {
+		if value, ok := strings.CutPrefix(env[i], name+"="); ok {
+			return value
+		}
+	}
+	return ""
+}
+
+func TestProxyRunner(t *testing.T) {
+	t.Setenv("HTTPS_PROXY", "http://shell.example.com:8080")
+	t.Setenv("NO_PROXY", "internal.example.com")
+	next := &envRunner{}
+	proxy := "http://proxy.example.com:3128"
+
+	if _, err := (proxyRunner{next, proxy}).Output(exec.Command("bun", "install")); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"HTTPS_PROXY", "https_proxy", "HTTP_PROXY", "http_proxy"} {
+		if got := envValue(next.env, name); got != proxy {
+			t.Errorf("child %s = %q, want %q", name, got, proxy)
+		}
+	}
+	if got, want := envValue(next.env, "NO_PROXY"), "internal.example.com,localhost,127.0.0.1,::1"; got != want {
+		t.Errorf("child NO_PROXY = %q, want %q", got, want)
+	}
+	if got := os.Getenv("HTTPS_PROXY"); got != "http://shell.example.com:8080" {
+		t.Errorf("the installer's own HTTPS_PROXY changed to %q", got)
+	}
+}
+
+func TestHTTPClientProxy(t *testing.T) {
+	t.Setenv("NO_PROXY", "internal.example.com")
+	transport := httpClient("http://proxy.example.com:3128").Transport.(*http.Transport)
+
+	tests := []struct {
+		url     string
+		proxied bool
+	}{
+		{"https://api2.cursor.sh/v1/models", true},
+		{"http://127.0.0.1:32124/v1/chat/completions", false},
+		{"http://localhost:32124/health", false},
+		{"http://[::1]:32124/health", false},
+		{"https://internal.example.com/models", false},
+		{"https://models.internal.example.com/models", false},
+		{"https://notinternal.example.com/models", true},
+	}
+	for _, tt := range tests {
+		req, err := http.NewRequest(http.MethodGet, tt.url, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		proxyURL, err := transport.Proxy(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := proxyURL != nil; got != tt.proxied {
+			t.Errorf("%s: proxied = %v, want %v", tt.url, got, tt.proxied)
+		}
+	}
+}
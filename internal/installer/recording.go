@@ -0,0 +1,356 @@
+// internal/installer/recording.go
+package installer
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+)
+
+// recordVersion is the format of a --record file
+const recordVersion = 1
+
+// recordContentLimit caps the file content a record carries; a larger write
+// (a copied plugin bundle) is recorded by hash alone
+const recordContentLimit = 1 << 20
+
+// recordHeader is the first line of a --record file
+type recordHeader struct {
+	Type       string    `json:"type"` // "record"
+	Version    int       `json:"version"`
+	Installer  string    `json:"installer"`
+	OS         string    `json:"os"`
+	RecordedAt time.Time `json:"recorded_at"`
+	Flags      []string  `json:"flags"`
+}
+
+// recordedOp is one operation in a --record file, a line each after the
+// header, in the order they happened. Paths are tokenized; see recordTokens.
+type recordedOp struct {
+	Seq  int    `json:"seq"`
+	Kind string `json:"kind"` // "command", "write" or "symlink"
+
+	Args  []string `json:"args,omitempty"`  // command
+	Dir   string   `json:"dir,omitempty"`   // command; empty is the working directory
+	Exit  int      `json:"exit,omitempty"`  // command
+	Error string   `json:"error,omitempty"` // command that could not be started
+
+	Path     string `json:"path,omitempty"`     // write, symlink
+	Target   string `json:"target,omitempty"`   // symlink
+	SHA256   string `json:"sha256,omitempty"`   // write: of the bytes written
+	Size     int    `json:"size,omitempty"`     // write
+	Mode     string `json:"mode,omitempty"`     // write
+	Content  string `json:"content,omitempty"`  // write, unless over recordContentLimit
+	Redacted bool   `json:"redacted,omitempty"` // write: secrets in Content were masked
+
+	Stopped bool `json:"stopped,omitempty"` // command: a server the installer stopped itself
+}
+
+func (op recordedOp) String() string {
+	switch op.Kind {
+	case "command":
+		s := "$ " + strings.Join(op.Args, " ")
+		if op.Dir != "" {
+			s += " (in " + op.Dir + ")"
+		}
+		return s
+	case "write":
+		return fmt.Sprintf("write %s (%d bytes, sha256 %.12s)", op.Path, op.Size, op.SHA256)
+	case "symlink":
+		return fmt.Sprintf("symlink %s -> %s", op.Path, op.Target)
+	}
+	return op.Kind
+}
+
+// recordToken stands in for a machine-specific path in a record, so it says
+// nothing about the user's home and replays against the replaying machine's
+type recordToken struct {
+	name    string
+	path    string
+	pattern *regexp.Regexp
+}
+
+// recordTokens tokenizes the plugin project before the home directory it
+// usually sits in
+func recordTokens(projectDir string) []recordToken {
+	var tokens []recordToken
+	add := func(name, path string) {
+		path = filepath.Clean(path)
+		if path == string(filepath.Separator) || path == "." {
+			return
+		}
+		// Only whole path elements, as redactHome does
+		pattern := regexp.MustCompile(regexp.QuoteMeta(path) + `([/\\\s"',;:]|$)`)
+		tokens = append(tokens, recordToken{name, path, pattern})
+	}
+	if projectDir != "" {
+		add("<PROJECT>", projectDir)
+	}
+	if home, err := actualHomeDir(); err == nil {
+		add("<HOME>", home)
+	}
+	return tokens
+}
+
+func tokenizePaths(s string, tokens []recordToken) string {
+	for _, token := range tokens {
+		s = token.pattern.ReplaceAllString(s, token.name+"$1")
+	}
+	return s
+}
+
+func expandTokens(s string, tokens []recordToken) string {
+	for _, token := range tokens {
+		s = strings.ReplaceAll(s, token.name, token.path)
+	}
+	return s
+}
+
+// secretKey matches the config keys whose values a record must not carry
+var secretKey = regexp.MustCompile(`(?i)(api[-_]?key|token|secret|password|authorization|credential)`)
+
+// redactedValue replaces each secret in a record
+const redactedValue = "<redacted>"
+
+// redactSecrets masks the values of secret-looking keys in JSON content. It
+// returns the content unchanged, and false, when there is nothing to mask.
+func redactSecrets(data []byte) ([]byte, bool) {
+	var value interface{}
+	if err := json.Unmarshal(data, &value); err != nil {
+		return data, false
+	}
+	var redact func(v interface{}) bool
+	redact = func(v interface{}) bool {
+		changed := false
+		switch v := v.(type) {
+		case map[string]interface{}:
+			for key, child := range v {
+				if _, isString := child.(string); isString && secretKey.MatchString(key) {
+					v[key] = redactedValue
+					changed = true
+				} else if redact(child) {
+					changed = true
+				}
+			}
+		case []interface{}:
+			for _, child := range v {
+				if redact(child) {
+					changed = true
+				}
+			}
+		}
+		return changed
+	}
+	if !redact(value) {
+		return data, false
+	}
+	redacted, err := marshalJSON(value, "")
+	if err != nil {
+		return data, false
+	}
+	return redacted, true
+}
+
+// restoreSecrets fills the values redactSecrets masked in recorded with those
+// under the same keys in current, the file as it is now. It returns false
+// when a masked value has no counterpart there.
+func restoreSecrets(recorded, current []byte) ([]byte, bool) {
+	var value, existing interface{}
+	if json.Unmarshal(recorded, &value) != nil || json.Unmarshal(current, &existing) != nil {
+		return nil, false
+	}
+	var restore func(v, from interface{}) bool
+	restore = func(v, from interface{}) bool {
+		switch v := v.(type) {
+		case map[string]interface{}:
+			fromMap, _ := from.(map[string]interface{})
+			for key, child := range v {
+				if child != redactedValue {
+					if !restore(child, fromMap[key]) {
+						return false
+					}
+					continue
+				}
+				secret, ok := fromMap[key].(string)
+				if !ok {
+					return false
+				}
+				v[key] = secret
+			}
+		case []interface{}:
+			fromList, _ := from.([]interface{})
+			for i, child := range v {
+				var fromChild interface{}
+				if i < len(fromList) {
+					fromChild = fromList[i]
+				}
+				if !restore(child, fromChild) {
+					return false
+				}
+			}
+		}
+		return true
+	}
+	if !restore(value, existing) {
+		return nil, false
+	}
+	restored, err := marshalJSON(value, "")
+	if err != nil {
+		return nil, false
+	}
+	return restored, true
+}
+
+// operationRecorder appends each operation to the --record file as it
+// happens, so the record survives a crash or an interrupted install
+type operationRecorder struct {
+	mu     sync.Mutex
+	file   *os.File
+	enc    *json.Encoder
+	seq    int
+	tokens []recordToken
+}
+
+// recorder is the --record in progress; nil records nothing
+var recorder *operationRecorder
+
+// startRecording opens path for --record and writes the header. Commands are
+// recorded by a recordingRunner in front of the run's runner, or where they
+// are started when they cannot go through it; stopRecording closes the file.
+func startRecording(path, projectDir string, flags []string) error {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	if err != nil {
+		return fmt.Errorf("cannot open --record: %w", err)
+	}
+	chownToActualUser(path)
+
+	r := &operationRecorder{file: file, enc: json.NewEncoder(file), tokens: recordTokens(projectDir)}
+	r.enc.SetEscapeHTML(false)
+	shown := redactFlags(flags)
+	for i, flag := range shown {
+		shown[i] = tokenizePaths(flag, r.tokens)
+	}
+	header := recordHeader{Type: "record", Version: recordVersion, Installer: installerVersion,
+		OS: runtime.GOOS + "/" + runtime.GOARCH, RecordedAt: time.Now().UTC(), Flags: shown}
+	if err := r.enc.Encode(header); err != nil {
+		file.Close()
+		return fmt.Errorf("cannot write --record: %w", err)
+	}
+
+	recorder = r
+	return nil
+}
+
+// stopRecording closes the --record file; nothing after it is recorded
+func stopRecording() {
+	if recorder == nil {
+		return
+	}
+	recorder.mu.Lock()
+	recorder.file.Close()
+	recorder.mu.Unlock()
+	recorder = nil
+}
+
+// add numbers op and appends it. A record is a support aid, so a failed
+// write is not worth failing the install over.
+func (r *operationRecorder) add(op recordedOp) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.seq++
+	op.Seq = r.seq
+	r.enc.Encode(op)
+}
+
+// command records a command that ran, or could not be started
+func (r *operationRecorder) command(cmd *exec.Cmd, err error) {
+	if r == nil {
+		return
+	}
+	r.add(r.commandOp(cmd, err))
+}
+
+// server records a command that kept running until the installer stopped it
+// (`opencode serve`), which a replay must not wait for
+func (r *operationRecorder) server(cmd *exec.Cmd) {
+	if r == nil {
+		return
+	}
+	op := r.commandOp(cmd, nil)
+	op.Stopped = true
+	r.add(op)
+}
+
+func (r *operationRecorder) commandOp(cmd *exec.Cmd, err error) recordedOp {
+	op := recordedOp{Kind: "command", Dir: tokenizePaths(cmd.Dir, r.tokens)}
+	for _, arg := range cmd.Args {
+		op.Args = append(op.Args, tokenizePaths(arg, r.tokens))
+	}
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		op.Exit = exitErr.ExitCode()
+	} else if err != nil {
+		op.Error = err.Error()
+	}
+	return op
+}
+
+// wrote records a file written with data
+func (r *operationRecorder) wrote(path string, data []byte, perm os.FileMode) {
+	if r == nil {
+		return
+	}
+	sum := sha256.Sum256(data)
+	op := recordedOp{Kind: "write", Path: tokenizePaths(path, r.tokens), SHA256: hex.EncodeToString(sum[:]),
+		Size: len(data), Mode: fmt.Sprintf("%04o", perm.Perm())}
+	if len(data) <= recordContentLimit {
+		content, redacted := redactSecrets(data)
+		op.Content = tokenizePaths(string(content), r.tokens)
+		op.Redacted = redacted
+	}
+	r.add(op)
+}
+
+// linked records a symlink created at path
+func (r *operationRecorder) linked(target, path string) {
+	if r == nil {
+		return
+	}
+	r.add(recordedOp{Kind: "symlink", Path: tokenizePaths(path, r.tokens), Target: tokenizePaths(target, r.tokens)})
+}
+
+// recordingRunner records each command it runs with next
+type recordingRunner struct {
+	next CommandRunner
+}
+
+func (r recordingRunner) Run(cmd *exec.Cmd) ([]byte, error) {
+	output, err := r.next.Run(cmd)
+	recorder.command(cmd, err)
+	return output, err
+}
+
+func (r recordingRunner) Output(cmd *exec.Cmd) ([]byte, error) {
+	output, err := r.next.Output(cmd)
+	recorder.command(cmd, err)
+	return output, err
+}
+
+func (r recordingRunner) CombinedOutput(cmd *exec.Cmd) ([]byte, error) {
+	output, err := r.next.CombinedOutput(cmd)
+	recorder.command(cmd, err)
+	return output, err
+}
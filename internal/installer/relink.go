@@ -0,0 +1,78 @@
+// internal/installer/relink.go
+package installer
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// relinkTasks points the plugin link at this checkout's build again, for
+// when the project moved and the link dangles. Nothing is built and the
+// config is left alone.
+func relinkTasks() []installTask {
+	return []installTask{
+		{name: "Check plugin link", description: "Checking the current plugin link", execute: task(inspectPluginLink), status: statusPending},
+		{name: "Relink plugin", description: "Linking the plugin to this checkout's build", execute: task(relinkPlugin), mutates: true, status: statusPending,
+			remediation: "Build the plugin with `bun run build` in the project (or pass --project-dir), then re-run relink"},
+		{name: "Update manifest", description: "Recording the new link target", execute: task(relinkManifest), mutates: true, status: statusPending},
+	}
+}
+
+// inspectPluginLink reports the plugin link as relink finds it, before
+// changing anything
+func inspectPluginLink(ic *installContext) error {
+	linkPath := uninstallPluginPath(ic, uninstallManifest(ic.configPath))
+	info, err := os.Lstat(linkPath)
+	if err != nil {
+		ic.state.note = "no plugin at " + linkPath
+		return nil
+	}
+	target, err := os.Readlink(linkPath)
+	switch {
+	case err != nil && info.Mode().IsRegular():
+		ic.state.note = linkPath + " is a copied plugin, not a link"
+	case err != nil:
+		return fmt.Errorf("cannot read %s: %w", linkPath, err)
+	case !filepath.IsAbs(target):
+		target = filepath.Join(filepath.Dir(linkPath), target)
+		fallthrough
+	default:
+		if _, statErr := os.Stat(target); statErr != nil {
+			ic.state.note = fmt.Sprintf("dangling: %s -> %s, which no longer exists", linkPath, target)
+		} else {
+			ic.state.note = fmt.Sprintf("%s -> %s", linkPath, target)
+		}
+	}
+	return nil
+}
+
+// relinkPlugin links the plugin where the last install put it to this
+// project's dist/plugin-entry.js, with createSymlink's backup and check
+// that the new link resolves
+func relinkPlugin(ic *installContext) error {
+	entry := filepath.Join(ic.projectDir, "dist", "plugin-entry.js")
+	if info, err := os.Stat(entry); err != nil || info.Size() == 0 {
+		return fmt.Errorf("no built plugin at %s", entry)
+	}
+
+	manifest := uninstallManifest(ic.configPath)
+	ic.pluginDir = filepath.Dir(uninstallPluginPath(ic, manifest))
+	ic.state.pluginEntry = entry
+	// Start from the recorded manifest so rewriting it keeps the rest
+	if manifest != nil {
+		recorded := *manifest
+		ic.state.manifest = &recorded
+	}
+	return createSymlink(ic)
+}
+
+// relinkManifest records the new link target in the manifest. An install
+// without one is left without one: a manifest listing only the plugin
+// would make uninstall leave the config entries behind.
+func relinkManifest(ic *installContext) error {
+	if uninstallManifest(ic.configPath) == nil {
+		return skipTask("no manifest to update")
+	}
+	return writeManifest(ic)
+}
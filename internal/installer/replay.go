@@ -0,0 +1,209 @@
+// internal/installer/replay.go
+package installer
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+// readRecord reads a --record file: its header and operations in order
+func readRecord(path string) (recordHeader, []recordedOp, error) {
+	var header recordHeader
+	file, err := os.Open(path)
+	if err != nil {
+		return header, nil, fmt.Errorf("cannot open --replay: %w", err)
+	}
+	defer file.Close()
+
+	dec := json.NewDecoder(file)
+	if err := dec.Decode(&header); err != nil || header.Type != "record" {
+		return header, nil, fmt.Errorf("%s is not a --record file", path)
+	}
+	if header.Version > recordVersion {
+		return header, nil, fmt.Errorf("%s is record version %d; this installer reads up to %d", path, header.Version, recordVersion)
+	}
+	var ops []recordedOp
+	for {
+		var op recordedOp
+		if err := dec.Decode(&op); err == io.EOF {
+			break
+		} else if err != nil {
+			// A record cut short by a crash still replays up to where it ends
+			fmt.Fprintf(os.Stderr, "Warning: %s is truncated after operation %d: %v\n", path, len(ops), err)
+			break
+		}
+		ops = append(ops, op)
+	}
+	return header, ops, nil
+}
+
+// runReplay is --replay: it performs a --record file's operations again, in
+// order, with <HOME> and <PROJECT> standing for this machine's paths, and
+// reports where the outcome differs from the recording. It asks first
+// unless --headless/--yes; --dry-run only lists them.
+func runReplay(opts cliOptions) int {
+	header, ops, err := readRecord(opts.replay)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 2
+	}
+	projectDir := opts.projectDir
+	if projectDir == "" {
+		projectDir = getProjectDir(inContainer())
+	}
+	tokens := recordTokens(projectDir)
+
+	fmt.Printf("Record of %d operations by installer %s on %s, %s\n", len(ops), header.Installer, header.OS, header.RecordedAt.Local().Format("2006-01-02 15:04"))
+	if len(header.Flags) > 0 {
+		fmt.Printf("  flags: %s\n", strings.Join(header.Flags, " "))
+	}
+	for _, token := range tokens {
+		fmt.Printf("  %s is %s\n", token.name, token.path)
+	}
+	if opts.dryRun {
+		for _, op := range ops {
+			fmt.Printf("  [%d] %s\n", op.Seq, op)
+		}
+		return 0
+	}
+	if !opts.headless {
+		fmt.Printf("Replay them here? Files are written and commands run for real. [y/N] ")
+		answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+		if !strings.EqualFold(strings.TrimSpace(answer), "y") {
+			fmt.Println("Replay cancelled")
+			return 1
+		}
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	diverged := 0
+	for _, op := range ops {
+		if ctx.Err() != nil {
+			fmt.Fprintln(os.Stderr, "Replay interrupted")
+			return 130
+		}
+		fmt.Printf("[%d/%d] %s\n", op.Seq, len(ops), op)
+		result, same := replayOp(ctx, op, tokens, opts.proxy)
+		if !same {
+			diverged++
+		}
+		fmt.Printf("      %s\n", result)
+	}
+
+	if diverged > 0 {
+		fmt.Printf("\n%d of %d operations differed from the recording\n", diverged, len(ops))
+		return 1
+	}
+	fmt.Printf("\nReplayed %d operations; all matched the recording\n", len(ops))
+	return 0
+}
+
+// replayOp performs one recorded operation and says how it went; same is
+// false when the outcome differs from the recording
+func replayOp(ctx context.Context, op recordedOp, tokens []recordToken, proxy string) (string, bool) {
+	switch op.Kind {
+	case "command":
+		if len(op.Args) == 0 {
+			return "skipped: no command recorded", false
+		}
+		if op.Stopped {
+			return "skipped: a server the installer stopped; it would run until interrupted", true
+		}
+		args := make([]string, len(op.Args))
+		for i, arg := range op.Args {
+			args[i] = expandTokens(arg, tokens)
+		}
+		cmd := exec.CommandContext(ctx, args[0], args[1:]...)
+		cmd.Dir = expandTokens(op.Dir, tokens)
+		// Interactive ones (`cursor-agent login`) need the terminal
+		cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+		setProxyEnv(cmd, proxy)
+		err := cmd.Run()
+
+		var exitErr *exec.ExitError
+		switch {
+		case errors.As(err, &exitErr):
+			return fmt.Sprintf("exit %d (recorded %s)", exitErr.ExitCode(), recordedOutcome(op)), op.Exit == exitErr.ExitCode()
+		case err != nil:
+			return fmt.Sprintf("could not start: %v (recorded %s)", err, recordedOutcome(op)), op.Error != ""
+		}
+		return fmt.Sprintf("exit 0 (recorded %s)", recordedOutcome(op)), op.Exit == 0 && op.Error == ""
+
+	case "write":
+		if op.Content == "" && op.Size > 0 {
+			return fmt.Sprintf("skipped: the %d-byte content was not recorded", op.Size), false
+		}
+		path := expandTokens(op.Path, tokens)
+		perm, err := strconv.ParseUint(op.Mode, 8, 32)
+		if err != nil {
+			perm = 0644
+		}
+		data := []byte(expandTokens(op.Content, tokens))
+		if op.Redacted {
+			// Writing the placeholders would replace the secrets the file has here
+			current, err := os.ReadFile(path)
+			if err != nil {
+				return "skipped: secrets were redacted from the recording and there is no file here to keep them from", false
+			}
+			restored, ok := restoreSecrets(data, current)
+			if !ok {
+				return "skipped: secrets were redacted from the recording and the file here does not have them all", false
+			}
+			data = restored
+		}
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return "failed: " + err.Error(), false
+		}
+		if err := writeFileAtomic(path, data, os.FileMode(perm)); err != nil {
+			return "failed: " + err.Error(), false
+		}
+		chownToActualUser(path)
+		sum := sha256.Sum256(data)
+		switch {
+		case hex.EncodeToString(sum[:]) == op.SHA256:
+			return "written; identical to the recorded file", true
+		case op.Redacted:
+			return "written with the secrets already in the file; the rest matches the recording", true
+		}
+		return "written; differs from the recorded file only in its paths", true
+
+	case "symlink":
+		path, target := expandTokens(op.Path, tokens), expandTokens(op.Target, tokens)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return "failed: " + err.Error(), false
+		}
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return "failed: " + err.Error(), false
+		}
+		if err := os.Symlink(target, path); err != nil {
+			return "failed: " + err.Error(), false
+		}
+		if _, err := os.Stat(path); err != nil {
+			return "linked, but the target does not exist here", false
+		}
+		return "linked", true
+	}
+	return fmt.Sprintf("skipped: unknown operation %q", op.Kind), false
+}
+
+// recordedOutcome describes how a recorded command ended
+func recordedOutcome(op recordedOp) string {
+	if op.Error != "" {
+		return "could not start"
+	}
+	return fmt.Sprintf("exit %d", op.Exit)
+}
@@ -0,0 +1,77 @@
+// internal/installer/replay_test.go
+package installer
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// recordedWrite is the op a --record of writing data to <HOME>/name holds
+func recordedWrite(t *testing.T, name string, data []byte) recordedOp {
+	t.Helper()
+	sum := sha256.Sum256(data)
+	content, redacted := redactSecrets(data)
+	return recordedOp{Kind: "write", Path: "<HOME>/" + name, SHA256: hex.EncodeToString(sum[:]), Size: len(data),
+		Mode: "0600", Content: string(content), Redacted: redacted}
+}
+
+func TestReplayKeepsSecrets(t *testing.T) {
+	home := t.TempDir()
+	tokens := []recordToken{{name: "<HOME>", path: home}}
+	path := filepath.Join(home, "opencode.json")
+	recorded := []byte(`{"plugin":["cursor-acp"],"provider":{"openai":{"options":{"apiKey":"sk-recorded"}}}}`)
+	op := recordedWrite(t, "opencode.json", recorded)
+	if !op.Redacted {
+		t.Fatal("the recorded apiKey was not redacted")
+	}
+
+	// Nothing here to take the secret from: the write is refused
+	if result, same := replayOp(context.Background(), op, tokens, ""); same {
+		t.Errorf("replay without a file reported a match: %s", result)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("replay wrote %s with the secrets redacted", path)
+	}
+
+	if err := os.WriteFile(path, []byte(`{"provider":{"openai":{"options":{"apiKey":"sk-local"}}}}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if result, same := replayOp(context.Background(), op, tokens, ""); !same {
+		t.Fatalf("replay failed: %s", result)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var config struct {
+		Plugin   []string `json:"plugin"`
+		Provider map[string]struct {
+			Options map[string]string `json:"options"`
+		} `json:"provider"`
+	}
+	if err := json.Unmarshal(data, &config); err != nil {
+		t.Fatal(err)
+	}
+	if key := config.Provider["openai"].Options["apiKey"]; key != "sk-local" {
+		t.Errorf("apiKey is %q after replay, want the local sk-local", key)
+	}
+	if len(config.Plugin) != 1 || config.Plugin[0] != "cursor-acp" {
+		t.Errorf("plugin is %v after replay, want the recorded [cursor-acp]", config.Plugin)
+	}
+
+	// A secret the local file lacks cannot be filled in
+	if err := os.WriteFile(path, []byte(`{"provider":{}}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if result, same := replayOp(context.Background(), op, tokens, ""); same {
+		t.Errorf("replay without the local secret reported a match: %s", result)
+	}
+	if data, _ := os.ReadFile(path); string(data) != `{"provider":{}}` {
+		t.Errorf("replay overwrote the file: %s", data)
+	}
+}
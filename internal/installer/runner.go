@@ -0,0 +1,38 @@
+// internal/installer/runner.go
+package installer
+
+import "os/exec"
+
+// CommandRunner runs the external commands the install flow depends on
+// (cursor-agent, bun, npm, node, git, opencode). Commands are built with
+// exec.Command as usual and handed to the run's runner (Options.Runner), so
+// a test can swap in one that answers from cmd.Args with canned output
+// instead of needing the real binaries. Only what needs the terminal
+// (`cursor-agent login`, a check's fix) or keeps running (`opencode serve`
+// for --deep-verify) is started directly. Whether a command exists is still
+// looked up on PATH.
+type CommandRunner interface {
+	// Run runs cmd, streaming its output to the TUI log pane, and returns
+	// its combined stdout and stderr
+	Run(cmd *exec.Cmd) ([]byte, error)
+	// Output runs cmd and returns its stdout; a failure's stderr is in the
+	// *exec.ExitError, as with exec.Cmd.Output
+	Output(cmd *exec.Cmd) ([]byte, error)
+	// CombinedOutput runs cmd and returns its stdout and stderr together
+	CombinedOutput(cmd *exec.Cmd) ([]byte, error)
+}
+
+// execRunner runs commands for real
+type execRunner struct{}
+
+func (execRunner) Run(cmd *exec.Cmd) ([]byte, error) {
+	return runStreaming(cmd)
+}
+
+func (execRunner) Output(cmd *exec.Cmd) ([]byte, error) {
+	return cmd.Output()
+}
+
+func (execRunner) CombinedOutput(cmd *exec.Cmd) ([]byte, error) {
+	return cmd.CombinedOutput()
+}
@@ -0,0 +1,117 @@
+// internal/installer/selftest.go
+package installer
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+)
+
+const selfTestTimeout = 30 * time.Second
+
+// runSelfTest brings up the plugin's proxy (reusing a running one), checks it
+// answers on the configured port and lists models through cursor-agent, then
+// tears it down. Unlike --deep-verify it sends no chat request, so it costs
+// nothing against the Cursor subscription.
+func runSelfTest(m model) int {
+	// Ctrl+C and SIGTERM cancel the context so a started proxy is still stopped
+	ctx, stop := signal.NotifyContext(m.ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	baseURL := m.baseURL
+	if baseURL == "" {
+		if config, _, err := readConfig(m.configPath); err == nil {
+			baseURL = configuredBaseURL(config)
+		}
+	}
+	if baseURL == "" {
+		baseURL = defaultBaseURL
+	}
+	u, err := url.Parse(baseURL)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: invalid baseURL %q: %v\n", baseURL, err)
+		return 1
+	}
+
+	fmt.Printf("cursor-acp selftest (%s):\n\n", baseURL)
+	var healthLatency, modelsLatency time.Duration
+	var models int
+	startup, err := withProxy(ctx, m.logFile, m.opencodeBin, m.proxy, baseURL, func() error {
+		start := time.Now()
+		if !isCursorProxy(u.Host) {
+			return fmt.Errorf("proxy on %s stopped answering /health", u.Host)
+		}
+		healthLatency = time.Since(start)
+
+		reqCtx, cancel := context.WithTimeout(ctx, selfTestTimeout)
+		defer cancel()
+		start = time.Now()
+		var err error
+		models, err = listProxyModels(reqCtx, m.proxy, baseURL)
+		modelsLatency = time.Since(start)
+		return err
+	})
+
+	if startup > 0 {
+		fmt.Printf("  [OK] proxy: started with opencode serve, listening on %s after %s\n", u.Host, formatDuration(startup))
+	} else if err == nil || healthLatency > 0 {
+		fmt.Printf("  [OK] proxy: already running on %s\n", u.Host)
+	}
+	if healthLatency > 0 {
+		fmt.Printf("  [OK] health: ok (%s)\n", healthLatency.Round(time.Millisecond))
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "  [FAIL] %v\n", err)
+		if m.logFile != nil {
+			fmt.Fprintf(os.Stderr, "\nSee logs: %s\n", m.logFile.Name())
+		}
+		return 1
+	}
+	fmt.Printf("  [OK] models: %d listed through cursor-agent (%s)\n", models, modelsLatency.Round(time.Millisecond))
+	fmt.Println()
+	fmt.Println("The bridge works.")
+	return 0
+}
+
+// listProxyModels asks the proxy for /models, which it answers by running
+// `cursor-agent models`, and returns how many it listed.
+func listProxyModels(ctx context.Context, proxy, baseURL string) (int, error) {
+	endpoint := strings.TrimSuffix(baseURL, "/") + "/models"
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return 0, err
+	}
+	resp, err := httpClient(proxy).Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return 0, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("%s returned %s: %s", endpoint, resp.Status, summarizeRawOutput(string(data)))
+	}
+	var list struct {
+		Data []struct {
+			ID string `json:"id"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(data, &list); err != nil {
+		return 0, fmt.Errorf("unexpected response from %s: %w", endpoint, err)
+	}
+	if len(list.Data) == 0 {
+		return 0, fmt.Errorf("%s listed no models - check `cursor-agent models` works", endpoint)
+	}
+	return len(list.Data), nil
+}
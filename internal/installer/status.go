@@ -0,0 +1,138 @@
+// internal/installer/status.go
+package installer
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// statusReport is the current install state printed by `status`
+type statusReport struct {
+	Installed        bool              `json:"installed"`
+	PluginVersion    string            `json:"plugin_version,omitempty"`
+	InstallerVersion string            `json:"installer_version,omitempty"` // from the manifest
+	RunningVersion   string            `json:"running_installer_version"`
+	InstalledAt      *time.Time        `json:"installed_at,omitempty"`
+	PluginPath       string            `json:"plugin_path"`
+	PluginTarget     string            `json:"plugin_target,omitempty"`
+	PluginCopied     bool              `json:"plugin_copied,omitempty"`
+	ConfigPath       string            `json:"config_path"`
+	BaseURL          string            `json:"base_url,omitempty"`
+	Port             string            `json:"port,omitempty"`
+	Models           int               `json:"models"`
+	Packages         map[string]string `json:"packages,omitempty"`
+	LoggedIn         bool              `json:"cursor_agent_logged_in"`
+}
+
+// runStatus reports what is installed without diagnosing or changing anything;
+// `doctor` is the one that judges the install.
+func runStatus(m model) int {
+	report := collectStatus(&m)
+
+	if m.jsonOutput {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetEscapeHTML(false)
+		enc.Encode(report)
+		return 0
+	}
+
+	installed := "no"
+	if report.Installed {
+		installed = "yes"
+		if report.PluginVersion != "" {
+			installed += " (" + report.PluginVersion + ")"
+		}
+	}
+	plugin := "not found"
+	switch {
+	case report.PluginCopied:
+		plugin = report.PluginPath + " (copied plugin)"
+	case report.PluginTarget != "":
+		plugin = report.PluginPath + " -> " + report.PluginTarget
+	}
+	proxy := "not configured"
+	if report.BaseURL != "" {
+		proxy = report.BaseURL
+		if report.Port != "" {
+			proxy += " (port " + report.Port + ")"
+		}
+	}
+	login := "not logged in"
+	if report.LoggedIn {
+		login = "logged in"
+	}
+
+	fmt.Println("cursor-acp status:")
+	fmt.Println()
+	fmt.Printf("  Installed:     %s\n", installed)
+	if report.InstalledAt != nil {
+		fmt.Printf("  Installed at:  %s (installer %s)\n", report.InstalledAt.Local().Format("2006-01-02 15:04"), report.InstallerVersion)
+	}
+	installer := report.RunningVersion
+	if report.InstallerVersion != "" && report.InstallerVersion != report.RunningVersion {
+		installer += fmt.Sprintf(" (config written by %s)", report.InstallerVersion)
+	}
+	fmt.Printf("  Installer:     %s\n", installer)
+	fmt.Printf("  Plugin:        %s\n", plugin)
+	fmt.Printf("  Config:        %s\n", report.ConfigPath)
+	fmt.Printf("  Proxy:         %s\n", proxy)
+	fmt.Printf("  Models:        %d\n", report.Models)
+	names := make([]string, 0, len(report.Packages))
+	for name := range report.Packages {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Printf("  Package:       %s %s\n", name, report.Packages[name])
+	}
+	fmt.Printf("  cursor-agent:  %s\n", login)
+	return 0
+}
+
+func collectStatus(m *model) statusReport {
+	report := statusReport{
+		PluginPath:     filepath.Join(m.pluginDir, "cursor-acp.js"),
+		ConfigPath:     m.configPath,
+		RunningVersion: installerVersion,
+		LoggedIn:       commandExists("cursor-agent") && cursorAgentLoggedIn(m.runner),
+	}
+
+	if manifest, err := readManifest(m.configPath); err == nil && manifest != nil {
+		report.PluginVersion = manifest.PluginVersion
+		report.InstallerVersion = manifest.InstallerVersion
+		report.InstalledAt = &manifest.InstalledAt
+		report.Packages = manifest.PackageVersions
+		if manifest.PluginPath != "" {
+			report.PluginPath = manifest.PluginPath
+		}
+	}
+
+	pluginPresent := false
+	if info, err := os.Lstat(report.PluginPath); err == nil {
+		pluginPresent = true
+		if target, err := os.Readlink(report.PluginPath); err == nil {
+			report.PluginTarget = target
+		} else {
+			report.PluginCopied = info.Mode().IsRegular()
+		}
+	}
+
+	providerPresent := false
+	if config, _, err := readConfig(m.configPath); err == nil {
+		providers, _ := config["provider"].(map[string]interface{})
+		_, providerPresent = providers["cursor-acp"]
+		report.BaseURL = configuredBaseURL(config)
+		report.Models = len(configuredModels(config))
+	}
+	if u, err := url.Parse(report.BaseURL); err == nil && report.BaseURL != "" {
+		report.Port = u.Port()
+	}
+
+	report.Installed = pluginPresent && providerPresent
+	return report
+}
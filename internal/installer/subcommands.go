@@ -0,0 +1,122 @@
+// internal/installer/subcommands.go
+package installer
+
+import (
+	"fmt"
+	"os"
+)
+
+// runSubcommand dispatches a non-interactive subcommand and returns the exit code.
+func runSubcommand(m model, command string, args []string) int {
+	switch command {
+	case "update":
+		m.step = stepInstalling
+		m.tasks = updateTasks()
+		m.currentTaskIndex = 0
+		return runTasksHeadless(m)
+	case "relink":
+		m.step = stepInstalling
+		m.tasks = relinkTasks()
+		m.currentTaskIndex = 0
+		return runTasksHeadless(m)
+	case "doctor":
+		return runDoctor(m)
+	case "status":
+		return runStatus(m)
+	case "restore":
+		return runRestore(m, args)
+	case "selftest":
+		return runSelfTest(m)
+	case "models":
+		return runModels(m, args)
+	default:
+		fmt.Fprintf(os.Stderr, "Error: unknown command %q (available: update, relink, doctor, status, restore, selftest, models)\n", command)
+		return 2
+	}
+}
+
+// runPrintConfig prints the opencode.json an install would write, with the
+// cursor-acp provider merged in, and changes nothing (--print-config). The
+// output is plain JSON for jq: comments in the file are not carried over.
+func runPrintConfig(m model) int {
+	// A dry run does not even refresh the model cache
+	m.dryRun = true
+	config, _, _, err := planConfig(&m.installContext)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+	data, err := marshalJSON(config, "")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: failed to serialize config: %v\n", err)
+		return 1
+	}
+	os.Stdout.Write(append(data, '\n'))
+	return 0
+}
+
+// updateTasks refreshes the cursor-acp model list without reinstalling.
+func updateTasks() []installTask {
+	return []installTask{
+		{name: "Refresh models", description: "Fetching models from cursor-agent", execute: task(refreshModels), status: statusPending},
+		{name: "Validate config", description: "Checking JSON syntax", execute: task(validateConfig), status: statusPending},
+	}
+}
+
+// refreshModels merges freshly fetched models into an existing cursor-acp
+// provider by --merge-strategy, leaving name, options and any user fields
+// untouched.
+func refreshModels(ic *installContext) error {
+	if !ic.dryRun {
+		unlock, err := lockConfig(ic.configPath)
+		if err != nil {
+			return err
+		}
+		defer unlock()
+	}
+
+	config, original, err := readConfig(ic.configPath)
+	if err != nil {
+		return err
+	}
+	if warning := installerVersionWarning(ic.configPath); warning != "" {
+		ic.state.warn("config", "%s", warning)
+	}
+
+	providers, _ := config["provider"].(map[string]interface{})
+	provider, ok := providers["cursor-acp"].(map[string]interface{})
+	if !ok {
+		return NewConfigError("cursor-acp provider not found - run a full install first", ic.configPath, nil)
+	}
+
+	models, source, err := loadModels(ic)
+	if err != nil {
+		return err
+	}
+	merged, notes := mergeModels(ic.mergeStrategy, configuredModels(config), models, nil)
+	provider["models"] = merged
+	ic.state.note = fmt.Sprintf("%d models from %s", len(models), source)
+	for _, note := range notes {
+		ic.state.warn("merge strategy", "%s", note)
+	}
+
+	output, err := patchJSONC(original, config, []string{"provider", "cursor-acp", "models"})
+	if err != nil {
+		return fmt.Errorf("failed to serialize config: %w", err)
+	}
+
+	if ic.dryRun {
+		return skipTask("%s", plannedWriteNote(ic.configPath, original, output))
+	}
+
+	if err := createBackup(ic, ic.configPath); err != nil {
+		return fmt.Errorf("failed to backup config: %w", err)
+	}
+
+	if err := writeFileAtomic(ic.configPath, output, 0644); err != nil {
+		return configWriteError(ic.configPath, err)
+	}
+	ic.chownToUser(false, ic.configPath)
+
+	return nil
+}
@@ -0,0 +1,2301 @@
+// internal/installer/tasks.go
+package installer
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+const npmPackage = "@rama_nigg/open-cursor"
+
+// defaultBaseURL is the plugin's built-in proxy address (see src/plugin.ts)
+const defaultBaseURL = "http://127.0.0.1:32124/v1"
+
+// modelRowRegex matches a line shaped like a model row, "<id> - <name>",
+// loosely enough that a malformed row is reported rather than silently
+// dropped. Banner and error lines have no separator after their first word.
+var modelRowRegex = regexp.MustCompile(`^(\S+)\s+[-–—:](?:\s+(.*?))?\s*$`)
+
+// modelMarkerRegex matches the "(current)" and "(default)" markers after a
+// model's display name
+var modelMarkerRegex = regexp.MustCompile(`(?:\s*\((?:current|default)\))+$`)
+
+// modelIDRegex is a usable model ID: words of letters, digits and . _ - : / @ +
+// separated by single spaces, starting with a letter or digit
+var modelIDRegex = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._:/@+-]*(?: [A-Za-z0-9._:/@+-]+)*$`)
+
+// modelEntryProblem says why id and name cannot go into the provider config,
+// or returns "" when they can. Whitespace in an ID is left to
+// normalizeModelIDs.
+func modelEntryProblem(id, name string) string {
+	switch {
+	case strings.TrimSpace(id) == "":
+		return "no model ID"
+	case !modelIDRegex.MatchString(strings.Join(strings.Fields(id), " ")):
+		return "invalid model ID"
+	case strings.TrimSpace(name) == "":
+		return "no display name"
+	}
+	return ""
+}
+
+// parseCursorModelsOutput parses the text listing of `cursor-agent models`.
+// It also returns the rows that looked like models but were not usable.
+func parseCursorModelsOutput(clean string) (map[string]interface{}, []string, error) {
+	models := make(map[string]interface{})
+	var rejected []string
+
+	lines := strings.Split(clean, "\n")
+	for _, line := range lines {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "Available") || strings.HasPrefix(line, "Tip:") {
+			continue
+		}
+		matches := modelRowRegex.FindStringSubmatch(line)
+		if matches == nil {
+			continue
+		}
+		id := matches[1]
+		name := strings.TrimSpace(modelMarkerRegex.ReplaceAllString(matches[2], ""))
+		if problem := modelEntryProblem(id, name); problem != "" {
+			rejected = append(rejected, fmt.Sprintf("%q (%s)", line, problem))
+			continue
+		}
+		models[id] = map[string]interface{}{"name": name}
+	}
+
+	if len(models) == 0 {
+		if len(rejected) > 0 {
+			return nil, rejected, fmt.Errorf("no valid model rows in %d lines (%d rejected)", len(lines), len(rejected))
+		}
+		return nil, nil, fmt.Errorf("regex matched 0 of %d lines", len(lines))
+	}
+
+	return models, rejected, nil
+}
+
+// parseCursorModelsJSON accepts the structured forms cursor-agent may emit:
+// an array of {id, name} objects, an object wrapping such an array under
+// "models", or a map of id to {name}. An entry without a name is named by
+// its ID; one without a usable ID is returned among the rejected.
+func parseCursorModelsJSON(data []byte) (map[string]interface{}, []string, error) {
+	var raw interface{}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, nil, err
+	}
+	if wrapper, ok := raw.(map[string]interface{}); ok {
+		if list, ok := wrapper["models"]; ok {
+			raw = list
+		}
+	}
+
+	models := make(map[string]interface{})
+	var rejected []string
+	add := func(id, name string, entry interface{}) {
+		if strings.TrimSpace(name) == "" {
+			name = id
+		}
+		if problem := modelEntryProblem(id, name); problem != "" {
+			rejected = append(rejected, fmt.Sprintf("%s (%s)", compactJSON(entry), problem))
+			return
+		}
+		models[id] = map[string]interface{}{"name": name}
+	}
+	switch v := raw.(type) {
+	case []interface{}:
+		for _, item := range v {
+			entry, ok := item.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			add(firstString(entry, "id", "model", "slug"), firstString(entry, "name", "displayName", "display_name"), entry)
+		}
+	case map[string]interface{}:
+		for id, item := range v {
+			var name string
+			if entry, ok := item.(map[string]interface{}); ok {
+				name = firstString(entry, "name", "displayName", "display_name")
+			} else if n, ok := item.(string); ok {
+				name = n
+			}
+			add(id, name, map[string]interface{}{id: item})
+		}
+	default:
+		return nil, nil, fmt.Errorf("unexpected JSON type %T", raw)
+	}
+
+	if len(models) == 0 {
+		return nil, rejected, fmt.Errorf("no models in JSON output")
+	}
+	sort.Strings(rejected)
+	return models, rejected, nil
+}
+
+// describeRejectedModels lists rows the parsers rejected for a warning,
+// the first few in full
+func describeRejectedModels(rejected []string) string {
+	const shown = 5
+	if len(rejected) <= shown {
+		return strings.Join(rejected, "; ")
+	}
+	return fmt.Sprintf("%s; and %d more", strings.Join(rejected[:shown], "; "), len(rejected)-shown)
+}
+
+func firstString(entry map[string]interface{}, keys ...string) string {
+	for _, key := range keys {
+		if s, ok := entry[key].(string); ok && s != "" {
+			return s
+		}
+	}
+	return ""
+}
+
+// normalizeModelIDs cleans up the IDs cursor-agent reported: surrounding
+// whitespace is trimmed, inner runs collapse to one space, and empty IDs are
+// dropped. IDs that then differ only in case are one model; the one already
+// in clean form is kept, preferring lower case (as cursor-agent's IDs are),
+// then sort order. It returns the cleaned map and a description of
+// everything dropped.
+func normalizeModelIDs(models map[string]interface{}) (map[string]interface{}, []string) {
+	rawIDs := make([]string, 0, len(models))
+	for id := range models {
+		rawIDs = append(rawIDs, id)
+	}
+	// Order the IDs so the one to keep comes first in each collision
+	rank := func(id string) int {
+		rank := 0
+		if id != strings.Join(strings.Fields(id), " ") {
+			rank += 2
+		}
+		if id != strings.ToLower(id) {
+			rank++
+		}
+		return rank
+	}
+	sort.Slice(rawIDs, func(i, j int) bool {
+		if ri, rj := rank(rawIDs[i]), rank(rawIDs[j]); ri != rj {
+			return ri < rj
+		}
+		return rawIDs[i] < rawIDs[j]
+	})
+
+	normalized := make(map[string]interface{}, len(models))
+	kept := make(map[string]string) // lower-cased ID -> raw ID kept for it
+	var dropped []string
+	for _, raw := range rawIDs {
+		id := strings.Join(strings.Fields(raw), " ")
+		if id == "" {
+			dropped = append(dropped, fmt.Sprintf("dropped a model with an empty ID (%q)", raw))
+			continue
+		}
+		if first, ok := kept[strings.ToLower(id)]; ok {
+			dropped = append(dropped, fmt.Sprintf("model ID %q duplicates %q; keeping %q", raw, first, strings.Join(strings.Fields(first), " ")))
+			continue
+		}
+		kept[strings.ToLower(id)] = raw
+		normalized[id] = models[raw]
+	}
+	return normalized, dropped
+}
+
+// isUnknownFlagOutput reports whether cursor-agent rejected a flag it doesn't support
+func isUnknownFlagOutput(output string) bool {
+	lower := strings.ToLower(output)
+	return strings.Contains(lower, "unknown flag") ||
+		strings.Contains(lower, "unknown option") ||
+		strings.Contains(lower, "unrecognized option") ||
+		strings.Contains(lower, "unexpected argument")
+}
+
+// fetchCursorModelsJSON asks cursor-agent for structured output. ok is false
+// when the flag is unsupported or the output could not be used, in which
+// case the caller falls back to the text parser. Each run is recorded in
+// capture, which may be nil. It also returns the entries it rejected.
+func fetchCursorModelsJSON(parent context.Context, runner CommandRunner, capture *modelsCapture) (map[string]interface{}, []string, bool) {
+	variants := [][]string{
+		{"models", "--json"},
+		{"models", "--output", "json"},
+	}
+
+	for _, args := range variants {
+		ctx, cancel := context.WithTimeout(parent, 30*time.Second)
+		output, err := runner.Output(exec.CommandContext(ctx, "cursor-agent", args...))
+		cancel()
+
+		if err != nil {
+			var exitErr *exec.ExitError
+			var stderr []byte
+			if errors.As(err, &exitErr) {
+				stderr = exitErr.Stderr
+			}
+			if isUnknownFlagOutput(string(output) + string(stderr)) {
+				capture.record(args, output, stderr, err, "flag not supported; trying the next variant")
+				continue
+			}
+			// Any other failure (not installed, network, timeout) is left to the text path
+			capture.record(args, output, stderr, err, "failed; falling back to the text output")
+			return nil, nil, false
+		}
+
+		models, rejected, err := parseCursorModelsJSON(output)
+		if err == nil {
+			capture.record(args, output, nil, nil, fmt.Sprintf("parsed %d models from JSON, rejected %d entries", len(models), len(rejected)))
+			return models, rejected, true
+		}
+		capture.record(args, output, nil, nil, "not usable as JSON: "+err.Error())
+	}
+	return nil, nil, false
+}
+
+// fetchCursorModels calls cursor-agent models and parses the output, preferring
+// structured JSON when this cursor-agent supports it. Each run is recorded in
+// capture, which may be nil. It also returns the rows that looked like models
+// but were rejected (see modelEntryProblem), for a warning.
+func fetchCursorModels(parent context.Context, runner CommandRunner, capture *modelsCapture) (map[string]interface{}, []string, error) {
+	if models, rejected, ok := fetchCursorModelsJSON(parent, runner, capture); ok {
+		return models, rejected, nil
+	}
+
+	variants := [][]string{
+		{"models"},
+		{"--list", "models"},
+	}
+
+	var lastErr error
+	var lastClean string
+
+	for _, args := range variants {
+		ctx, cancel := context.WithTimeout(parent, 30*time.Second)
+		output, err := runner.CombinedOutput(exec.CommandContext(ctx, "cursor-agent", args...))
+		cancel()
+
+		if err != nil {
+			capture.record(args, output, nil, err, "failed")
+			// Output an earlier variant could not parse says more than a
+			// fallback variant this cursor-agent does not have
+			if errorCategory(lastErr) != "PARSE" {
+				lastErr = NewExecError(
+					fmt.Sprintf("cursor-agent %s failed", strings.Join(args, " ")),
+					string(output),
+					err,
+				)
+			}
+			continue
+		}
+
+		clean := stripANSI(string(output))
+		lastClean = clean
+
+		models, rejected, parseErr := parseCursorModelsOutput(clean)
+		if parseErr == nil {
+			capture.record(args, output, nil, nil, fmt.Sprintf("parsed %d models, rejected %d rows", len(models), len(rejected)))
+			return models, rejected, nil
+		}
+		capture.record(args, output, nil, nil, "no models found: "+parseErr.Error())
+
+		lastErr = NewParseError(
+			"no models found in cursor-agent output",
+			clean,
+			parseErr,
+		)
+	}
+
+	if lastErr != nil {
+		return nil, nil, lastErr
+	}
+
+	return nil, nil, NewParseError("failed to fetch models from cursor-agent", lastClean, fmt.Errorf("all command variants failed"))
+}
+
+func isMissingModuleBuildError(err error) bool {
+	var installerErr *InstallerError
+	if errors.As(err, &installerErr) {
+		output := strings.ToLower(installerErr.RawOutput)
+		if strings.Contains(output, "could not resolve") ||
+			strings.Contains(output, "maybe you need to \"bun install\"") ||
+			strings.Contains(output, "cannot find package") {
+			return true
+		}
+	}
+
+	msg := strings.ToLower(err.Error())
+	return strings.Contains(msg, "could not resolve") ||
+		strings.Contains(msg, "cannot find package") ||
+		strings.Contains(msg, "bun install")
+}
+
+func (m model) startInstallation() (tea.Model, tea.Cmd) {
+	m.step = stepInstalling
+	m.tasks = buildInstallPlan(&m.installContext)
+	m.startDeadline()
+
+	m.currentTaskIndex = 0
+	m.tasks[0].status = statusRunning
+	resume := m.resumeAnimation()
+	return m, tea.Batch(resume, executeTaskCmd(0, &m))
+}
+
+// buildInstallPlan returns the ordered task list for a fresh install, shaped
+// by the flags in ic. The TUI, headless mode, --protocol and the API all run
+// this one list.
+func buildInstallPlan(ic *installContext) []installTask {
+	// --skip-build only picks up dist/; otherwise say which route the build
+	// will try first
+	build := installTask{name: "Install plugin", description: "npm (preferred) or bun build fallback", execute: task(buildPlugin), mutates: true, verify: pluginBuilt,
+		remediation: "Clear the build state with `rm -rf node_modules dist && bun install`, then re-run"}
+	switch {
+	case ic.skipBuild:
+		build = installTask{name: "Use existing build", description: "Checking dist/plugin-entry.js (--skip-build)", execute: task(usePrebuiltPlugin),
+			remediation: "Build the plugin first (`bun install && bun run build`) or drop --skip-build"}
+	case ic.ref != "":
+		build.description = fmt.Sprintf("Building %s from source in %s", ic.ref, ic.projectDir)
+	case ic.noNetwork:
+		build.description = "Building from source with the existing node_modules (--no-network)"
+	case !commandExists("npm"):
+		build.description = "Building from source in " + ic.projectDir
+	}
+
+	tasks := []installTask{
+		// Models and the config preview come first so declining the change
+		// leaves the system untouched
+		{name: "Check prerequisites", description: "Verifying a package manager and cursor-agent", execute: task(checkPrerequisites)},
+		{name: "Fetch models", description: "Querying cursor-agent for available models", execute: task(fetchModels), awaitsInput: stepSelectModels,
+			remediation: "Log in with `cursor-agent login`, check `cursor-agent models` works, then re-run (or pass --models-from-file)"},
+		{name: "Preview config", description: "Computing opencode.json changes", execute: task(previewConfig), awaitsInput: stepConfirmConfig},
+		{name: "Migrate legacy plugin", description: "Removing cursor-acp-auth if present", execute: task(migrateLegacyPlugin), mutates: true},
+		build,
+		{name: "Install AI SDK", description: "Adding @ai-sdk/openai-compatible to opencode", execute: task(installAiSdk), mutates: true, verify: aiSdkInstalled},
+		{name: "Install ACP SDK", description: "Adding @agentclientprotocol/sdk to opencode", execute: task(installAcpSdk), mutates: true, verify: acpSdkInstalled},
+		{name: "Create symlink", description: "Linking to OpenCode plugin directory", execute: task(createSymlink), mutates: true, verify: pluginLinked,
+			remediation: symlinkRemediation()},
+		{name: "Verify plugin", description: "Checking the plugin exports its entrypoint", execute: task(verifyPlugin)},
+		{name: "Update config", description: "Adding cursor-acp plugin to opencode.json", execute: task(updateConfig), mutates: true},
+		{name: "Validate config", description: "Checking JSON syntax", execute: task(validateConfig)},
+		{name: "Write manifest", description: "Recording installed files for uninstall", execute: task(writeManifest), mutates: true},
+		{name: "Verify plugin loads", description: "Checking if plugin appears in opencode", execute: task(verifyPostInstall), optional: true},
+	}
+	if ic.deepVerify {
+		tasks = append(tasks, installTask{name: "Deep verify", description: "Sending a test chat completion through the proxy", execute: task(deepVerify), optional: true})
+	}
+	for i := range tasks {
+		tasks[i].status = statusPending
+		if ic.dryRun && tasks[i].mutates {
+			tasks[i].description += " (dry run: planned only)"
+		}
+	}
+	return tasks
+}
+
+func executeTaskCmd(index int, m *model) tea.Cmd {
+	if index < len(m.tasks) {
+		m.tasks[index].startedAt = time.Now()
+	}
+	return func() tea.Msg {
+		if index >= len(m.tasks) {
+			return taskCompleteMsg{index: index, success: true}
+		}
+
+		task := &m.tasks[index]
+		if m.resumable(task) {
+			return taskCompleteMsg{index: index, success: true, skipped: true, resumed: true, note: "done by an earlier run (--resume)"}
+		}
+		err := task.execute(m)
+		elapsed := time.Since(task.startedAt)
+		note := m.state.note
+		m.state.note = ""
+
+		if err != nil {
+			var skip *taskSkipError
+			if errors.As(err, &skip) {
+				return taskCompleteMsg{index: index, success: true, skipped: true, note: skip.note, elapsed: elapsed}
+			}
+			return taskCompleteMsg{
+				index:       index,
+				success:     false,
+				err:         err.Error(),
+				remediation: remediationFor(err),
+				specificFix: hasSpecificRemediation(err),
+				category:    errorCategory(err),
+				rawOutput:   unparsedOutput(err),
+				elapsed:     elapsed,
+			}
+		}
+
+		return taskCompleteMsg{index: index, success: true, note: note, elapsed: elapsed}
+	}
+}
+
+func checkPrerequisites(ic *installContext) error {
+	pm, pmErr := detectPackageManager(ic.pkgManager)
+	if pmErr != nil && !ic.skipBuild {
+		return pmErr
+	}
+	if !commandExists("cursor-agent") {
+		return fmt.Errorf("cursor-agent not found - install with: %s", installCommand("cursor-agent"))
+	}
+	for _, tool := range requiredTools {
+		if tool.command == "bun" && (ic.skipBuild || pm.name != "bun") {
+			continue
+		}
+		if check := checkToolVersion(ic.runner, tool); !check.passed && !check.warning {
+			return fmt.Errorf("%s", check.message)
+		}
+	}
+	return nil
+}
+
+func buildPlugin(ic *installContext) error {
+	// Keep what the last install built while it is current; --force (or
+	// asking for a particular --ref) rebuilds
+	if entry := installedPluginEntry(ic); entry != "" && ic.ref == "" {
+		ic.state.pluginEntry = entry
+		return alreadyDone("%s is installed and up to date (--force rebuilds it)", entry)
+	}
+
+	pm, pmErr := detectPackageManager(ic.pkgManager)
+	if ic.dryRun {
+		if pmErr != nil {
+			return skipTask("would fail: %v", pmErr)
+		}
+		checkout := ""
+		if ic.ref != "" {
+			checkout = fmt.Sprintf("git checkout %s && ", ic.ref)
+		}
+		if ic.noNetwork {
+			return skipTask("would run: %s%s (in %s)", checkout, pm.describe(pm.run, "build"), ic.projectDir)
+		}
+		install := pm.install
+		if ic.force {
+			install = pm.repair
+		}
+		return skipTask("would run: %s%s && %s (in %s)", checkout, pm.describe(install), pm.describe(pm.run, "build"), ic.projectDir)
+	}
+
+	// Prefer npm-installed package when available; fall back to local build.
+	// --no-network builds from source with the dependencies already present,
+	// and --ref from that ref of the source.
+	if commandExists("npm") && !ic.noNetwork && ic.ref == "" {
+		installCmd := exec.CommandContext(ic.ctx, "npm", "install", "-g", fmt.Sprintf("%s@%s", npmPackage, ic.npmTag))
+		if err := runCommand(ic.runner, fmt.Sprintf("npm install -g %s@%s", npmPackage, ic.npmTag), installCmd, ic.logFile); err == nil {
+			rootOut, rootErr := ic.runner.Output(exec.CommandContext(ic.ctx, "npm", "root", "-g"))
+			if rootErr == nil {
+				root := strings.TrimSpace(string(rootOut))
+				entry := filepath.Join(root, "@rama_nigg", "open-cursor", "dist", "plugin-entry.js")
+				if info, err := os.Stat(entry); err == nil && info.Size() > 0 {
+					ic.state.pluginEntry = entry
+					return nil
+				}
+			}
+		}
+		// If npm install failed, continue to bun fallback; log only in debug mode.
+		if ic.debugMode && ic.logFile != nil {
+			ic.logFile.WriteString("npm install @rama_nigg/open-cursor failed or plugin entry not found; falling back to bun build\n")
+		}
+	}
+
+	if pmErr != nil {
+		return pmErr
+	}
+	// The build script itself runs `bun build`, whichever manager starts it
+	if !commandExists("bun") {
+		return fmt.Errorf("building from source needs bun (the build script runs `bun build`) - install it with: %s", installCommand("bun"))
+	}
+
+	// The checkout goes back as it was once the build is done
+	if ic.ref != "" {
+		restore, err := checkoutRef(ic)
+		if err != nil {
+			return err
+		}
+		defer restore()
+	}
+
+	// --force reinstalls dependencies past any cache before rebuilding
+	install := pm.install
+	if ic.force {
+		install = pm.repair
+	}
+
+	makeInstallCmd := func() *exec.Cmd {
+		return pm.command(ic.ctx, ic.projectDir, install)
+	}
+	if ic.noNetwork {
+		if ic.logFile != nil {
+			ic.logFile.WriteString(fmt.Sprintf("--no-network: skipping %s\n", pm.describe(install)))
+		}
+	} else if err := runCommandWithRetry(ic.ctx, ic.runner, pm.describe(install), makeInstallCmd, networkRetryAttempts, ic.logFile); err != nil {
+		return err
+	}
+
+	buildCmd := pm.command(ic.ctx, ic.projectDir, pm.run, "build")
+	if err := runCommand(ic.runner, pm.describe(pm.run, "build"), buildCmd, ic.logFile); err != nil {
+		if !isMissingModuleBuildError(err) {
+			return err
+		}
+		if ic.noNetwork {
+			return fmt.Errorf("--no-network: %s is missing packages the build needs - run %s there first: %w",
+				filepath.Join(ic.projectDir, "node_modules"), pm.describe(pm.install), err)
+		}
+
+		// Recovery path for stale/broken node_modules where bun install did not restore all packages.
+		makeRepairCmd := func() *exec.Cmd {
+			return pm.command(ic.ctx, ic.projectDir, pm.repair)
+		}
+		if repairErr := runCommandWithRetry(ic.ctx, ic.runner, pm.describe(pm.repair), makeRepairCmd, networkRetryAttempts, ic.logFile); repairErr != nil {
+			return repairErr
+		}
+
+		retryBuildCmd := pm.command(ic.ctx, ic.projectDir, pm.run, "build")
+		if retryErr := runCommand(ic.runner, pm.describe(pm.run, "build")+" (retry)", retryBuildCmd, ic.logFile); retryErr != nil {
+			return retryErr
+		}
+	}
+
+	// Verify dist/plugin-entry.js exists (plugin-only entrypoint)
+	distPath := filepath.Join(ic.projectDir, "dist", "plugin-entry.js")
+	info, err := os.Stat(distPath)
+	if err != nil || info.Size() == 0 {
+		return fmt.Errorf("dist/plugin-entry.js not found or empty after build")
+	}
+	// The build rewrites dist/, so an older entry means it did not run as
+	// expected (or src/ carries mtimes from the future)
+	if distStale(ic.projectDir, info.ModTime()) {
+		ic.state.warn("Install plugin", "%s is still older than the source in src/ after the build; check the build output in the log", distPath)
+	} else if ic.ref != "" {
+		ic.state.note = "built from " + ic.ref
+	} else {
+		ic.state.note = "built from current source"
+	}
+
+	ic.chownToUser(false, lockfilePaths(ic.projectDir)...)
+	ic.chownToUser(true, filepath.Join(ic.projectDir, "node_modules"), filepath.Join(ic.projectDir, "dist"))
+
+	ic.state.pluginEntry = distPath
+	return nil
+}
+
+// installedPluginEntry is the plugin entry the last install linked, if it is
+// still current (see pluginEntryCurrent) and is this project's build or the
+// npm package at the version npmTag points to; a build from another
+// checkout is not reused
+func installedPluginEntry(ic *installContext) string {
+	if ic.force {
+		return ""
+	}
+	previous, err := readManifest(ic.configPath)
+	if err != nil || previous == nil || !pluginEntryCurrent(ic.projectDir, previous.PluginTarget) {
+		return ""
+	}
+	target := previous.PluginTarget
+	if target == filepath.Join(ic.projectDir, "dist", "plugin-entry.js") {
+		return target
+	}
+	if !strings.Contains(filepath.ToSlash(target), "/"+npmPackage+"/dist/") {
+		return ""
+	}
+	// A newer release means reinstalling; one that cannot be looked up
+	// (offline, no npm) leaves the installed package be
+	installed := pluginVersion(target)
+	if latest := npmTagVersion(ic); latest != "" && latest != installed {
+		if ic.logFile != nil {
+			ic.logFile.WriteString(fmt.Sprintf("%s@%s is %s; %s is installed\n", npmPackage, ic.npmTag, latest, installed))
+		}
+		return ""
+	}
+	return target
+}
+
+// npmTagVersion asks the registry which version of the npm package npmTag
+// points to, or returns "" when it cannot
+func npmTagVersion(ic *installContext) string {
+	if ic.noNetwork || !commandExists("npm") {
+		return ""
+	}
+	ctx, cancel := context.WithTimeout(ic.ctx, 15*time.Second)
+	defer cancel()
+	output, err := ic.runner.Output(exec.CommandContext(ctx, "npm", "view", npmPackage+"@"+ic.npmTag, "version"))
+	if err != nil {
+		return ""
+	}
+	// A tag names one version; a range given as the tag lists several, the newest last
+	lines := strings.Fields(strings.TrimSpace(string(output)))
+	if len(lines) != 1 {
+		return ""
+	}
+	return strings.Trim(lines[0], `"'`)
+}
+
+// lockfilePaths lists the lockfiles any supported package manager may write in dir
+func lockfilePaths(dir string) []string {
+	return []string{
+		filepath.Join(dir, "bun.lock"),
+		filepath.Join(dir, "bun.lockb"),
+		filepath.Join(dir, "pnpm-lock.yaml"),
+		filepath.Join(dir, "package-lock.json"),
+	}
+}
+
+// usePrebuiltPlugin validates an existing dist/plugin-entry.js for --skip-build.
+func usePrebuiltPlugin(ic *installContext) error {
+	distPath := filepath.Join(ic.projectDir, "dist", "plugin-entry.js")
+	info, err := os.Stat(distPath)
+	if err != nil || info.Size() == 0 {
+		return fmt.Errorf("--skip-build: %s not found or empty - build it first or drop --skip-build", distPath)
+	}
+
+	ic.state.pluginEntry = distPath
+	// --skip-build wins; the pre-install check has already warned
+	if distStale(ic.projectDir, info.ModTime()) {
+		return skipTask("--skip-build: using prebuilt %s, which is older than src/", distPath)
+	}
+	return skipTask("--skip-build: using prebuilt %s", distPath)
+}
+
+// distStale reports whether the plugin source changed after a build made at built
+func distStale(projectDir string, built time.Time) bool {
+	return changedSince(built, filepath.Join(projectDir, "src"), filepath.Join(projectDir, "package.json"))
+}
+
+func installAiSdk(ic *installContext) error {
+	return addOpencodePackage(ic, "@ai-sdk/openai-compatible", ic.aiSdkVersion)
+}
+
+// installAcpSdk adds the ACP SDK at --acp-sdk-version. Unlike the AI SDK it is
+// not checked for before an offline install, so --no-network skips it when
+// it is missing instead of failing.
+func installAcpSdk(ic *installContext) error {
+	if ic.noNetwork && !opencodePackageInstalled("@agentclientprotocol/sdk") {
+		return skipTask("--no-network: @agentclientprotocol/sdk is not installed")
+	}
+	return addOpencodePackage(ic, "@agentclientprotocol/sdk", ic.acpSdkVersion)
+}
+
+// addOpencodePackage adds pkg at version (a version or range; empty is
+// latest) to OpenCode's config directory, unless a version in that range is
+// already there
+func addOpencodePackage(ic *installContext, pkg, version string) error {
+	configDir, err := getConfigDir()
+	if err != nil {
+		return NewConfigError("failed to determine config directory", "", err)
+	}
+
+	opencodeDir := filepath.Join(configDir, "opencode")
+
+	// Already there at a version in the range asked for (any, unless one was given)
+	if installed := installedPackageVersion(opencodeDir, pkg); installed != "" && !ic.force &&
+		(version == "" || versionSatisfies(installed, version)) {
+		ic.state.record().recordPackageVersion(pkg, installed)
+		return alreadyDone("%s %s is already installed", pkg, installed)
+	}
+
+	spec := packageSpec(pkg, version)
+	pm, pmErr := detectPackageManager(ic.pkgManager)
+	if ic.dryRun {
+		if pmErr != nil {
+			return skipTask("would fail: %v", pmErr)
+		}
+		return skipTask("would run: %s (in %s)", pm.describe(pm.add, spec), opencodeDir)
+	}
+
+	if ic.noNetwork {
+		// The pre-install check has made sure it is there
+		if !opencodePackageInstalled(pkg) {
+			return fmt.Errorf("--no-network: %s is not installed in %s", pkg, opencodeDir)
+		}
+		ic.state.record().recordPackageVersion(pkg, installedPackageVersion(opencodeDir, pkg))
+		return skipTask("--no-network: using existing %s", pkg)
+	}
+
+	if pmErr != nil {
+		// Only reachable with --skip-build; an existing install is good enough
+		if opencodePackageInstalled(pkg) {
+			return skipTask("no package manager; using existing %s", pkg)
+		}
+		return fmt.Errorf("a package manager is required to install %s: %w", pkg, pmErr)
+	}
+
+	created, err := mkdirAllForUser(opencodeDir)
+	if err != nil {
+		return NewConfigError("failed to create opencode directory", opencodeDir, err)
+	}
+	ic.chownToUser(false, created...)
+	ic.recordCreatedDirs(created)
+
+	// Only packages that were missing beforehand are ours to remove on uninstall
+	_, statErr := os.Stat(filepath.Join(opencodeDir, "node_modules", filepath.FromSlash(pkg)))
+	preexisting := statErr == nil
+	if err := ic.recordPackageAdd(pm, opencodeDir, pkg); err != nil {
+		return err
+	}
+
+	makeInstallCmd := func() *exec.Cmd {
+		return pm.command(ic.ctx, opencodeDir, pm.add, spec)
+	}
+	if err := runCommandWithRetry(ic.ctx, ic.runner, pm.describe(pm.add, spec), makeInstallCmd, networkRetryAttempts, ic.logFile); err != nil {
+		return err
+	}
+
+	ic.chownToUser(false, filepath.Join(opencodeDir, "package.json"))
+	ic.chownToUser(false, lockfilePaths(opencodeDir)...)
+	ic.chownToUser(true, filepath.Join(opencodeDir, "node_modules"))
+
+	manifest := ic.state.record()
+	manifest.recordPackageVersion(pkg, installedPackageVersion(opencodeDir, pkg))
+	if !preexisting {
+		manifest.PackagesDir = opencodeDir
+		manifest.Packages = appendUnique(manifest.Packages, pkg)
+	}
+
+	return nil
+}
+
+func createSymlink(ic *installContext) error {
+	// Create symlink in OpenCode's plugin directory
+	symlinkPath := filepath.Join(ic.pluginDir, "cursor-acp.js")
+
+	// Create symlink to plugin entry (npm path preferred, fallback to local dist)
+	entry := ic.state.pluginEntry
+	if entry == "" {
+		entry = filepath.Join(ic.projectDir, "dist", "plugin-entry.js")
+	}
+
+	manifest := ic.state.record()
+	manifest.PluginPath = symlinkPath
+	manifest.PluginTarget = entry
+	manifest.PluginVersion = pluginVersion(entry)
+
+	if !ic.force {
+		if target, err := os.Readlink(symlinkPath); err == nil && target == entry {
+			ic.state.pluginCopied = false
+			manifest.PluginCopied = false
+			return alreadyDone("%s already links to %s", symlinkPath, entry)
+		}
+		if info, err := os.Lstat(symlinkPath); err == nil && info.Mode().IsRegular() && sameFileContent(entry, symlinkPath) {
+			ic.state.pluginCopied = true
+			manifest.PluginCopied = true
+			return alreadyDone("%s is already a copy of %s", symlinkPath, entry)
+		}
+	}
+	if ic.dryRun {
+		return skipTask("would link %s -> %s", symlinkPath, entry)
+	}
+
+	// Ensure plugin directory exists (e.g. ~/.config/opencode/plugin)
+	created, err := mkdirAllForUser(ic.pluginDir)
+	if err != nil {
+		return fmt.Errorf("failed to create plugin directory: %w", err)
+	}
+	ic.chownToUser(false, created...)
+	ic.recordCreatedDirs(created)
+
+	// Don't take over a link another install manages without saying so
+	ours := []string{entry, filepath.Join(ic.projectDir, "dist", "plugin-entry.js")}
+	if previous, err := readManifest(ic.configPath); err == nil && previous != nil {
+		ours = append(ours, previous.PluginTarget)
+	}
+	if warning := foreignPluginLink(symlinkPath, entry, ours); warning != "" {
+		ic.state.warn("plugin link", "%s", warning)
+		if ic.logFile != nil {
+			ic.logFile.WriteString("Warning: " + warning + "\n")
+		}
+	}
+
+	// Remove existing symlink if present, keeping it (or its absence) for rollback
+	if err := backupLink(ic, symlinkPath); err != nil {
+		return fmt.Errorf("failed to backup existing plugin: %w", err)
+	}
+	os.Remove(symlinkPath)
+
+	// Symlinks need Developer Mode or admin rights on Windows, so copy there
+	// and anywhere else symlink creation is refused.
+	ic.state.pluginCopied = false
+	if runtime.GOOS == "windows" {
+		ic.state.pluginCopied = true
+	} else if err := os.Symlink(entry, symlinkPath); err != nil {
+		if ic.logFile != nil {
+			ic.logFile.WriteString(fmt.Sprintf("symlink %s -> %s failed (%v); copying plugin instead\n", symlinkPath, entry, err))
+		}
+		ic.state.pluginCopied = true
+	} else {
+		recorder.linked(entry, symlinkPath)
+	}
+
+	ic.chownToUser(false, ic.pluginDir)
+	manifest.PluginCopied = ic.state.pluginCopied
+
+	if ic.state.pluginCopied {
+		if err := copyFile(entry, symlinkPath); err != nil {
+			return fmt.Errorf("failed to copy plugin: %w", err)
+		}
+		ic.chownToUser(false, symlinkPath)
+		if !sameFileContent(entry, symlinkPath) {
+			return fmt.Errorf("copied plugin does not match %s", entry)
+		}
+		return nil
+	}
+
+	ic.chownToUser(false, symlinkPath)
+
+	// Verify symlink resolves
+	if _, err := os.Stat(symlinkPath); err != nil {
+		return fmt.Errorf("symlink verification failed: %w", err)
+	}
+
+	return nil
+}
+
+// foreignPluginLink describes an existing symlink at symlinkPath that points
+// neither at entry nor anywhere else in ours (targets an install of ours has
+// used), or that dangles. It returns "" when there is nothing to warn about.
+func foreignPluginLink(symlinkPath, entry string, ours []string) string {
+	target, err := os.Readlink(symlinkPath)
+	if err != nil {
+		// Missing, or a regular file from a copied install
+		return ""
+	}
+	resolved := target
+	if !filepath.IsAbs(resolved) {
+		resolved = filepath.Join(filepath.Dir(symlinkPath), resolved)
+	}
+	resolved = filepath.Clean(resolved)
+	for _, path := range ours {
+		if path != "" && filepath.Clean(path) == resolved {
+			return ""
+		}
+	}
+
+	if _, err := os.Stat(resolved); err != nil {
+		return fmt.Sprintf("Replaced dangling symlink %s -> %s", symlinkPath, target)
+	}
+	return fmt.Sprintf("%s pointed at %s, not %s; it was relinked here (another tool may manage it)", symlinkPath, target, entry)
+}
+
+func fetchModels(ic *installContext) error {
+	models, source, err := loadModels(ic)
+	if err != nil {
+		return err
+	}
+	ic.state.models = models
+	ic.state.selectedModels = nil
+	ic.state.note = fetchedModelsNote(models, source)
+	return nil
+}
+
+func fetchedModelsNote(models map[string]interface{}, source string) string {
+	return fmt.Sprintf("%d models from %s", len(models), source)
+}
+
+// planConfig computes the opencode.json updateConfig would write, returning
+// the merged config, the current file contents and the proposed contents.
+func planConfig(ic *installContext) (map[string]interface{}, []byte, []byte, error) {
+	config, original, err := readConfig(ic.configPath)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	if ic.force {
+		if err := resetCursorAcpProvider(config); err != nil {
+			return nil, nil, nil, err
+		}
+	}
+
+	// Models are normally fetched by the previous task; fetch here if not
+	models := ic.state.models
+	if models == nil {
+		models, _, err = loadModels(ic)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+	}
+	models, ic.state.mergeNotes = mergeModels(ic.mergeStrategy, configuredModels(config), models, ic.state.selectedModels)
+
+	// --port auto picks once; the preview and the write must agree
+	baseURL := ic.baseURL
+	if ic.autoPort {
+		if ic.state.autoPortURL == "" {
+			start := baseURL
+			if start == "" {
+				start = defaultBaseURL
+			}
+			if ic.state.autoPortURL, err = pickFreePort(start); err != nil {
+				return nil, nil, nil, err
+			}
+		}
+		baseURL = ic.state.autoPortURL
+	}
+
+	if err := applyCursorAcpProvider(config, models, baseURL); err != nil {
+		return nil, nil, nil, err
+	}
+	ic.state.optionNotes = applyProviderOptions(config, ic.providerOpts)
+
+	output, err := patchJSONC(original, config, []string{"provider", "cursor-acp"}, []string{"plugin"})
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to serialize config: %w", err)
+	}
+	return config, original, output, nil
+}
+
+// previewConfig computes the config change so the TUI can ask for confirmation
+func previewConfig(ic *installContext) error {
+	_, original, output, err := planConfig(ic)
+	if err != nil {
+		return err
+	}
+
+	ic.state.configDiff = diffLines(string(original), string(output))
+	if len(ic.state.configDiff) == 0 {
+		ic.state.note = "no changes to " + ic.configPath
+	} else {
+		ic.state.note = fmt.Sprintf("%d changed lines in %s", len(ic.state.configDiff), ic.configPath)
+	}
+	return nil
+}
+
+func updateConfig(ic *installContext) error {
+	// Hold the lock from read to write so a concurrent installer cannot
+	// interleave; the lock file needs the config directory to exist
+	if !ic.dryRun {
+		created, err := mkdirAllForUser(filepath.Dir(ic.configPath))
+		if err != nil {
+			return fmt.Errorf("failed to create config directory: %w", err)
+		}
+		ic.chownToUser(false, created...)
+		ic.recordCreatedDirs(created)
+		unlock, err := lockConfig(ic.configPath)
+		if err != nil {
+			return err
+		}
+		defer unlock()
+	}
+
+	config, original, output, err := planConfig(ic)
+	if err != nil {
+		return err
+	}
+	if warning := installerVersionWarning(ic.configPath); warning != "" {
+		ic.state.warn("config", "%s", warning)
+	}
+	for _, note := range ic.state.mergeNotes {
+		ic.state.warn("merge strategy", "%s", note)
+	}
+	for _, note := range ic.state.optionNotes {
+		ic.state.warn("provider option", "%s", note)
+	}
+	// Two providers on one port would both talk to whichever proxy bound it
+	baseURL := configuredBaseURL(config)
+	for _, name := range providersSharingPort(config, baseURL) {
+		ic.state.warn("Update config", "provider %q also uses %s, so it and cursor-acp would share one proxy - choose another port with --port <n>", name, baseURL)
+	}
+	if ic.autoPort {
+		ic.state.note = "auto-selected " + ic.state.autoPortURL
+	}
+
+	if bytes.Equal(original, output) {
+		ic.state.record().BaseURL = configuredBaseURL(config)
+		return alreadyDone("%s already has the cursor-acp provider and plugin", ic.configPath)
+	}
+	if ic.dryRun {
+		return skipTask("%s", plannedWriteNote(ic.configPath, original, output))
+	}
+
+	// Persist a timestamped backup for recovery outside the installer process
+	if err := createBackup(ic, ic.configPath); err != nil {
+		return fmt.Errorf("failed to backup config: %w", err)
+	}
+
+	if err := writeFileAtomic(ic.configPath, output, 0644); err != nil {
+		return configWriteError(ic.configPath, err)
+	}
+	ic.chownToUser(false, ic.configPath, ic.configPath+".lock")
+
+	before := map[string]interface{}{}
+	if len(original) > 0 {
+		parseJSONC(original, &before)
+	}
+	manifest := ic.state.record()
+	manifest.recordConfigKeys(before, config)
+	manifest.BaseURL = configuredBaseURL(config)
+
+	return nil
+}
+
+// readConfig loads and parses an opencode.json, returning an empty config when
+// the file does not exist yet. The raw bytes are returned alongside for diffing.
+func readConfig(path string) (map[string]interface{}, []byte, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			return nil, nil, fmt.Errorf("failed to read config: %w", err)
+		}
+		return make(map[string]interface{}), nil, nil
+	}
+
+	var config map[string]interface{}
+	if err := parseJSONC(data, &config); err != nil {
+		configErr := NewConfigError(fmt.Sprintf("%s is not valid JSON: %s", path, describeJSONError(data, err)), "", err)
+		configErr.Remediation = "Fix the syntax error, or run the installer interactively to back the file up and start from a fresh config"
+		return nil, nil, configErr
+	}
+	if config == nil {
+		config = make(map[string]interface{})
+	}
+	return config, data, nil
+}
+
+// minimalConfig replaces an opencode.json that cannot be parsed
+const minimalConfig = "{\n  \"$schema\": \"https://opencode.ai/config.json\"\n}\n"
+
+// configSyntaxProblem says why the config at path does not parse, or returns
+// "" if it parses or does not exist yet
+func configSyntaxProblem(path string) string {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	var config map[string]interface{}
+	if err := parseJSONC(data, &config); err != nil {
+		return describeJSONError(data, err)
+	}
+	return ""
+}
+
+// resetCorruptConfig backs up an unparseable config and replaces it with
+// minimalConfig, returning where the old content went. The swap is in the
+// undo log, so a failed install puts the broken file back.
+func resetCorruptConfig(ic *installContext) (string, error) {
+	if err := createBackup(ic, ic.configPath); err != nil {
+		return "", err
+	}
+	// The old content must be recoverable, so insist on a disk copy even
+	// where createBackup skips one (--no-rollback)
+	backupPath, ok := ic.diskBackups[ic.configPath]
+	if !ok {
+		data, err := os.ReadFile(ic.configPath)
+		if err != nil {
+			return "", fmt.Errorf("failed to read config: %w", err)
+		}
+		if backupPath, err = backupConfigToDisk(ic.backupDir, ic.configPath, data); err != nil {
+			return "", fmt.Errorf("failed to back up config: %w", err)
+		}
+		ic.chownToUser(false, filepath.Dir(backupPath), backupPath)
+	}
+
+	if err := writeFileAtomic(ic.configPath, []byte(minimalConfig), 0644); err != nil {
+		return "", configWriteError(ic.configPath, err)
+	}
+	ic.chownToUser(false, ic.configPath)
+	return backupPath, nil
+}
+
+// resetCursorAcpProvider drops everything from an existing cursor-acp provider
+// except name and options, so --force rebuilds the model list from scratch.
+func resetCursorAcpProvider(config map[string]interface{}) error {
+	providers, _ := config["provider"].(map[string]interface{})
+	if providers["cursor-acp"] == nil {
+		return nil
+	}
+	existing, ok := providers["cursor-acp"].(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("cursor-acp provider has invalid type (expected object, got %T)", providers["cursor-acp"])
+	}
+	fresh := make(map[string]interface{})
+	for _, key := range []string{"name", "options"} {
+		if v, ok := existing[key]; ok {
+			fresh[key] = v
+		}
+	}
+	providers["cursor-acp"] = fresh
+	return nil
+}
+
+// applyCursorAcpProvider merges the cursor-acp provider and plugin entry into config.
+// A non-empty baseURL replaces options.baseURL; otherwise an existing value is kept.
+func applyCursorAcpProvider(config map[string]interface{}, models map[string]interface{}, baseURL string) error {
+	// Ensure provider section exists
+	providers, ok := config["provider"].(map[string]interface{})
+	if !ok {
+		providers = make(map[string]interface{})
+		config["provider"] = providers
+	}
+
+	// Add cursor-acp provider (merge with existing to preserve user config)
+	existingCursorAcp, ok := providers["cursor-acp"].(map[string]interface{})
+	if !ok {
+		// If cursor-acp exists but isn't a map, user config is malformed
+		if providers["cursor-acp"] != nil {
+			return fmt.Errorf("cursor-acp provider has invalid type (expected object, got %T)", providers["cursor-acp"])
+		}
+		existingCursorAcp = make(map[string]interface{})
+	}
+
+	// Only set name if not already present (preserve user customization)
+	if _, hasName := existingCursorAcp["name"]; !hasName {
+		existingCursorAcp["name"] = "Cursor Agent (ACP stdin)"
+	}
+
+	// Always update models list (this is what installer needs to ensure)
+	existingCursorAcp["models"] = models
+
+	// Ensure options.baseURL is set so OpenCode never builds "undefined/chat/completions"
+	opts, _ := existingCursorAcp["options"].(map[string]interface{})
+	if opts == nil {
+		opts = make(map[string]interface{})
+		existingCursorAcp["options"] = opts
+	}
+	if baseURL != "" {
+		opts["baseURL"] = baseURL
+	} else if _, hasBaseURL := opts["baseURL"]; !hasBaseURL {
+		opts["baseURL"] = defaultBaseURL
+	}
+
+	// Preserve any other user fields (npm, etc.)
+	providers["cursor-acp"] = existingCursorAcp
+
+	// Ensure plugin array exists and add cursor-acp
+	plugins, ok := config["plugin"].([]interface{})
+	if !ok {
+		plugins = []interface{}{}
+	}
+
+	// Check if cursor-acp is already in the plugin list
+	hasPlugin := false
+	for _, p := range plugins {
+		if p == "cursor-acp" {
+			hasPlugin = true
+			break
+		}
+	}
+
+	if !hasPlugin {
+		plugins = append(plugins, "cursor-acp")
+		config["plugin"] = plugins
+	}
+
+	return nil
+}
+
+// resolveBaseURL validates the --base-url/--port flags and returns the
+// provider baseURL to write. A port alone is applied to defaultBaseURL.
+func resolveBaseURL(baseURL, port string) (string, error) {
+	if baseURL == "" {
+		baseURL = defaultBaseURL
+	}
+	u, err := url.Parse(baseURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Hostname() == "" {
+		return "", fmt.Errorf("invalid --base-url %q (expected e.g. %s)", baseURL, defaultBaseURL)
+	}
+	if port != "" {
+		n, err := strconv.Atoi(port)
+		if err != nil || n < 1 || n > 65535 {
+			return "", fmt.Errorf("invalid --port %q (expected 1-65535)", port)
+		}
+		u.Host = net.JoinHostPort(u.Hostname(), port)
+	}
+	return u.String(), nil
+}
+
+// configuredBaseURL returns options.baseURL of an existing cursor-acp provider, if any
+func configuredBaseURL(config map[string]interface{}) string {
+	providers, _ := config["provider"].(map[string]interface{})
+	provider, _ := providers["cursor-acp"].(map[string]interface{})
+	opts, _ := provider["options"].(map[string]interface{})
+	baseURL, _ := opts["baseURL"].(string)
+	return baseURL
+}
+
+// configuredModels returns the models map of an existing cursor-acp provider, if any
+func configuredModels(config map[string]interface{}) map[string]interface{} {
+	providers, _ := config["provider"].(map[string]interface{})
+	provider, _ := providers["cursor-acp"].(map[string]interface{})
+	models, _ := provider["models"].(map[string]interface{})
+	return models
+}
+
+// Ways --merge-strategy combines the configured models with the fetched ones
+const (
+	mergeReplace = "replace" // the fetched models only, as fetched
+	mergeUnion   = "union"   // keep configured models and their fields, add new ones
+	mergePrune   = "prune"   // union, less configured models no longer offered
+
+	defaultMergeStrategy = mergeUnion
+)
+
+var mergeStrategies = []string{mergeReplace, mergeUnion, mergePrune}
+
+// keepsConfiguredModels reports whether the models already in config survive
+// an install, so choosing none of the fetched ones still leaves some
+func (ic *installContext) keepsConfiguredModels(config map[string]interface{}) bool {
+	return ic.mergeStrategy != mergeReplace && len(configuredModels(config)) > 0
+}
+
+// selectionNote tells the model selection what happens to the models
+// already configured
+func (ic *installContext) selectionNote() string {
+	switch ic.mergeStrategy {
+	case mergeReplace:
+		return "Only the selected models are kept (--merge-strategy replace)."
+	case mergePrune:
+		return "Previously configured models are kept unless cursor-agent no longer offers them."
+	}
+	return "Previously configured models are kept."
+}
+
+// mergeModels combines the configured models with the fetched ones (those
+// selected, if selected is not nil) according to strategy. Under union and
+// prune an entry already configured is kept with any fields the user added,
+// gaining only fields it lacks. The notes say what the strategy kept or
+// dropped that a user might not expect, for the warnings.
+func mergeModels(strategy string, existing, fetched map[string]interface{}, selected map[string]bool) (map[string]interface{}, []string) {
+	offered := make(map[string]interface{}, len(fetched))
+	for id, entry := range fetched {
+		if selected == nil || selected[id] {
+			offered[id] = entry
+		}
+	}
+
+	var notes []string
+	if strategy == mergeReplace {
+		var dropped, customized []string
+		for id, entry := range existing {
+			if _, ok := offered[id]; !ok {
+				dropped = append(dropped, id)
+			} else if extraModelFields(entry, offered[id]) {
+				customized = append(customized, id)
+			}
+		}
+		sort.Strings(dropped)
+		sort.Strings(customized)
+		if len(dropped) > 0 {
+			notes = append(notes, fmt.Sprintf("removed %d configured models: %s (--merge-strategy union keeps them)", len(dropped), strings.Join(dropped, ", ")))
+		}
+		if len(customized) > 0 {
+			notes = append(notes, fmt.Sprintf("reset the custom fields of %s to what cursor-agent reports (--merge-strategy union keeps them)", strings.Join(customized, ", ")))
+		}
+		return offered, notes
+	}
+
+	merged := make(map[string]interface{}, len(existing)+len(offered))
+	var stale []string
+	for id, entry := range existing {
+		if _, ok := fetched[id]; !ok {
+			stale = append(stale, id)
+			if strategy == mergePrune {
+				continue
+			}
+		}
+		merged[id] = entry
+	}
+	for id, entry := range offered {
+		current, ok := merged[id].(map[string]interface{})
+		fresh, isObject := entry.(map[string]interface{})
+		if !ok || !isObject {
+			merged[id] = entry
+			continue
+		}
+		for key, value := range fresh {
+			if _, has := current[key]; !has {
+				current[key] = value
+			}
+		}
+	}
+
+	// An empty fetch (e.g. all filtered out) says nothing about what is offered
+	if len(stale) > 0 && len(fetched) > 0 {
+		sort.Strings(stale)
+		if strategy == mergePrune {
+			notes = append(notes, fmt.Sprintf("dropped %d models cursor-agent no longer offers: %s", len(stale), strings.Join(stale, ", ")))
+		} else {
+			notes = append(notes, fmt.Sprintf("kept %d configured models cursor-agent no longer offers: %s (--merge-strategy prune drops them)", len(stale), strings.Join(stale, ", ")))
+		}
+	}
+	return merged, notes
+}
+
+// extraModelFields reports whether a configured model entry has fields, or
+// values, that the fetched one does not
+func extraModelFields(configured, fetched interface{}) bool {
+	entry, ok := configured.(map[string]interface{})
+	if !ok {
+		return false
+	}
+	fresh, _ := fetched.(map[string]interface{})
+	for key, value := range entry {
+		if !reflect.DeepEqual(fresh[key], value) {
+			return true
+		}
+	}
+	return false
+}
+
+// plannedWriteNote describes a file write skipped in dry-run mode, including the diff.
+func plannedWriteNote(path string, before, after []byte) string {
+	diff := diffLines(string(before), string(after))
+	if len(diff) == 0 {
+		return fmt.Sprintf("would leave %s unchanged", path)
+	}
+	return fmt.Sprintf("would write %s\n%s", path, strings.Join(diff, "\n"))
+}
+
+func validateConfig(ic *installContext) error {
+	if ic.dryRun {
+		return skipTask("nothing written in dry-run mode")
+	}
+
+	if err := validateJSON(ic.configPath); err != nil {
+		return NewValidationError("config validation failed", ic.configPath, err)
+	}
+
+	data, err := os.ReadFile(ic.configPath)
+	if err != nil {
+		return NewConfigError("failed to read config for validation", ic.configPath, err)
+	}
+
+	var config map[string]interface{}
+	if err := parseJSONC(data, &config); err != nil {
+		return NewConfigError("failed to parse config JSON: "+describeJSONError(data, err), ic.configPath, err)
+	}
+
+	providers, ok := config["provider"].(map[string]interface{})
+	if !ok {
+		return NewValidationError("provider section missing from config", ic.configPath, nil)
+	}
+
+	if _, exists := providers["cursor-acp"]; !exists {
+		return NewValidationError("cursor-acp provider not found in config", ic.configPath, nil)
+	}
+
+	if err := checkCursorAcpProvider(providers["cursor-acp"]); err != nil {
+		return NewValidationError(err.Error(), ic.configPath, nil)
+	}
+
+	return nil
+}
+
+// checkCursorAcpProvider checks the shape OpenCode needs from the provider
+// block, naming the first offending field. A bad baseURL otherwise only shows
+// up at runtime as requests to "undefined/chat/completions".
+func checkCursorAcpProvider(value interface{}) error {
+	const prefix = "provider.cursor-acp"
+	provider, ok := value.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("%s must be an object, got %T", prefix, value)
+	}
+
+	if name, ok := provider["name"].(string); !ok || strings.TrimSpace(name) == "" {
+		return fmt.Errorf("%s.name must be a non-empty string", prefix)
+	}
+
+	opts, ok := provider["options"].(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("%s.options must be an object", prefix)
+	}
+	baseURL, ok := opts["baseURL"].(string)
+	if !ok {
+		return fmt.Errorf("%s.options.baseURL must be a string URL", prefix)
+	}
+	if u, err := url.Parse(baseURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("%s.options.baseURL must be an http(s) URL, got %q", prefix, baseURL)
+	}
+
+	models, ok := provider["models"].(map[string]interface{})
+	if !ok || len(models) == 0 {
+		return fmt.Errorf("%s.models must be a non-empty object", prefix)
+	}
+	ids := make([]string, 0, len(models))
+	for id := range models {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		if _, ok := models[id].(map[string]interface{}); !ok {
+			return fmt.Errorf("%s.models[%q] must be an object, got %T", prefix, id, models[id])
+		}
+	}
+	return nil
+}
+
+// pluginExportCheck imports the built plugin (ESM) and asserts its default
+// export is the cursor-acp plugin function OpenCode calls. The path is passed
+// via the environment so the same script works under node and bun.
+const pluginExportCheck = `
+import { pathToFileURL } from "node:url";
+const mod = await import(pathToFileURL(process.env.CURSOR_ACP_PLUGIN_PATH).href);
+if (mod.default === undefined) {
+  console.error("no default export; exports: " + (Object.keys(mod).join(", ") || "none"));
+  process.exit(3);
+}
+if (typeof mod.default !== "function") {
+  console.error("default export is a " + typeof mod.default + ", expected the plugin function");
+  process.exit(3);
+}
+`
+
+func verifyPlugin(ic *installContext) error {
+	if ic.dryRun {
+		return skipTask("nothing built in dry-run mode")
+	}
+
+	pluginPath := ic.state.pluginEntry
+	if pluginPath == "" {
+		pluginPath = filepath.Join(ic.projectDir, "dist", "plugin-entry.js")
+	}
+
+	// Load the plugin to catch syntax/import errors and a wrong export shape
+	var cmd *exec.Cmd
+	switch pluginLoader(ic.runner) {
+	case "node":
+		cmd = exec.CommandContext(ic.ctx, "node", "--input-type=module", "-e", pluginExportCheck)
+	case "bun":
+		cmd = exec.CommandContext(ic.ctx, "bun", "-e", pluginExportCheck)
+		ic.state.note = "loaded with bun"
+	default:
+		return skipTask("no usable node (>= %s) or bun found; cannot load %s", minNodeVersion, pluginPath)
+	}
+	cmd.Env = append(os.Environ(), "CURSOR_ACP_PLUGIN_PATH="+pluginPath)
+	if combined, err := ic.runner.CombinedOutput(cmd); err != nil {
+		verr := NewValidationError("plugin does not export the cursor-acp entrypoint", pluginPath, err)
+		// node ends crash output with its version banner; keep the real error last
+		output := strings.TrimSpace(string(combined))
+		if i := strings.LastIndex(output, "\nNode.js v"); i >= 0 {
+			output = strings.TrimSpace(output[:i])
+		}
+		verr.RawOutput = output
+		return verr
+	}
+
+	// Check cursor-agent responds
+	if _, err := ic.runner.Output(exec.CommandContext(ic.ctx, "cursor-agent", "--version")); err != nil {
+		return fmt.Errorf("cursor-agent not responding")
+	}
+
+	return nil
+}
+
+// OpenCode can take a moment to load a new plugin on its first run, so
+// verifyPostInstall retries with backoff; --verify-retries and
+// --verify-timeout override the defaults.
+const (
+	defaultVerifyRetries = 3
+	defaultVerifyTimeout = 30 * time.Second
+	verifyAttemptTimeout = 5 * time.Second
+)
+
+func verifyPostInstall(ic *installContext) error {
+	if ic.dryRun {
+		return skipTask("nothing installed in dry-run mode")
+	}
+	if ic.noNetwork {
+		return skipTask("not run with --no-network")
+	}
+
+	deadline := time.Now().Add(ic.verifyTimeout)
+	backoff := time.Second
+	for attempt := 1; ; attempt++ {
+		err := checkOpenCodeModels(ic, min(verifyAttemptTimeout, time.Until(deadline)))
+		if err == nil {
+			return nil
+		}
+		if attempt > ic.verifyRetries || time.Until(deadline) <= backoff {
+			if attempt > 1 {
+				return fmt.Errorf("after %d attempts: %w", attempt, err)
+			}
+			return err
+		}
+		if ic.logFile != nil {
+			ic.logFile.WriteString(fmt.Sprintf("Verify plugin loads: attempt %d failed, retrying in %s: %v\n", attempt, backoff, err))
+		}
+		select {
+		case <-ic.ctx.Done():
+			return err
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
+
+// checkOpenCodeModels runs `opencode models` once and looks for the provider
+func checkOpenCodeModels(ic *installContext, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(ic.ctx, timeout)
+	defer cancel()
+
+	output, err := ic.runner.CombinedOutput(exec.CommandContext(ctx, ic.opencodeBin, "models"))
+
+	cancel()
+
+	if err != nil {
+		return fmt.Errorf("failed to run opencode models: %w. Output: %s", err, string(output))
+	}
+
+	if strings.Contains(string(output), "cursor-acp") {
+		return nil
+	}
+
+	return fmt.Errorf("cursor-acp provider not found - plugin may not be installed correctly. OpenCode output: %s", string(output))
+}
+
+// Backup and restore functions
+func createBackup(ic *installContext, path string) error {
+	// Keep the earliest snapshot so rollback returns to the pre-install state
+	if _, exists := ic.backupFiles[path]; exists {
+		return nil
+	}
+
+	info, err := os.Lstat(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			// Anything created here later is ours to remove on rollback
+			ic.backupFiles[path] = backupEntry{kind: backupAbsent}
+			ic.recordUndo("remove "+path, func() error {
+				return restoreEntry(path, backupEntry{kind: backupAbsent})
+			})
+			return nil
+		}
+		return fmt.Errorf("failed to read file for backup: %w", err)
+	}
+
+	// writeFileAtomic writes through a symlink to a file (say, a config kept
+	// in a dotfiles repo), so back up that file's contents and leave the link
+	// alone. Any other link is recorded by target.
+	if info.Mode()&os.ModeSymlink != 0 {
+		if target, err := os.Stat(path); err != nil || !target.Mode().IsRegular() {
+			return backupSymlinkTarget(ic, path)
+		}
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read file for backup: %w", err)
+	}
+	ic.backupFiles[path] = backupEntry{kind: backupRegular, data: data}
+	ic.recordUndo("restore "+path, func() error {
+		entry := backupEntry{kind: backupRegular, data: data}
+		// Prefer the on-disk copy; it is what the user can see and restore manually
+		if backupPath, ok := ic.diskBackups[path]; ok {
+			if diskData, err := os.ReadFile(backupPath); err == nil {
+				entry.data = diskData
+			}
+		}
+		return restoreEntry(path, entry)
+	})
+
+	// Persist a timestamped copy for recovery outside the installer process.
+	// Failures are intentionally non-fatal to avoid blocking installation.
+	if !ic.noRollback && !ic.dryRun {
+		backupPath, err := backupConfigToDisk(ic.backupDir, path, data)
+		if err != nil {
+			if ic.logFile != nil {
+				ic.logFile.WriteString(fmt.Sprintf("on-disk backup of %s failed: %v\n", path, err))
+			}
+		} else {
+			ic.diskBackups[path] = backupPath
+			ic.chownToUser(false, filepath.Dir(backupPath), backupPath)
+		}
+	}
+	return nil
+}
+
+// backupLink is createBackup for a path that is about to be replaced rather
+// than written: a symlink there is recorded by target, not by contents, since
+// reading through it would turn the link into a copy on rollback.
+func backupLink(ic *installContext, path string) error {
+	if _, exists := ic.backupFiles[path]; exists {
+		return nil
+	}
+	if info, err := os.Lstat(path); err == nil && info.Mode()&os.ModeSymlink != 0 {
+		return backupSymlinkTarget(ic, path)
+	}
+	return createBackup(ic, path)
+}
+
+func backupSymlinkTarget(ic *installContext, path string) error {
+	target, err := os.Readlink(path)
+	if err != nil {
+		return fmt.Errorf("failed to read symlink for backup: %w", err)
+	}
+	entry := backupEntry{kind: backupSymlink, target: target}
+	ic.backupFiles[path] = entry
+	ic.recordUndo("restore symlink "+path, func() error {
+		return restoreEntry(path, entry)
+	})
+	return nil
+}
+
+func restoreBackup(ic *installContext, path string) error {
+	if entry, exists := ic.backupFiles[path]; exists {
+		if err := restoreEntry(path, entry); err != nil {
+			return fmt.Errorf("failed to restore backup: %w", err)
+		}
+		delete(ic.backupFiles, path)
+	}
+	return nil
+}
+
+// restoreEntry puts path back the way createBackup found it. Ownership is
+// restored on a best-effort basis; rollback itself matters more.
+func restoreEntry(path string, entry backupEntry) error {
+	if entry.kind == backupAbsent {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+	if entry.kind == backupSymlink {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		if err := os.Symlink(entry.target, path); err != nil {
+			return err
+		}
+		recorder.linked(entry.target, path)
+	} else if err := writeFileAtomic(path, entry.data, 0644); err != nil {
+		return err
+	}
+	chownToActualUser(path)
+	return nil
+}
+
+func cleanupBackups(ic *installContext) {
+	// On success we just drop the in-memory copies and the undo log; never
+	// delete the user's files.
+	ic.backupFiles = make(map[string]backupEntry)
+	ic.undo.steps = nil
+}
+
+// Uninstall functions
+
+// confirmUninstall shows what uninstalling would remove and waits for the
+// user to confirm before anything is touched
+func (m model) confirmUninstall() model {
+	m.uninstallPlan = planUninstall(&m.installContext)
+	m.step = stepConfirmUninstall
+	return m
+}
+
+func (m model) startUninstallation() (tea.Model, tea.Cmd) {
+	m.step = stepUninstalling
+	m.isUninstall = true
+	m.tasks = m.uninstallTasks()
+	m.startDeadline()
+
+	m.currentTaskIndex = 0
+	m.tasks[0].status = statusRunning
+	resume := m.resumeAnimation()
+	return m, tea.Batch(resume, executeTaskCmd(0, &m))
+}
+
+// uninstallTasks removes exactly what the install manifest lists, falling
+// back to the heuristic cleanup for installs that predate the manifest.
+func (m model) uninstallTasks() []installTask {
+	manifest, err := readManifest(m.configPath)
+	if err != nil && m.logFile != nil {
+		m.logFile.WriteString(fmt.Sprintf("Warning: %v; falling back to heuristic uninstall\n", err))
+	}
+
+	if manifest == nil {
+		return []installTask{
+			{name: "Back up files", description: "Backing up files before removing anything", execute: task(backupBeforeUninstall), status: statusPending},
+			{name: "Remove plugin symlink", description: "Removing cursor-acp.js from plugin directory", execute: task(removeSymlink), status: statusPending},
+			{name: "Remove ACP SDK", description: "Removing @agentclientprotocol/sdk from opencode", execute: task(removeAcpSdk), status: statusPending},
+			{name: "Remove provider config", description: "Removing cursor-acp from opencode.json", execute: task(removeProviderConfig), status: statusPending},
+			{name: "Remove old plugin", description: "Removing cursor-acp-auth if present", execute: task(removeOldPlugin), status: statusPending},
+			{name: "Validate config", description: "Checking JSON syntax", execute: task(validateConfigAfterUninstall), status: statusPending},
+		}
+	}
+
+	m.state.manifest = manifest
+	return []installTask{
+		{name: "Back up files", description: "Backing up files before removing anything", execute: task(backupBeforeUninstall), status: statusPending},
+		{name: "Remove plugin symlink", description: "Removing cursor-acp.js from plugin directory", execute: task(removeSymlink), status: statusPending},
+		{name: "Remove packages", description: "Removing packages added by the installer", execute: task(removeManifestPackages), status: statusPending},
+		{name: "Remove provider config", description: "Removing cursor-acp entries added by the installer", execute: task(removeProviderConfig), status: statusPending},
+		{name: "Validate config", description: "Checking JSON syntax", execute: task(validateConfigAfterUninstall), status: statusPending},
+		{name: "Remove manifest", description: "Removing the install manifest", execute: task(removeManifest), status: statusPending},
+	}
+}
+
+// uninstallPluginPath is the plugin link uninstalling removes: where the
+// manifest says it was created, unless --plugin-dir names another directory
+func uninstallPluginPath(ic *installContext, manifest *installManifest) string {
+	if manifest != nil && manifest.PluginPath != "" && !ic.pluginDirSet {
+		return manifest.PluginPath
+	}
+	return filepath.Join(ic.pluginDir, "cursor-acp.js")
+}
+
+func removeSymlink(ic *installContext) error {
+	// Remove symlink from plugin directory
+	symlinkPath := uninstallPluginPath(ic, ic.state.manifest)
+
+	// Check if symlink exists
+	info, err := os.Lstat(symlinkPath)
+	if os.IsNotExist(err) {
+		// Symlink doesn't exist, that's fine - already uninstalled
+		return nil
+	}
+
+	// A regular file here is a plugin copied in place of a symlink (Windows fallback)
+	copied := err == nil && info.Mode()&os.ModeSymlink == 0
+
+	if ic.dryRun {
+		if copied {
+			return skipTask("would remove copied plugin %s", symlinkPath)
+		}
+		return skipTask("would remove %s", symlinkPath)
+	}
+
+	// A copy is the plugin itself; keep it with the other backups
+	if copied {
+		if err := createBackup(ic, symlinkPath); err != nil {
+			return err
+		}
+	}
+
+	// Remove symlink
+	if err := os.Remove(symlinkPath); err != nil {
+		return fmt.Errorf("failed to remove symlink: %w", err)
+	}
+	ic.state.removed = append(ic.state.removed, symlinkPath)
+
+	// Also remove old node_modules symlink if it exists (migration from older installer)
+	configDir, _ := getConfigDir()
+	oldNodeModulesPath := filepath.Join(configDir, "opencode", "node_modules", "cursor-acp")
+	if _, err := os.Lstat(oldNodeModulesPath); err == nil {
+		os.Remove(oldNodeModulesPath)
+	}
+
+	return nil
+}
+
+// removeAcpSdk is the heuristic cleanup of the ACP SDK for installs without a
+// manifest. With nothing recording that the installer added it, a package
+// that package.json depends on may be the user's, so only a copy no
+// package.json lists is removed.
+func removeAcpSdk(ic *installContext) error {
+	configDir, _ := getConfigDir()
+	opencodeConfigDir := filepath.Join(configDir, "opencode")
+	pkgPath := filepath.Join(opencodeConfigDir, "node_modules", "@agentclientprotocol", "sdk")
+
+	if _, err := os.Stat(pkgPath); os.IsNotExist(err) {
+		return nil
+	}
+
+	if packageJSONDepends(opencodeConfigDir, "@agentclientprotocol/sdk") {
+		ic.state.warn("Remove ACP SDK", "kept @agentclientprotocol/sdk: %s lists it and no install manifest says the installer added it; remove it by hand if nothing else uses it",
+			filepath.Join(opencodeConfigDir, "package.json"))
+		return skipTask("kept @agentclientprotocol/sdk (listed in package.json)")
+	}
+
+	if ic.dryRun {
+		return skipTask("would remove @agentclientprotocol/sdk from %s", filepath.Join(opencodeConfigDir, "node_modules"))
+	}
+
+	if err := removeNodeModule(opencodeConfigDir, "@agentclientprotocol/sdk"); err != nil {
+		return fmt.Errorf("failed to remove @agentclientprotocol/sdk: %w", err)
+	}
+	ic.state.removed = append(ic.state.removed, fmt.Sprintf("@agentclientprotocol/sdk from %s", opencodeConfigDir))
+	return nil
+}
+
+// packageJSONDepends reports whether dir's package.json lists pkg in its dependencies
+func packageJSONDepends(dir, pkg string) bool {
+	data, err := os.ReadFile(filepath.Join(dir, "package.json"))
+	if err != nil {
+		return false
+	}
+	var packageJson map[string]interface{}
+	if err := parseJSONC(data, &packageJson); err != nil {
+		return false
+	}
+	dependencies, _ := packageJson["dependencies"].(map[string]interface{})
+	_, ok := dependencies[pkg]
+	return ok
+}
+
+// removeManifestPackages removes the node_modules packages the install added
+func removeManifestPackages(ic *installContext) error {
+	manifest := ic.state.manifest
+	if len(manifest.Packages) == 0 {
+		return skipTask("installer added no packages")
+	}
+
+	if ic.dryRun {
+		return skipTask("would remove %s from %s", strings.Join(manifest.Packages, ", "), manifest.PackagesDir)
+	}
+
+	for _, pkg := range manifest.Packages {
+		if _, err := os.Stat(filepath.Join(manifest.PackagesDir, "node_modules", pkg)); os.IsNotExist(err) {
+			continue
+		}
+		if err := removePackage(ic, manifest.PackagesDir, pkg); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// removePackage drops pkg from dir's package.json dependencies and node_modules
+func removePackage(ic *installContext, opencodeConfigDir, pkg string) error {
+	packageJsonPath := filepath.Join(opencodeConfigDir, "package.json")
+	if _, err := os.Stat(packageJsonPath); err == nil {
+		if err := createBackup(ic, packageJsonPath); err != nil {
+			return fmt.Errorf("failed to backup package.json: %w", err)
+		}
+
+		data, err := os.ReadFile(packageJsonPath)
+		if err != nil {
+			return fmt.Errorf("failed to read package.json: %w", err)
+		}
+
+		var packageJson map[string]interface{}
+		if err := parseJSONC(data, &packageJson); err != nil {
+			return fmt.Errorf("failed to parse package.json: %w", err)
+		}
+
+		if dependencies, ok := packageJson["dependencies"].(map[string]interface{}); ok {
+			if _, hasPkg := dependencies[pkg]; hasPkg {
+				delete(dependencies, pkg)
+
+				output, err := patchJSONC(data, packageJson, []string{"dependencies", pkg})
+				if err != nil {
+					return fmt.Errorf("failed to serialize package.json: %w", err)
+				}
+
+				if err := writeFileAtomic(packageJsonPath, output, 0644); err != nil {
+					return fmt.Errorf("failed to write package.json: %w", err)
+				}
+				ic.chownToUser(false, packageJsonPath)
+			}
+		}
+	}
+
+	if err := removeNodeModule(opencodeConfigDir, pkg); err != nil {
+		return fmt.Errorf("failed to remove %s: %w", pkg, err)
+	}
+	ic.state.removed = append(ic.state.removed, fmt.Sprintf("%s from %s", pkg, opencodeConfigDir))
+
+	return nil
+}
+
+func removeProviderConfig(ic *installContext) error {
+	if !ic.dryRun {
+		unlock, err := lockConfig(ic.configPath)
+		if err != nil {
+			return err
+		}
+		defer unlock()
+	}
+
+	// Read existing config
+	data, err := os.ReadFile(ic.configPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to read config: %w", err)
+	}
+
+	var config map[string]interface{}
+	if err := parseJSONC(data, &config); err != nil {
+		return fmt.Errorf("failed to parse config: %w", err)
+	}
+
+	paths := removeCursorAcpKeys(config, ic.state.manifest)
+	if len(paths) == 0 {
+		return skipTask("nothing to remove from %s", ic.configPath)
+	}
+
+	// Write back only the subtrees we own
+	output, err := patchJSONC(data, config, paths...)
+	if err != nil {
+		return fmt.Errorf("failed to serialize config: %w", err)
+	}
+
+	if ic.dryRun {
+		return skipTask("%s", plannedWriteNote(ic.configPath, data, output))
+	}
+
+	if err := createBackup(ic, ic.configPath); err != nil {
+		return fmt.Errorf("failed to backup config: %w", err)
+	}
+
+	if err := writeFileAtomic(ic.configPath, output, 0644); err != nil {
+		return configWriteError(ic.configPath, err)
+	}
+	ic.chownToUser(false, ic.configPath)
+
+	ic.state.removed = append(ic.state.removed, fmt.Sprintf("%s from %s", strings.Join(configKeyNames(paths), ", "), ic.configPath))
+
+	return nil
+}
+
+// removeCursorAcpKeys deletes the installer's entries from config and returns
+// the paths it changed. Without a manifest everything cursor-acp is removed;
+// with one, only what the installer inserted.
+func removeCursorAcpKeys(config map[string]interface{}, manifest *installManifest) [][]string {
+	var paths [][]string
+
+	// Remove cursor-acp provider
+	if providers, ok := config["provider"].(map[string]interface{}); ok {
+		if manifest == nil || manifest.hasKey(manifestProviderKey) {
+			if _, exists := providers["cursor-acp"]; exists {
+				delete(providers, "cursor-acp")
+				paths = append(paths, []string{"provider", "cursor-acp"})
+			}
+		} else if provider, ok := providers["cursor-acp"].(map[string]interface{}); ok {
+			models, _ := provider["models"].(map[string]interface{})
+			for _, id := range manifest.addedModels() {
+				if _, exists := models[id]; exists {
+					delete(models, id)
+					paths = append(paths, []string{"provider", "cursor-acp", "models", id})
+				}
+			}
+		}
+	}
+
+	// Remove cursor-acp from plugin array
+	if plugins, ok := config["plugin"].([]interface{}); ok && (manifest == nil || manifest.hasKey(manifestPluginKey)) {
+		newPlugins := []interface{}{}
+		for _, p := range plugins {
+			if p != "cursor-acp" {
+				newPlugins = append(newPlugins, p)
+			}
+		}
+		if len(newPlugins) != len(plugins) {
+			config["plugin"] = newPlugins
+			paths = append(paths, []string{"plugin"})
+		}
+	}
+	return paths
+}
+
+// configKeyNames names the config paths removeCursorAcpKeys changed, as the
+// manifest and the uninstall listing show them
+func configKeyNames(paths [][]string) []string {
+	keys := make([]string, len(paths))
+	for i, path := range paths {
+		keys[i] = strings.Join(path, ".")
+		if keys[i] == "plugin" {
+			keys[i] = manifestPluginKey
+		}
+	}
+	return keys
+}
+
+func validateConfigAfterUninstall(ic *installContext) error {
+	if ic.dryRun {
+		return skipTask("nothing written in dry-run mode")
+	}
+
+	if err := validateJSON(ic.configPath); err != nil {
+		return fmt.Errorf("config validation failed: %w", err)
+	}
+
+	// Verify cursor-acp provider is removed
+	data, _ := os.ReadFile(ic.configPath)
+	var config map[string]interface{}
+	parseJSONC(data, &config)
+
+	// A provider the user created before installing is kept; only our models go
+	if manifest := ic.state.manifest; manifest != nil && !manifest.hasKey(manifestProviderKey) {
+		models := configuredModels(config)
+		for _, id := range manifest.addedModels() {
+			if _, exists := models[id]; exists {
+				return fmt.Errorf("cursor-acp model %s still exists in config", id)
+			}
+		}
+		return nil
+	}
+
+	if providers, ok := config["provider"].(map[string]interface{}); ok {
+		if _, exists := providers["cursor-acp"]; exists {
+			return fmt.Errorf("cursor-acp provider still exists in config")
+		}
+	}
+
+	return nil
+}
+
+// legacyPluginCacheDir is where OpenCode cached the old cursor-acp-auth
+// package. It is under the home directory whatever the config directory is
+// (XDG_CONFIG_HOME, or Application Support on macOS).
+func legacyPluginCacheDir() string {
+	homeDir := os.Getenv("HOME")
+	if home, err := actualHomeDir(); err == nil {
+		homeDir = home
+	}
+	return filepath.Join(homeDir, ".cache", "opencode", "node_modules", "cursor-acp-auth")
+}
+
+// hasLegacyPluginEntry reports whether the config's plugin array still lists cursor-acp-auth
+func hasLegacyPluginEntry(configPath string) bool {
+	config, _, err := readConfig(configPath)
+	if err != nil {
+		return false
+	}
+	plugins, _ := config["plugin"].([]interface{})
+	for _, p := range plugins {
+		if pluginStr, ok := p.(string); ok && strings.HasPrefix(pluginStr, "cursor-acp-auth") {
+			return true
+		}
+	}
+	return false
+}
+
+// migrateLegacyPlugin removes a leftover cursor-acp-auth setup during install,
+// recording what changed as warnings for the completion screen.
+func migrateLegacyPlugin(ic *installContext) error {
+	inConfig := hasLegacyPluginEntry(ic.configPath)
+	cacheDir := legacyPluginCacheDir()
+	_, statErr := os.Stat(cacheDir)
+	inCache := statErr == nil
+
+	if !inConfig && !inCache {
+		return skipTask("no legacy cursor-acp-auth plugin found")
+	}
+
+	if err := removeOldPlugin(ic); err != nil {
+		return err
+	}
+
+	if inConfig {
+		ic.state.warn("migration", "removed legacy cursor-acp-auth from the plugin list in %s", ic.configPath)
+	}
+	if inCache {
+		ic.state.warn("migration", "deleted legacy cursor-acp-auth cache at %s", cacheDir)
+	}
+	return nil
+}
+
+func removeOldPlugin(ic *installContext) error {
+	configPath := ic.configPath
+
+	if ic.dryRun {
+		return skipTask("would remove cursor-acp-auth entries from %s and its cache", configPath)
+	}
+
+	if err := createBackup(ic, configPath); err != nil {
+		return fmt.Errorf("failed to backup config: %w", err)
+	}
+
+	data, err := os.ReadFile(configPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to read config: %w", err)
+	}
+
+	var config map[string]interface{}
+	if err := parseJSONC(data, &config); err != nil {
+		return fmt.Errorf("failed to parse config: %w", err)
+	}
+
+	if plugins, ok := config["plugin"].([]interface{}); ok {
+		newPlugins := []interface{}{}
+		for _, p := range plugins {
+			pluginStr, ok := p.(string)
+			if !ok {
+				continue
+			}
+			if !strings.HasPrefix(pluginStr, "cursor-acp-auth") {
+				newPlugins = append(newPlugins, pluginStr)
+			}
+		}
+		config["plugin"] = newPlugins
+	}
+
+	output, err := patchJSONC(data, config, []string{"plugin"})
+	if err != nil {
+		return fmt.Errorf("failed to serialize config: %w", err)
+	}
+
+	if err := writeFileAtomic(configPath, output, 0644); err != nil {
+		return configWriteError(configPath, err)
+	}
+	ic.chownToUser(false, configPath)
+
+	oldPluginPath := legacyPluginCacheDir()
+	if _, err := os.Stat(oldPluginPath); err == nil {
+		if err := os.RemoveAll(oldPluginPath); err != nil {
+			return fmt.Errorf("failed to remove old plugin from cache: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// handleTaskComplete records a task's outcome, moves the install along and
+// logs the task's timing, plus the total once the run is over.
+func (m model) handleTaskComplete(msg taskCompleteMsg) (tea.Model, tea.Cmd) {
+	next, cmd := m.applyTaskResult(msg)
+	final := next.(model)
+	final.logTiming(msg.index)
+	final.recordProgress()
+	return final, cmd
+}
+
+func (m model) logTiming(index int) {
+	if m.logFile == nil {
+		return
+	}
+	timestamp := time.Now().Format("15:04:05")
+	if index < len(m.tasks) {
+		task := m.tasks[index]
+		m.logFile.WriteString(fmt.Sprintf("[%s] Task %s: %s in %s\n", timestamp, task.name, task.status, formatDuration(task.duration)))
+	}
+	if m.step == stepComplete {
+		action := "install"
+		if m.isUninstall {
+			action = "uninstall"
+		}
+		m.logFile.WriteString(fmt.Sprintf("[%s] Total %s time: %s\n", timestamp, action, formatDuration(time.Since(m.startedAt))))
+	}
+}
+
+func (m model) applyTaskResult(msg taskCompleteMsg) (tea.Model, tea.Cmd) {
+	if msg.index >= len(m.tasks) {
+		m.step = stepComplete
+		return m, nil
+	}
+
+	task := &m.tasks[msg.index]
+	task.duration = msg.elapsed
+	m.warnings = append(m.warnings, m.state.warnings...)
+	m.state.warnings = nil
+
+	// Interrupted: whatever the task returned, stop here and undo its work
+	if m.ctx.Err() != nil {
+		task.status = statusFailed
+		task.errorDetails = &errorInfo{message: m.interruption(), logFile: logFileName(m.logFile), category: errorCategoryUnknown}
+		return m.finishCancelled()
+	}
+
+	if msg.skipped {
+		task.status = statusSkipped
+		task.note = msg.note
+		task.resumed = msg.resumed
+	} else if msg.success {
+		task.status = statusComplete
+		task.note = msg.note
+	} else {
+		task.status = statusFailed
+		task.errorDetails = &errorInfo{
+			message:     msg.err,
+			logFile:     logFileName(m.logFile),
+			remediation: msg.remediation,
+			category:    msg.category,
+			rawOutput:   msg.rawOutput,
+		}
+		// An error with its own fix beats the task's general advice
+		if task.remediation != "" && !msg.specificFix {
+			task.errorDetails.remediation = task.remediation
+		}
+
+		if !task.optional && m.pendingUndo() && !m.isUninstall && !m.noRollback {
+			if err := rollbackInstall(&m.installContext); err != nil {
+				m.errors = append(m.errors, msg.err+" (rollback failed: "+err.Error())
+			} else {
+				m.errors = append(m.errors, msg.err+" (rolled back)")
+			}
+		}
+
+		if !task.optional {
+			m.errors = append(m.errors, msg.err)
+			m.step = stepComplete
+			return m, nil
+		}
+	}
+
+	if task.status == statusComplete && !m.headless {
+		switch task.awaitsInput {
+		case stepSelectModels:
+			return m.startModelSelection()
+		case stepConfirmConfig:
+			// Nothing is written in dry-run, and an unchanged config needs no approval
+			if !m.dryRun && len(m.state.configDiff) > 0 {
+				m.diffScroll = 0
+				m.step = stepConfirmConfig
+				return m, nil
+			}
+		}
+	}
+
+	return m.advanceTask()
+}
+
+// finishCancelled rolls back the install's changes after an interrupt (unless
+// --no-rollback) and quits.
+func (m model) finishCancelled() (tea.Model, tea.Cmd) {
+	m.cancelled = true
+	message := m.interruption()
+	if m.pendingUndo() && !m.isUninstall && !m.noRollback {
+		if err := rollbackInstall(&m.installContext); err != nil {
+			message += " (rollback failed: " + err.Error() + ")"
+		} else {
+			message += " (rolled back)"
+		}
+	}
+	m.errors = append(m.errors, message)
+	m.step = stepComplete
+	return m, tea.Quit
+}
+
+// startDeadline starts the --timeout clock. It runs from the first task, not
+// from launch, so time spent reading the welcome screen doesn't count; every
+// task's own timeout derives from m.ctx and so never outlasts it.
+func (m *model) startDeadline() {
+	if m.timeout > 0 {
+		m.ctx, m.cancel = context.WithTimeout(m.ctx, m.timeout)
+	}
+}
+
+// timedOut reports whether --timeout, rather than an interrupt, stopped the run
+func (m model) timedOut() bool {
+	return errors.Is(m.ctx.Err(), context.DeadlineExceeded)
+}
+
+// interruption describes why the run stopped early
+func (m model) interruption() string {
+	if m.timedOut() {
+		return fmt.Sprintf("timed out after %s (--timeout)", m.timeout)
+	}
+	return "cancelled"
+}
+
+// cancelledExitCode is 124 after --timeout, as timeout(1) uses, else 130 (SIGINT)
+func (m model) cancelledExitCode() int {
+	if m.timedOut() {
+		return 124
+	}
+	return 130
+}
+
+// advanceTask starts the task after currentTaskIndex, or completes the run
+func (m model) advanceTask() (tea.Model, tea.Cmd) {
+	m.currentTaskIndex++
+	if m.currentTaskIndex >= len(m.tasks) {
+		cleanupBackups(&m.installContext)
+		m.step = stepComplete
+		return m, nil
+	}
+
+	m.tasks[m.currentTaskIndex].status = statusRunning
+	return m, executeTaskCmd(m.currentTaskIndex, &m)
+}
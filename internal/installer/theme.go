@@ -1,5 +1,5 @@
-// cmd/installer/theme.go
-package main
+// internal/installer/theme.go
+package installer
 
 import "github.com/charmbracelet/lipgloss"
 
@@ -26,6 +26,21 @@ var (
 	headerStyle = lipgloss.NewStyle().Foreground(Primary).Bold(true)
 )
 
+// categoryStyle is how errors of one InstallerError category are marked
+type categoryStyle struct {
+	icon  string
+	color lipgloss.Color
+}
+
+// errorCategoryStyles tells error categories apart by icon and shade
+var errorCategoryStyles = map[string]categoryStyle{
+	"CONFIG":             {icon: "⚙", color: Primary},
+	"VALIDATE":           {icon: "✗", color: Secondary},
+	"EXEC":               {icon: "$", color: WarningColor},
+	"PARSE":              {icon: "{}", color: WarningColor},
+	errorCategoryUnknown: {icon: "?", color: FgMuted},
+}
+
 // ASCII header from /home/nomadx/bit/opencursor.txt
 const asciiHeader = ` ▄▄▄  ▄▄▄▄  ▄▄▄▄▄ ▄▄  ▄▄      ▄▄▄  ▄▄ ▄▄ ▄▄▄▄   ▄▄▄▄   ▄▄▄   ▄▄▄▄
 ██ ██ ██ ██ ██▄▄  ███▄██ ▄▄▄ ██ ▀▀ ██ ██ ██ ██ ██▄▄▄  ██ ██  ██ ██
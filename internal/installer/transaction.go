@@ -0,0 +1,124 @@
+// internal/installer/transaction.go
+
+package installer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// packageUndoTimeout bounds reinstalling a package's previous version on rollback
+const packageUndoTimeout = 2 * time.Minute
+
+// undoStep reverses one change the install made
+type undoStep struct {
+	description string
+	undo        func() error
+}
+
+// undoLog lists every change an install has made, oldest first, so a failure
+// part-way through can put things back in reverse order. It is shared by
+// pointer for the same reason installState is.
+type undoLog struct {
+	steps []undoStep
+}
+
+// recordUndo adds the inverse of a change about to be made
+func (ic *installContext) recordUndo(description string, undo func() error) {
+	ic.undo.steps = append(ic.undo.steps, undoStep{description: description, undo: undo})
+}
+
+// pendingUndo reports whether rolling back would change anything
+func (ic *installContext) pendingUndo() bool {
+	return len(ic.undo.steps) > 0
+}
+
+// recordCreatedDirs undoes mkdirAllForUser. created is deepest-first, so it is
+// recorded in reverse to remove the deepest directory first. A directory that
+// has gained other files by then is left alone.
+func (ic *installContext) recordCreatedDirs(created []string) {
+	for i := len(created) - 1; i >= 0; i-- {
+		dir := created[i]
+		ic.recordUndo("remove directory "+dir, func() error {
+			entries, err := os.ReadDir(dir)
+			if err != nil || len(entries) > 0 {
+				return nil
+			}
+			return os.Remove(dir)
+		})
+	}
+}
+
+// recordPackageAdd records how to undo adding pkg to dir with pm: remove it if
+// it was not installed before, or reinstall the previous version if it was.
+// package.json and the lockfiles are backed up too, so they go back as well.
+func (ic *installContext) recordPackageAdd(pm packageManager, dir, pkg string) error {
+	nodeModules := filepath.Join(dir, "node_modules")
+	if _, err := os.Stat(nodeModules); os.IsNotExist(err) {
+		ic.recordCreatedDirs([]string{nodeModules})
+	}
+	for _, path := range append([]string{filepath.Join(dir, "package.json")}, lockfilePaths(dir)...) {
+		if err := createBackup(ic, path); err != nil {
+			return fmt.Errorf("failed to backup %s: %w", filepath.Base(path), err)
+		}
+	}
+
+	previous := installedPackageVersion(dir, pkg)
+	if previous == "" {
+		ic.recordUndo(fmt.Sprintf("remove %s from %s", pkg, dir), func() error {
+			return removeNodeModule(dir, pkg)
+		})
+		return nil
+	}
+
+	ic.recordUndo(fmt.Sprintf("reinstall %s@%s in %s", pkg, previous, dir), func() error {
+		if installedPackageVersion(dir, pkg) == previous {
+			return nil
+		}
+		// The install's own context may already be cancelled by an interrupt
+		ctx, cancel := context.WithTimeout(context.Background(), packageUndoTimeout)
+		defer cancel()
+		spec := pkg + "@" + previous
+		return runCommand(ic.runner, pm.describe(pm.add, spec), pm.command(ctx, dir, pm.add, spec), ic.logFile)
+	})
+	return nil
+}
+
+// removeNodeModule deletes pkg from dir's node_modules, and its scope directory
+// too if nothing else lives in it.
+func removeNodeModule(dir, pkg string) error {
+	nodeModules := filepath.Join(dir, "node_modules")
+	if err := os.RemoveAll(filepath.Join(nodeModules, pkg)); err != nil {
+		return err
+	}
+	if scope, _, scoped := strings.Cut(pkg, "/"); scoped {
+		os.Remove(filepath.Join(nodeModules, scope))
+	}
+	return nil
+}
+
+// rollbackInstall replays the undo log newest-first. It carries on past a step
+// that fails so one stuck file doesn't strand the rest, and reports them all.
+func rollbackInstall(ic *installContext) error {
+	var errs []error
+	for i := len(ic.undo.steps) - 1; i >= 0; i-- {
+		step := ic.undo.steps[i]
+		if ic.logFile != nil {
+			ic.logFile.WriteString(fmt.Sprintf("Rollback: %s\n", step.description))
+		}
+		if err := step.undo(); err != nil {
+			if ic.logFile != nil {
+				ic.logFile.WriteString(fmt.Sprintf("Rollback: %s failed: %v\n", step.description, err))
+			}
+			errs = append(errs, fmt.Errorf("%s: %w", step.description, err))
+		}
+	}
+	ic.undo.steps = nil
+	ic.backupFiles = make(map[string]backupEntry)
+	return errors.Join(errs...)
+}
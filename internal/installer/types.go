@@ -0,0 +1,370 @@
+// internal/installer/types.go
+package installer
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/charmbracelet/bubbles/progress"
+	"github.com/charmbracelet/bubbles/spinner"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Installation steps
+type installStep int
+
+const (
+	stepWelcome installStep = iota
+	stepInstalling
+	stepUninstalling
+	stepSelectModels
+	stepConfirmConfig
+	stepComplete
+	stepSelectOpenCode
+	stepRepairConfig
+	stepConfirmUninstall
+)
+
+// Task status
+type taskStatus int
+
+const (
+	statusPending taskStatus = iota
+	statusRunning
+	statusComplete
+	statusFailed
+	statusSkipped
+)
+
+func (s taskStatus) String() string {
+	switch s {
+	case statusPending:
+		return "pending"
+	case statusRunning:
+		return "running"
+	case statusComplete:
+		return "complete"
+	case statusFailed:
+		return "failed"
+	case statusSkipped:
+		return "skipped"
+	default:
+		return "unknown"
+	}
+}
+
+// Installation task
+type installTask struct {
+	name         string
+	description  string
+	execute      func(*model) error
+	optional     bool
+	status       taskStatus
+	errorDetails *errorInfo
+	note         string // e.g. the planned mutation when skipped in dry-run mode, or the model source
+	startedAt    time.Time
+	duration     time.Duration
+	awaitsInput  installStep                // TUI step to show after this task succeeds; stepWelcome (zero) means none
+	mutates      bool                       // changes the system; under --dry-run it only reports what it would do
+	remediation  string                     // next step shown if this task fails; overrides the error category's
+	verify       func(*installContext) bool // --resume: whether an earlier run's work still holds; nil always re-runs
+	resumed      bool                       // skipped by --resume
+}
+
+// taskReport is the machine-readable form of a finished task (--json)
+type taskReport struct {
+	Type       string `json:"type"`
+	Name       string `json:"name"`
+	Status     string `json:"status"`
+	Err        string `json:"err,omitempty"`
+	Category   string `json:"category,omitempty"`
+	Fix        string `json:"fix,omitempty"`
+	Note       string `json:"note,omitempty"`
+	DurationMs int64  `json:"duration_ms"`
+	Optional   bool   `json:"optional"`
+}
+
+func (t installTask) report() taskReport {
+	r := taskReport{
+		Type:       "task",
+		Name:       t.name,
+		Status:     t.status.String(),
+		Note:       t.note,
+		DurationMs: t.duration.Milliseconds(),
+		Optional:   t.optional,
+	}
+	if t.errorDetails != nil {
+		r.Err = t.errorDetails.message
+		r.Category = t.errorDetails.category
+		r.Fix = t.errorDetails.remediation
+	}
+	return r
+}
+
+// installWarning is a problem that did not stop the run but that the user
+// should know about
+type installWarning struct {
+	Source  string `json:"source"` // the task or pre-install check that found it
+	Message string `json:"message"`
+}
+
+func (w installWarning) String() string {
+	return w.Source + ": " + w.Message
+}
+
+// summaryReport is the final --json object, totalling task outcomes
+type summaryReport struct {
+	Type       string           `json:"type"`
+	Completed  int              `json:"completed"`
+	Failed     int              `json:"failed"`
+	Skipped    int              `json:"skipped"`
+	Warnings   []installWarning `json:"warnings,omitempty"`
+	Removed    []string         `json:"removed,omitempty"`
+	BackupDir  string           `json:"backup_dir,omitempty"` // set when files were backed up before being changed
+	NextSteps  []nextStep       `json:"next_steps,omitempty"` // after a successful install
+	NoChanges  bool             `json:"no_changes,omitempty"` // an install that found everything already in place
+	LogFile    string           `json:"log_file,omitempty"`
+	DurationMs int64            `json:"duration_ms"` // since the installer started
+}
+
+// backupKind says what a backupEntry captured
+type backupKind int
+
+const (
+	backupRegular backupKind = iota
+	backupSymlink
+	backupAbsent // nothing was there; rollback removes whatever is
+)
+
+// backupEntry is the pre-install state of one path: a regular file's contents,
+// a symlink's target, or that the path did not exist.
+type backupEntry struct {
+	kind   backupKind
+	data   []byte
+	target string
+}
+
+type errorInfo struct {
+	message     string
+	command     string
+	logFile     string
+	remediation string // what the user should do next
+	category    string // InstallerError category, or UNKNOWN
+	rawOutput   string // the full output of a PARSE error; see unparsedOutput
+}
+
+// Pre-install check result
+type checkResult struct {
+	name    string
+	passed  bool
+	message string
+	warning bool     // true = non-blocking warning, false = blocking error
+	fixCmd  []string // runs a fix for a failed check: f on the welcome screen, or --auto-fix
+}
+
+// installContext is everything the install and uninstall tasks use: paths,
+// flags and the state they hand to each other. It holds nothing from Bubble
+// Tea, so a task can run against temp directories without a model.
+type installContext struct {
+	ctx     context.Context
+	logFile *os.File
+
+	// Installation paths
+	projectDir string
+	pluginDir  string
+	configPath string
+	npmTag     string
+	runner     CommandRunner // every external command goes through it; see CommandRunner
+
+	debugMode     bool
+	noRollback    bool
+	dryRun        bool
+	refreshModels bool
+	modelsTTL     time.Duration
+	baseURL       string
+	autoPort      bool
+	skipBuild     bool
+	modelsFile    string
+	modelsURL     string // --models-endpoint; see fetchLiveModels
+	captureModels string // --capture-models-output; see modelsCapture
+	deepVerify    bool
+	noNetwork     bool   // --no-network; see checkOfflineArtifacts
+	ref           string // --ref; see checkoutRef
+	mergeStrategy string // --merge-strategy; see mergeModels
+	force         bool
+	acpSdkVersion string
+	aiSdkVersion  string
+	pkgManager    string
+	proxy         string // --proxy, for the commands not run through runner and for httpClient
+	opencodeBin   string // the opencode binary to run; see useOpenCode
+	excludeModels []string
+	onlyModels    []string
+	timeout       time.Duration // --timeout; see startDeadline
+	verifyRetries int
+	verifyTimeout time.Duration
+	resumeFrom    *installProgress       // --resume: the earlier install's progress, if it applies
+	pluginDirSet  bool                   // --plugin-dir given; it beats the manifest's plugin path
+	pluginDirFrom string                 // why pluginDir; see detectPluginDir
+	container     bool                   // running in a container; see inContainer
+	providerOpts  map[string]interface{} // --provider-option; see applyProviderOptions
+
+	// Backup files for rollback
+	backupFiles map[string]backupEntry
+	diskBackups map[string]string // original path -> timestamped on-disk copy
+	backupDir   string            // where the on-disk copies go (--backup-dir)
+	undo        *undoLog          // every change made so far, replayed backwards on failure
+
+	// Results handed from one task to the next
+	state *installState
+}
+
+// task adapts an installContext task to installTask.execute
+func task(execute func(*installContext) error) func(*model) error {
+	return func(m *model) error {
+		return execute(&m.installContext)
+	}
+}
+
+// Main model
+type model struct {
+	installContext
+
+	step             installStep
+	tasks            []installTask
+	currentTaskIndex int
+	width            int
+	height           int
+	spinner          spinner.Model
+	progress         progress.Model
+	errors           []string
+	warnings         []installWarning
+	selectedOption   int
+	headless         bool
+	jsonOutput       bool
+	quiet            bool
+	startedAt        time.Time // when the installer started, for the total time
+	startCmd         tea.Cmd   // run by Init, e.g. to begin --uninstall immediately
+
+	// Animations
+	beams  *BeamsTextEffect
+	ticker *TypewriterTicker
+
+	// Model selection
+	modelChoices  []string // model IDs, sorted
+	modelSelected map[string]bool
+	modelCursor   int
+	refetching    bool   // 'r' pressed; waiting for modelsRefetchedMsg
+	refetchError  string // why the last re-fetch failed
+
+	// Streaming log pane (toggled with 'l')
+	logLines  []string
+	showLog   bool
+	logScroll int // lines scrolled back from the newest
+
+	diffScroll int // first visible line of the config diff
+
+	// Full output of a failed parse on the completion screen (toggled with 'r')
+	showRawOutput bool
+	rawScroll     int // first visible line
+
+	repairError string // why resetting a corrupt config failed
+
+	// OpenCode install selection, when more than one is found
+	opencodeInstalls []OpenCodeInfo
+	opencodeCursor   int
+
+	// Pre-install checks
+	checks         []checkResult
+	checksComplete bool
+	checkOpts      cliOptions // the options the checks ran with, to run them again after a fix
+	fixCursor      int        // which fixable check f runs; see selectedFix
+	autoFix        bool       // --auto-fix
+
+	existingSetup bool
+	isUninstall   bool
+	uninstallPlan []uninstallItem // listed for confirmation before uninstalling
+
+	// Context for cancellation
+	cancel   context.CancelFunc
+	ticking  bool // tickCmd loop is scheduled
+	spinning bool // spinner tick loop is scheduled
+	static   bool // no header animation or tickCmd loop; see animationsDisabled
+
+	completeStatus string // result of the last completion-screen action (open/copy log)
+
+	cancelling bool // interrupt requested; waiting for the running task to stop
+	cancelled  bool
+}
+
+// installState is shared by pointer because Bubble Tea copies the model on
+// every update; tasks record results here that later tasks depend on.
+type installState struct {
+	pluginEntry  string // resolved plugin entrypoint (npm global or local dist)
+	pluginCopied bool   // plugin was copied rather than symlinked (e.g. Windows)
+
+	models         map[string]interface{} // fetched from cursor-agent
+	selectedModels map[string]bool        // chosen in the TUI; nil keeps every fetched model
+
+	note     string           // set by the running task to annotate its result line
+	warnings []installWarning // moved into model.warnings when the task completes
+
+	autoPortURL string   // baseURL with the port chosen by --port auto
+	mergeNotes  []string // what mergeModels kept or dropped, warned about by updateConfig
+	optionNotes []string // configured options --provider-option replaced, warned about by updateConfig
+	configDiff  []string // planned opencode.json change, shown for confirmation
+	removed     []string // what uninstall tasks deleted, for the summary
+
+	// manifest is built up by install tasks, or loaded from disk for uninstall
+	manifest *installManifest
+}
+
+// warn records a warning for the completion screen; source says what found it
+func (s *installState) warn(source, format string, args ...interface{}) {
+	s.warnings = append(s.warnings, installWarning{Source: source, Message: fmt.Sprintf(format, args...)})
+}
+
+// record returns the manifest being built by the current install
+func (s *installState) record() *installManifest {
+	if s.manifest == nil {
+		s.manifest = &installManifest{}
+	}
+	return s.manifest
+}
+
+// Messages
+type taskCompleteMsg struct {
+	index       int
+	success     bool
+	skipped     bool
+	resumed     bool // skipped because --resume found the work already done
+	err         string
+	remediation string
+	specificFix bool   // remediation is specific to the error, not the task
+	category    string // InstallerError category, or UNKNOWN
+	rawOutput   string // the output a PARSE error could not make sense of
+	note        string
+	elapsed     time.Duration
+}
+
+// modelsRefetchedMsg is the result of fetching the models again from the
+// model selection screen
+type modelsRefetchedMsg struct {
+	models map[string]interface{}
+	source string
+	err    error
+}
+
+type checksCompleteMsg struct {
+	checks   []checkResult
+	installs []OpenCodeInfo // set when a fix installed OpenCode
+}
+
+type tickMsg time.Time
+
+// logLineMsg is one line of child-process output for the log pane
+type logLineMsg string
+
+// globalProgram for sending messages from goroutines
+var globalProgram *tea.Program
@@ -0,0 +1,150 @@
+// internal/installer/uninstallplan.go
+package installer
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// uninstallItem is one thing uninstalling will remove, as the confirmation
+// before it lists them
+type uninstallItem struct {
+	Kind string `json:"kind"` // "plugin", "package", "config" or "file"
+	What string `json:"what"`
+}
+
+func (item uninstallItem) String() string {
+	return item.Kind + ": " + item.What
+}
+
+// uninstallManifest reads the manifest uninstallTasks will work from; nil
+// means the heuristic cleanup of installs that predate it
+func uninstallManifest(configPath string) *installManifest {
+	manifest, err := readManifest(configPath)
+	if err != nil {
+		return nil
+	}
+	return manifest
+}
+
+// planUninstall lists what the uninstall tasks would remove right now, from
+// the manifest when there is one, the same way they decide it
+func planUninstall(ic *installContext) []uninstallItem {
+	manifest := uninstallManifest(ic.configPath)
+	var items []uninstallItem
+
+	pluginPath := uninstallPluginPath(ic, manifest)
+	if info, err := os.Lstat(pluginPath); err == nil {
+		if target, err := os.Readlink(pluginPath); err == nil {
+			items = append(items, uninstallItem{"plugin", pluginPath + " -> " + target})
+		} else if info.Mode().IsRegular() {
+			items = append(items, uninstallItem{"plugin", pluginPath + " (copy)"})
+		}
+	}
+
+	for _, pkg := range uninstallPackages(manifest) {
+		items = append(items, uninstallItem{"package", fmt.Sprintf("%s in %s", pkg.name, filepath.Join(pkg.dir, "node_modules"))})
+	}
+
+	if config, _, err := readConfig(ic.configPath); err == nil {
+		for _, key := range configKeyNames(removeCursorAcpKeys(config, manifest)) {
+			items = append(items, uninstallItem{"config", fmt.Sprintf("%s in %s", key, ic.configPath)})
+		}
+	}
+
+	if manifest == nil {
+		if hasLegacyPluginEntry(ic.configPath) {
+			items = append(items, uninstallItem{"config", fmt.Sprintf("plugin[cursor-acp-auth] in %s", ic.configPath)})
+		}
+		if _, err := os.Stat(legacyPluginCacheDir()); err == nil {
+			items = append(items, uninstallItem{"file", legacyPluginCacheDir()})
+		}
+	} else {
+		items = append(items, uninstallItem{"file", getManifestPath(ic.configPath)})
+	}
+	return items
+}
+
+// uninstallPackage is a node_modules package uninstalling removes
+type uninstallPackage struct {
+	name string
+	dir  string // holds package.json and node_modules
+}
+
+// uninstallPackages lists the packages the uninstall tasks remove that are
+// present: the manifest's, or without one the ACP SDK
+func uninstallPackages(manifest *installManifest) []uninstallPackage {
+	var candidates []uninstallPackage
+	if manifest != nil {
+		for _, pkg := range manifest.Packages {
+			candidates = append(candidates, uninstallPackage{pkg, manifest.PackagesDir})
+		}
+	} else if configDir, err := getConfigDir(); err == nil {
+		// removeAcpSdk keeps a copy package.json lists
+		dir := filepath.Join(configDir, "opencode")
+		if !packageJSONDepends(dir, "@agentclientprotocol/sdk") {
+			candidates = append(candidates, uninstallPackage{"@agentclientprotocol/sdk", dir})
+		}
+	}
+
+	var present []uninstallPackage
+	for _, pkg := range candidates {
+		if _, err := os.Stat(filepath.Join(pkg.dir, "node_modules", pkg.name)); err == nil {
+			present = append(present, pkg)
+		}
+	}
+	return present
+}
+
+// backupBeforeUninstall backs up every file the uninstall changes or
+// removes before any of them is touched, so all of it can be put back with
+// `restore` even if a later step fails
+func backupBeforeUninstall(ic *installContext) error {
+	manifest := ic.state.manifest
+	paths := []string{ic.configPath}
+	if manifest != nil {
+		paths = append(paths, getManifestPath(ic.configPath))
+	}
+	dirs := map[string]bool{}
+	for _, pkg := range uninstallPackages(manifest) {
+		if !dirs[pkg.dir] {
+			dirs[pkg.dir] = true
+			paths = append(paths, filepath.Join(pkg.dir, "package.json"))
+		}
+	}
+
+	var existing []string
+	for _, path := range paths {
+		if _, err := os.Stat(path); err == nil {
+			existing = append(existing, path)
+		}
+	}
+	pluginPath := uninstallPluginPath(ic, manifest)
+	_, statErr := os.Lstat(pluginPath)
+	if len(existing) == 0 && statErr != nil {
+		return skipTask("nothing to back up")
+	}
+
+	if ic.dryRun {
+		return skipTask("would back up %s", strings.Join(existing, ", "))
+	}
+
+	for _, path := range existing {
+		if err := createBackup(ic, path); err != nil {
+			return err
+		}
+	}
+	// A link is recorded by target; a copied plugin is backed up like a file
+	if statErr == nil {
+		if err := backupLink(ic, pluginPath); err != nil {
+			return err
+		}
+	}
+
+	if len(ic.diskBackups) > 0 {
+		ic.state.note = fmt.Sprintf("%d files backed up to %s", len(ic.diskBackups), ic.backupDir)
+	}
+	return nil
+}
@@ -0,0 +1,535 @@
+// internal/installer/update.go
+package installer
+
+import (
+	"fmt"
+	"os/exec"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/spinner"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		m.height = msg.Height
+		// Without beams View draws the plain header
+		if m.static {
+			return m, nil
+		}
+		// Calculate header height: 4 lines for ASCII art + 2 for padding
+		headerHeight := 6
+		if m.beams == nil {
+			m.beams = NewBeamsTextEffect(msg.Width, headerHeight, asciiHeader)
+		} else {
+			m.beams.Resize(msg.Width, headerHeight)
+		}
+		return m, nil
+
+	case tickMsg:
+		// Static screens let the loop lapse so an idle installer uses no CPU;
+		// resumeAnimation restarts it
+		if !m.animating() {
+			m.ticking = false
+			return m, nil
+		}
+		if m.beams != nil {
+			m.beams.Update()
+		}
+		if m.ticker != nil {
+			m.ticker.Update()
+		}
+		return m, tickCmd()
+
+	case tea.KeyMsg:
+		return m.handleKeyPress(msg)
+
+	case spinner.TickMsg:
+		if !m.runningTasks() && !m.refetching {
+			m.spinning = false
+			return m, nil
+		}
+		var cmd tea.Cmd
+		m.spinner, cmd = m.spinner.Update(msg)
+		return m, cmd
+
+	case taskCompleteMsg:
+		return m.handleTaskComplete(msg)
+
+	case modelsRefetchedMsg:
+		return m.handleModelsRefetched(msg)
+
+	case logLineMsg:
+		m.appendLogLine(string(msg))
+		return m, nil
+
+	case checksCompleteMsg:
+		m.applyChecks(msg)
+		return m, nil
+	}
+
+	return m, nil
+}
+
+func (m model) handleKeyPress(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	key := msg.String()
+
+	switch key {
+	case "ctrl+c", "esc":
+		if m.cancel != nil {
+			m.cancel()
+		}
+		switch m.step {
+		case stepInstalling, stepUninstalling:
+			// Killing the running command makes its task return; handleTaskComplete
+			// then rolls back. A second press exits without waiting.
+			if m.cancelling {
+				return m, tea.Quit
+			}
+			m.cancelling = true
+			return m, nil
+		case stepSelectModels, stepConfirmConfig:
+			return m.finishCancelled()
+		}
+		return m, tea.Quit
+
+	case "q":
+		if m.step == stepComplete || m.step == stepWelcome || m.step == stepSelectOpenCode || m.step == stepRepairConfig || m.step == stepConfirmUninstall {
+			return m, tea.Quit
+		}
+	}
+
+	switch m.step {
+	case stepWelcome:
+		return m.handleWelcomeKeys(key)
+	case stepInstalling, stepUninstalling:
+		// Can't quit during install/uninstall
+		return m.handleInstallingKeys(key)
+	case stepSelectModels:
+		return m.handleSelectModelsKeys(key)
+	case stepConfirmConfig:
+		return m.handleConfirmConfigKeys(key)
+	case stepComplete:
+		return m.handleCompleteKeys(key)
+	case stepSelectOpenCode:
+		return m.handleSelectOpenCodeKeys(key)
+	case stepRepairConfig:
+		return m.handleRepairConfigKeys(key)
+	case stepConfirmUninstall:
+		return m.handleConfirmUninstallKeys(key)
+	}
+
+	return m, nil
+}
+
+func (m model) handleConfirmUninstallKeys(key string) (tea.Model, tea.Cmd) {
+	switch key {
+	case "enter", "y":
+		return m.startUninstallation()
+	case "n":
+		m.step = stepWelcome
+		return m, m.resumeAnimation()
+	}
+	return m, nil
+}
+
+func (m model) handleRepairConfigKeys(key string) (tea.Model, tea.Cmd) {
+	switch key {
+	case "enter", "y":
+		backupPath, err := resetCorruptConfig(&m.installContext)
+		if err != nil {
+			m.repairError = err.Error()
+			return m, nil
+		}
+		m.repairError = ""
+		m.configReset(backupPath)
+		return m.startInstallation()
+	case "n":
+		m.repairError = ""
+		m.step = stepWelcome
+	}
+	return m, nil
+}
+
+// configReset records that the unparseable config was reset: its check no
+// longer stands, and a warning says where the old content went
+func (m *model) configReset(backupPath string) {
+	m.checks = append([]checkResult(nil), m.checks...)
+	for i := range m.checks {
+		if m.checks[i].name == configSyntaxCheckName {
+			m.checks[i] = checkResult{name: configSyntaxCheckName, passed: true, message: "reset to a minimal config"}
+		}
+	}
+	m.warnings = append(m.warnings, installWarning{Source: configSyntaxCheckName,
+		Message: fmt.Sprintf("%s was not valid JSON; its old content is in %s", m.configPath, backupPath)})
+}
+
+func (m model) handleSelectOpenCodeKeys(key string) (tea.Model, tea.Cmd) {
+	switch key {
+	case "up", "k":
+		if m.opencodeCursor > 0 {
+			m.opencodeCursor--
+		}
+	case "down", "j":
+		if m.opencodeCursor < len(m.opencodeInstalls)-1 {
+			m.opencodeCursor++
+		}
+	case "enter":
+		m.useOpenCode(m.opencodeInstalls[m.opencodeCursor])
+		m.step = stepWelcome
+		return m, m.resumeAnimation()
+	}
+	return m, nil
+}
+
+// useOpenCode makes info the install that tasks run. Every install method
+// shares ~/.config/opencode, so the config and plugin paths stay as they are.
+func (m *model) useOpenCode(info OpenCodeInfo) {
+	m.opencodeBin = "opencode"
+	if info.Installed {
+		m.opencodeBin = info.BinaryPath
+	}
+
+	// Swap the OpenCode rows of the pre-install checks for this one's
+	var checks []checkResult
+	for _, check := range m.checks {
+		switch check.name {
+		case "OpenCode":
+			checks = append(checks, openCodeChecks(info)...)
+		case "OpenCode binary":
+		default:
+			checks = append(checks, check)
+		}
+	}
+	m.checks = checks
+}
+
+func (m model) handleWelcomeKeys(key string) (tea.Model, tea.Cmd) {
+	switch key {
+	case "enter":
+		// Check for blocking errors (only for install)
+		for _, check := range m.checks {
+			if !check.passed && !check.warning {
+				return m, nil // Don't proceed with blocking errors
+			}
+		}
+		if !m.dryRun && configSyntaxProblem(m.configPath) != "" {
+			m.step = stepRepairConfig
+			return m, nil
+		}
+		return m.startInstallation()
+	case "u":
+		// Uninstall - no prerequisites needed
+		if m.existingSetup {
+			return m.confirmUninstall(), nil
+		}
+	case "l":
+		if m.needsLogin() {
+			return m, m.loginCmd()
+		}
+	case "up", "k":
+		if m.fixCursor > 0 {
+			m.fixCursor--
+		}
+	case "down", "j":
+		if m.fixCursor < len(fixableChecks(m.checks))-1 {
+			m.fixCursor++
+		}
+	case "f":
+		if check, ok := m.selectedFix(); ok {
+			return m, m.fixCmd(check)
+		}
+	}
+	return m, nil
+}
+
+// needsLogin reports whether cursor-agent is installed but not logged in
+func (m model) needsLogin() bool {
+	for _, check := range m.checks {
+		if check.name == loginCheckName {
+			return !check.passed
+		}
+	}
+	return false
+}
+
+// loginCmd suspends the TUI to run `cursor-agent login` in the terminal, then
+// re-checks the login so the welcome screen reflects the result.
+func (m model) loginCmd() tea.Cmd {
+	checks := append([]checkResult(nil), m.checks...)
+	login := exec.Command("cursor-agent", "login")
+	setProxyEnv(login, m.proxy)
+	return tea.ExecProcess(login, func(err error) tea.Msg {
+		recorder.command(login, err)
+		for i := range checks {
+			if checks[i].name != loginCheckName {
+				continue
+			}
+			checks[i] = checkLogin(m.runner, m.container)
+			if err != nil && !checks[i].passed {
+				checks[i].message = fmt.Sprintf("login failed (%v) - run: cursor-agent login", err)
+			}
+		}
+		return checksCompleteMsg{checks: checks}
+	})
+}
+
+// maxLogLines bounds the log pane's scrollback
+const maxLogLines = 500
+
+// logPaneHeight is the number of log lines shown at once
+const logPaneHeight = 8
+
+func (m model) handleInstallingKeys(key string) (tea.Model, tea.Cmd) {
+	switch key {
+	case "l":
+		m.showLog = !m.showLog
+	case "up", "k":
+		if m.showLog && m.logScroll < len(m.logLines)-logPaneHeight {
+			m.logScroll++
+		}
+	case "down", "j":
+		if m.showLog && m.logScroll > 0 {
+			m.logScroll--
+		}
+	}
+	return m, nil
+}
+
+// appendLogLine adds child-process output to the pane, keeping only the last
+// carriage-return segment so progress bars don't flood it.
+func (m *model) appendLogLine(line string) {
+	line = strings.TrimRight(line, "\r\n")
+	if i := strings.LastIndex(line, "\r"); i >= 0 {
+		line = line[i+1:]
+	}
+	line = stripANSI(line)
+	if strings.TrimSpace(line) == "" {
+		return
+	}
+
+	m.logLines = append(m.logLines, line)
+	if len(m.logLines) > maxLogLines {
+		m.logLines = m.logLines[len(m.logLines)-maxLogLines:]
+	}
+	// Keep the view anchored when scrolled back
+	if m.logScroll > 0 {
+		m.logScroll++
+	}
+}
+
+func (m model) handleCompleteKeys(key string) (tea.Model, tea.Cmd) {
+	switch key {
+	case "enter", "q":
+		return m, tea.Quit
+	case "o", "c":
+		if m.logFile == nil {
+			m.completeStatus = "No log file was created for this run"
+			return m, nil
+		}
+		m.logFile.Sync()
+		path := m.logFile.Name()
+		if key == "o" {
+			if err := openInViewer(path); err != nil {
+				m.completeStatus = fmt.Sprintf("Could not open the log (%v); it is at %s", err, path)
+			} else {
+				m.completeStatus = "Opened " + path
+			}
+		} else if err := copyToClipboard(path); err != nil {
+			m.completeStatus = fmt.Sprintf("Could not copy to the clipboard (%v)", err)
+		} else {
+			m.completeStatus = "Copied " + path
+		}
+	case "r":
+		if _, ok := m.unparsedTask(); ok {
+			m.showRawOutput = !m.showRawOutput
+		}
+	case "up", "k":
+		if m.showRawOutput && m.rawScroll > 0 {
+			m.rawScroll--
+		}
+	case "down", "j":
+		if m.showRawOutput && m.rawScroll < len(m.rawOutputLines())-rawPaneHeight {
+			m.rawScroll++
+		}
+	}
+	return m, nil
+}
+
+func (m model) handleConfirmConfigKeys(key string) (tea.Model, tea.Cmd) {
+	switch key {
+	case "up", "k":
+		if m.diffScroll > 0 {
+			m.diffScroll--
+		}
+	case "down", "j":
+		if m.diffScroll < len(m.state.configDiff)-1 {
+			m.diffScroll++
+		}
+	case "enter", "y":
+		return m.resumeInstall()
+	case "n":
+		// Only read-only tasks have run so far; finishCancelled undoes a
+		// reset of a corrupt config
+		return m.finishCancelled()
+	}
+	return m, nil
+}
+
+// animating reports whether the current step has moving parts: the header
+// animation on the welcome screen and the task list while it runs.
+func (m model) animating() bool {
+	return m.step == stepWelcome || m.runningTasks()
+}
+
+func (m model) runningTasks() bool {
+	return m.step == stepInstalling || m.step == stepUninstalling
+}
+
+// resumeAnimation restarts whichever tick loops lapsed on a static screen
+func (m *model) resumeAnimation() tea.Cmd {
+	var cmds []tea.Cmd
+	if !m.ticking && !m.static {
+		m.ticking = true
+		cmds = append(cmds, tickCmd())
+	}
+	if !m.spinning {
+		m.spinning = true
+		cmds = append(cmds, m.spinner.Tick)
+	}
+	return tea.Batch(cmds...)
+}
+
+func (m model) startModelSelection() (tea.Model, tea.Cmd) {
+	m.setModelChoices(nil)
+	m.refetchError = ""
+	m.step = stepSelectModels
+	return m, nil
+}
+
+// setModelChoices lists m.state.models for selection, all selected except
+// those previously left unticked
+func (m *model) setModelChoices(previous map[string]bool) {
+	m.modelChoices = make([]string, 0, len(m.state.models))
+	for id := range m.state.models {
+		m.modelChoices = append(m.modelChoices, id)
+	}
+	sort.Strings(m.modelChoices)
+
+	m.modelSelected = make(map[string]bool, len(m.modelChoices))
+	for _, id := range m.modelChoices {
+		selected, seen := previous[id]
+		m.modelSelected[id] = selected || !seen
+	}
+	m.modelCursor = 0
+}
+
+// refetchModelsCmd fetches the models again, bypassing the cache, for when
+// the first fetch ran before a `cursor-agent login`
+func (m model) refetchModelsCmd() tea.Cmd {
+	ic := m.installContext
+	ic.refreshModels = true
+	return func() tea.Msg {
+		models, source, err := loadModels(&ic)
+		return modelsRefetchedMsg{models: models, source: source, err: err}
+	}
+}
+
+func (m model) handleModelsRefetched(msg modelsRefetchedMsg) (tea.Model, tea.Cmd) {
+	m.refetching = false
+	if m.step != stepSelectModels {
+		return m, nil
+	}
+	if msg.err != nil {
+		m.refetchError = msg.err.Error()
+		return m, nil
+	}
+	m.refetchError = ""
+	m.state.models = msg.models
+	m.tasks[m.currentTaskIndex].note = fetchedModelsNote(msg.models, msg.source)
+	m.setModelChoices(m.modelSelected)
+	if m.logFile != nil {
+		m.logFile.WriteString(fmt.Sprintf("Re-fetched %s\n", fetchedModelsNote(msg.models, msg.source)))
+	}
+	return m, nil
+}
+
+func (m model) handleSelectModelsKeys(key string) (tea.Model, tea.Cmd) {
+	// The list is about to be replaced
+	if m.refetching {
+		return m, nil
+	}
+	switch key {
+	case "up", "k":
+		if m.modelCursor > 0 {
+			m.modelCursor--
+		}
+	case "down", "j":
+		if m.modelCursor < len(m.modelChoices)-1 {
+			m.modelCursor++
+		}
+	case " ", "x":
+		if len(m.modelChoices) > 0 {
+			id := m.modelChoices[m.modelCursor]
+			m.modelSelected[id] = !m.modelSelected[id]
+		}
+	case "a":
+		// Select all, or none if everything is already selected
+		all := m.selectedModelCount() == len(m.modelChoices)
+		for _, id := range m.modelChoices {
+			m.modelSelected[id] = !all
+		}
+	case "enter":
+		if m.selectedModelCount() == 0 && !m.keepsConfiguredModels(m.currentConfig()) {
+			return m, nil // Nothing would be written
+		}
+		selected := make(map[string]bool, len(m.modelSelected))
+		for id, ok := range m.modelSelected {
+			if ok {
+				selected[id] = true
+			}
+		}
+		m.state.selectedModels = selected
+		return m.resumeInstall()
+	case "r":
+		m.refetching = true
+		m.refetchError = ""
+		cmds := []tea.Cmd{m.refetchModelsCmd()}
+		if !m.spinning {
+			m.spinning = true
+			cmds = append(cmds, m.spinner.Tick)
+		}
+		return m, tea.Batch(cmds...)
+	}
+	return m, nil
+}
+
+// resumeInstall moves on to the next task once a prompt has been answered
+func (m model) resumeInstall() (tea.Model, tea.Cmd) {
+	m.step = stepInstalling
+	resume := m.resumeAnimation()
+	next, cmd := m.advanceTask()
+	return next, tea.Batch(resume, cmd)
+}
+
+func (m model) selectedModelCount() int {
+	count := 0
+	for _, ok := range m.modelSelected {
+		if ok {
+			count++
+		}
+	}
+	return count
+}
+
+// currentConfig reads the config on disk, returning nil if it is missing or invalid
+func (m model) currentConfig() map[string]interface{} {
+	config, _, err := readConfig(m.configPath)
+	if err != nil {
+		return nil
+	}
+	return config
+}
@@ -0,0 +1,809 @@
+// internal/installer/utils.go
+package installer
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"os/user"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// getConfigDir returns the actual user's config directory (not root's when
+// using sudo): $XDG_CONFIG_HOME when set, otherwise ~/.config. Under sudo the
+// variable is only present if the invoking user exported it through (sudo -E
+// or env_keep), so it still names their directory.
+func getConfigDir() (string, error) {
+	homeDir, err := actualHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return configDirFor(homeDir, os.Getenv("XDG_CONFIG_HOME"), runtime.GOOS), nil
+}
+
+// actualHomeDir returns the home directory of the user who ran the installer,
+// looking past sudo
+func actualHomeDir() (string, error) {
+	if sudoUser := os.Getenv("SUDO_USER"); sudoUser != "" && sudoUser != "root" {
+		if u, err := user.Lookup(sudoUser); err == nil {
+			return u.HomeDir, nil
+		}
+	}
+	return os.UserHomeDir()
+}
+
+// configDirFor applies the XDG precedence. Relative values are invalid per the
+// spec and are ignored. On macOS some OpenCode installs keep their config in
+// ~/Library/Application Support instead; that is used when it has an
+// opencode.json and ~/.config does not, so the installer edits the config
+// OpenCode actually reads rather than creating an ignored one.
+func configDirFor(homeDir, xdgConfigHome, goos string) string {
+	if xdgConfigHome != "" && filepath.IsAbs(xdgConfigHome) {
+		return filepath.Clean(xdgConfigHome)
+	}
+	configDir := filepath.Join(homeDir, ".config")
+	if goos == "darwin" && !hasOpenCodeConfig(configDir) {
+		appSupport := filepath.Join(homeDir, "Library", "Application Support")
+		if hasOpenCodeConfig(appSupport) {
+			return appSupport
+		}
+	}
+	return configDir
+}
+
+// hasOpenCodeConfig reports whether dir/opencode holds an opencode.json
+func hasOpenCodeConfig(dir string) bool {
+	info, err := os.Stat(filepath.Join(dir, "opencode", "opencode.json"))
+	return err == nil && !info.IsDir()
+}
+
+// getActualUser returns the actual username (not root when using sudo)
+func getActualUser() string {
+	if sudoUser := os.Getenv("SUDO_USER"); sudoUser != "" && sudoUser != "root" {
+		return sudoUser
+	}
+	if u, err := user.Current(); err == nil {
+		return u.Username
+	}
+	return "unknown"
+}
+
+// chownToActualUser hands path back to the user who ran sudo, so files the
+// installer creates stay editable without root. It does nothing unless running
+// as root with SUDO_USER set, or if path does not exist.
+func chownToActualUser(path string) error {
+	sudoUser := os.Getenv("SUDO_USER")
+	if os.Geteuid() != 0 || sudoUser == "" || sudoUser == "root" {
+		return nil
+	}
+	u, err := user.Lookup(sudoUser)
+	if err != nil {
+		return err
+	}
+	uid, err := strconv.Atoi(u.Uid)
+	if err != nil {
+		return err
+	}
+	gid, err := strconv.Atoi(u.Gid)
+	if err != nil {
+		return err
+	}
+	// Lchown so a symlink itself changes hands, not its target
+	if err := os.Lchown(path, uid, gid); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// mkdirAllForUser is os.MkdirAll that also returns the directories it had to
+// create, so they can be handed back to the sudo user along with their contents.
+func mkdirAllForUser(dir string) ([]string, error) {
+	var created []string
+	for p := filepath.Clean(dir); ; p = filepath.Dir(p) {
+		if _, err := os.Stat(p); err == nil || p == filepath.Dir(p) {
+			break
+		}
+		created = append(created, p)
+	}
+	return created, os.MkdirAll(dir, 0755)
+}
+
+// chownToUser runs chownToActualUser on each path (and everything under it
+// when recursive). Failures become warnings: the install still works, the
+// user may just need sudo to edit the files later.
+func (ic *installContext) chownToUser(recursive bool, paths ...string) {
+	for _, path := range paths {
+		var err error
+		if recursive {
+			err = filepath.WalkDir(path, func(p string, _ os.DirEntry, walkErr error) error {
+				if walkErr != nil {
+					if os.IsNotExist(walkErr) {
+						return nil
+					}
+					return walkErr
+				}
+				return chownToActualUser(p)
+			})
+		} else {
+			err = chownToActualUser(path)
+		}
+		if err != nil {
+			ic.state.warn("permissions", "could not give %s back to %s: %v", path, getActualUser(), err)
+		}
+	}
+}
+
+// opencodePaths returns the opencode.json to edit and the plugin directory
+// next to it. configOverride (--config) replaces ~/.config/opencode/opencode.json.
+func opencodePaths(configOverride string) (string, string, error) {
+	if configOverride != "" {
+		configPath, err := filepath.Abs(configOverride)
+		if err != nil {
+			return "", "", err
+		}
+		return configPath, pluginDirNextTo(configPath), nil
+	}
+
+	configDir, err := getConfigDir()
+	if err != nil {
+		return "", "", err
+	}
+	configPath := filepath.Join(configDir, "opencode", "opencode.json")
+	return configPath, pluginDirNextTo(configPath), nil
+}
+
+// pluginDirNextTo picks the plugin directory beside configPath. OpenCode
+// loads both plugin/ and plugins/, so an existing plugins/ is used when there
+// is no plugin/; otherwise plugin/.
+func pluginDirNextTo(configPath string) string {
+	dir := filepath.Dir(configPath)
+	for _, name := range []string{"plugin", "plugins"} {
+		if info, err := os.Stat(filepath.Join(dir, name)); err == nil && info.IsDir() {
+			return filepath.Join(dir, name)
+		}
+	}
+	return filepath.Join(dir, "plugin")
+}
+
+// openCodePluginDirs lists the directories OpenCode loads plugins from: plugin/
+// and plugins/ under the detected install's config directory, beside the
+// config in use, under $OPENCODE_CONFIG_DIR, and in the working directory's
+// .opencode.
+func openCodePluginDirs(opencode OpenCodeInfo, configPath string) []string {
+	var roots []string
+	if opencode.ConfigDir != "" {
+		roots = append(roots, opencode.ConfigDir)
+	}
+	if configPath != "" {
+		roots = append(roots, filepath.Dir(configPath))
+	}
+	if dir := os.Getenv("OPENCODE_CONFIG_DIR"); dir != "" {
+		roots = append(roots, dir)
+	}
+	if cwd, err := os.Getwd(); err == nil {
+		roots = append(roots, filepath.Join(cwd, ".opencode"))
+	}
+
+	var dirs []string
+	for _, root := range roots {
+		for _, name := range []string{"plugin", "plugins"} {
+			dir := filepath.Join(root, name)
+			if !slices.Contains(dirs, dir) {
+				dirs = append(dirs, dir)
+			}
+		}
+	}
+	return dirs
+}
+
+// checkPluginDir warns when pluginDir is not one OpenCode scans, since a
+// plugin linked there would never load
+func checkPluginDir(pluginDir string, opencode OpenCodeInfo, configPath string) checkResult {
+	scanned := openCodePluginDirs(opencode, configPath)
+	if slices.Contains(scanned, filepath.Clean(pluginDir)) {
+		return checkResult{name: "plugin dir", passed: true, message: pluginDir}
+	}
+	return checkResult{name: "plugin dir", passed: false, warning: true,
+		message: fmt.Sprintf("OpenCode does not load plugins from %s (it scans %s)", pluginDir, strings.Join(scanned, ", "))}
+}
+
+// detectExistingSetup checks if cursor-acp is already configured
+func detectExistingSetup(configOverride, pluginDirOverride string) (bool, string) {
+	configPath, pluginDir, err := opencodePaths(configOverride)
+	if err != nil {
+		return false, ""
+	}
+	if pluginDirOverride != "" {
+		pluginDir = pluginDirOverride
+	}
+
+	// Check for plugin symlink
+	symlinkPath := filepath.Join(pluginDir, "cursor-acp.js")
+	if _, err := os.Lstat(symlinkPath); err == nil {
+		return true, configPath
+	}
+
+	// Check config file
+	data, err := os.ReadFile(configPath)
+	if err != nil {
+		return false, configPath
+	}
+
+	var config map[string]interface{}
+	if err := parseJSONC(data, &config); err != nil {
+		return false, configPath
+	}
+
+	if providers, ok := config["provider"].(map[string]interface{}); ok {
+		if _, exists := providers["cursor-acp"]; exists {
+			return true, configPath
+		}
+	}
+
+	return false, configPath
+}
+
+// commandExists checks if a command is available
+func commandExists(cmd string) bool {
+	_, err := exec.LookPath(cmd)
+	return err == nil
+}
+
+// openInViewer opens path with the platform's default application without
+// waiting for it to close.
+func openInViewer(path string) error {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		cmd = exec.Command("open", path)
+	case "windows":
+		cmd = exec.Command("cmd", "/c", "start", "", path)
+	default:
+		cmd = exec.Command("xdg-open", path)
+	}
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	go cmd.Wait()
+	return nil
+}
+
+// copyToClipboard writes text to the system clipboard using whichever
+// clipboard tool the platform provides.
+func copyToClipboard(text string) error {
+	var candidates [][]string
+	switch runtime.GOOS {
+	case "darwin":
+		candidates = [][]string{{"pbcopy"}}
+	case "windows":
+		candidates = [][]string{{"clip"}}
+	default:
+		candidates = [][]string{{"wl-copy"}, {"xclip", "-selection", "clipboard"}, {"xsel", "--clipboard", "--input"}}
+	}
+	for _, argv := range candidates {
+		if !commandExists(argv[0]) {
+			continue
+		}
+		cmd := exec.Command(argv[0], argv[1:]...)
+		cmd.Stdin = strings.NewReader(text)
+		return cmd.Run()
+	}
+	return fmt.Errorf("no clipboard tool found")
+}
+
+// runCommand executes a command and logs output
+func runCommand(runner CommandRunner, name string, cmd *exec.Cmd, logFile *os.File) error {
+	timestamp := time.Now().Format("15:04:05")
+	cmdStr := cmd.String()
+
+	if logFile != nil {
+		logFile.WriteString(fmt.Sprintf("[%s] Running: %s\n", timestamp, cmdStr))
+	}
+
+	output, err := runner.Run(cmd)
+	outputStr := string(output)
+
+	if logFile != nil {
+		if len(output) > 0 {
+			logFile.Write(output)
+			logFile.WriteString("\n")
+		}
+		if err != nil {
+			logFile.WriteString(fmt.Sprintf("[%s] Error: %v\n\n", timestamp, err))
+		} else {
+			logFile.WriteString(fmt.Sprintf("[%s] Success\n\n", timestamp))
+		}
+		logFile.Sync()
+	} else if err != nil {
+		fmt.Fprintf(os.Stderr, "[%s] Command failed: %s\nOutput: %s\nError: %v\n",
+			timestamp, cmdStr, outputStr, err)
+	}
+
+	if err != nil {
+		return NewExecError(name+" failed", outputStr, err)
+	}
+	return nil
+}
+
+// runStreaming runs cmd like CombinedOutput, additionally forwarding each
+// stdout/stderr line to the TUI log pane as it arrives.
+func runStreaming(cmd *exec.Cmd) ([]byte, error) {
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	stderr, err := cmd.StderrPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+
+	var mu sync.Mutex
+	var combined bytes.Buffer
+	var wg sync.WaitGroup
+	stream := func(r io.Reader) {
+		defer wg.Done()
+		reader := bufio.NewReader(r)
+		for {
+			line, readErr := reader.ReadString('\n')
+			if line != "" {
+				mu.Lock()
+				combined.WriteString(line)
+				mu.Unlock()
+				if globalProgram != nil {
+					globalProgram.Send(logLineMsg(line))
+				}
+			}
+			if readErr != nil {
+				return
+			}
+		}
+	}
+	wg.Add(2)
+	go stream(stdout)
+	go stream(stderr)
+
+	// All reads must finish before Wait closes the pipes
+	wg.Wait()
+	err = cmd.Wait()
+	return combined.Bytes(), err
+}
+
+// networkRetryAttempts is the initial try plus three retries (1s, 2s, 4s backoff)
+const networkRetryAttempts = 4
+
+// runCommandWithRetry runs the command built by makeCmd, retrying with
+// exponential backoff while failures look like transient network errors.
+// makeCmd is called per attempt because an exec.Cmd cannot be reused.
+// Cancelling ctx stops the backoff wait between attempts.
+func runCommandWithRetry(ctx context.Context, runner CommandRunner, name string, makeCmd func() *exec.Cmd, attempts int, logFile *os.File) error {
+	backoff := time.Second
+	var err error
+	for attempt := 1; attempt <= attempts; attempt++ {
+		err = runCommand(runner, name, makeCmd(), logFile)
+		if err == nil || !isNetworkError(err) || attempt == attempts {
+			return err
+		}
+		if logFile != nil {
+			logFile.WriteString(fmt.Sprintf("[%s] %s hit a network error; retrying in %s (attempt %d/%d)\n",
+				time.Now().Format("15:04:05"), name, backoff, attempt+1, attempts))
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+	return err
+}
+
+// isNetworkError reports whether a failed command's output looks like a
+// transient registry/network failure rather than a local error.
+func isNetworkError(err error) bool {
+	var installerErr *InstallerError
+	if !errors.As(err, &installerErr) {
+		return false
+	}
+	var exitErr *exec.ExitError
+	if !errors.As(installerErr.Cause, &exitErr) {
+		return false
+	}
+
+	output := strings.ToLower(installerErr.RawOutput)
+	for _, marker := range []string{
+		"etimedout", "enotfound", "econnreset", "econnrefused", "eai_again",
+		"socket hang up", "network", "registry", "fetch failed",
+	} {
+		if strings.Contains(output, marker) {
+			return true
+		}
+	}
+	return false
+}
+
+// logFileName returns the path of the installer log, or "" when logging is unavailable
+func logFileName(logFile *os.File) string {
+	if logFile == nil {
+		return ""
+	}
+	return logFile.Name()
+}
+
+// writeFileAtomic writes data to a sibling temp file and renames it over path,
+// so a crash mid-write never leaves a truncated file behind. An existing file
+// keeps its mode, as with os.WriteFile; perm is for a new one.
+func writeFileAtomic(path string, data []byte, perm os.FileMode) error {
+	requested := path
+	// Write through a symlink rather than replacing it with a regular file,
+	// which would detach e.g. a config kept in a dotfiles repo
+	linked := false
+	if resolved, err := filepath.EvalSymlinks(path); err == nil && resolved != path {
+		path = resolved
+		linked = true
+	}
+	// A 0600 opencode.json holding provider keys must not become world-readable
+	if info, err := os.Stat(path); err == nil {
+		perm = info.Mode().Perm()
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	tmpPath := tmp.Name()
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmpPath)
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		os.Remove(tmpPath)
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+	if err := os.Chmod(tmpPath, perm); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+	// Callers chown the link they asked for; the file behind it is ours to fix
+	if linked {
+		chownToActualUser(path)
+	}
+	recorder.wrote(requested, data, perm)
+	return nil
+}
+
+// copyFile copies src to dst, replacing dst
+func copyFile(src, dst string) error {
+	data, err := os.ReadFile(src)
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(dst, data, 0644)
+}
+
+// sameFileContent reports whether both files exist and have identical bytes
+func sameFileContent(a, b string) bool {
+	dataA, errA := os.ReadFile(a)
+	dataB, errB := os.ReadFile(b)
+	return errA == nil && errB == nil && bytes.Equal(dataA, dataB)
+}
+
+// validateJSON checks if a file contains valid JSON
+func validateJSON(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read file: %w", err)
+	}
+
+	var js interface{}
+	if err := parseJSONC(data, &js); err != nil {
+		return fmt.Errorf("invalid JSON: %w", err)
+	}
+
+	return nil
+}
+
+// cursorAgentLoggedIn checks if cursor-agent is logged in
+func cursorAgentLoggedIn(runner CommandRunner) bool {
+	output, err := runner.Output(exec.Command("cursor-agent", "whoami"))
+	if err != nil {
+		return false
+	}
+	return !strings.Contains(string(output), "Not logged in")
+}
+
+// OpenCodeInstallMethod represents how opencode was installed
+type OpenCodeInstallMethod int
+
+const (
+	InstallMethodUnknown    OpenCodeInstallMethod = iota
+	InstallMethodAUR                              // /usr/bin/opencode via AUR package
+	InstallMethodCurlScript                       // ~/.opencode/bin/opencode via official install script
+	InstallMethodNpmGlobal                        // npm install -g opencode-ai
+	InstallMethodBunGlobal                        // bun install -g opencode-ai
+	InstallMethodHomebrew                         // brew install opencode
+)
+
+func (m OpenCodeInstallMethod) String() string {
+	switch m {
+	case InstallMethodAUR:
+		return "AUR package (opencode-bin)"
+	case InstallMethodCurlScript:
+		return "Official install script"
+	case InstallMethodNpmGlobal:
+		return "npm global"
+	case InstallMethodBunGlobal:
+		return "bun global"
+	case InstallMethodHomebrew:
+		return "Homebrew"
+	default:
+		return "unknown"
+	}
+}
+
+// OpenCodeInfo contains information about the opencode installation
+type OpenCodeInfo struct {
+	Installed     bool
+	Version       string
+	BinaryPath    string
+	InstallMethod OpenCodeInstallMethod
+	ConfigDir     string // ~/.config/opencode
+	PluginDir     string // ~/.config/opencode/plugin
+	NodeModules   string // ~/.config/opencode/node_modules
+}
+
+// describe renders the version and install method, e.g. "1.1.53 (Homebrew)"
+func (info OpenCodeInfo) describe() string {
+	version := info.Version
+	if version == "" {
+		version = "version unknown"
+	}
+	return fmt.Sprintf("%s (%s)", version, info.InstallMethod.String())
+}
+
+// detectOpenCodeInstall returns the opencode the shell would run (first on
+// PATH), or one with Installed false.
+func detectOpenCodeInstall(runner CommandRunner) OpenCodeInfo {
+	if installs := detectOpenCodeInstalls(runner); len(installs) > 0 {
+		return installs[0]
+	}
+	return OpenCodeInfo{Installed: false}
+}
+
+// openCodeSearchDirs are checked after PATH for installs the shell may not see
+func openCodeSearchDirs() []string {
+	homeDir, _ := os.UserHomeDir()
+	return []string{
+		filepath.Join(homeDir, ".opencode", "bin"),
+		filepath.Join(homeDir, ".bun", "bin"),
+		"/opt/homebrew/bin",
+		"/home/linuxbrew/.linuxbrew/bin",
+		"/usr/local/bin",
+		"/usr/bin",
+	}
+}
+
+// detectOpenCodeInstalls lists every distinct opencode binary, PATH order
+// first. Two paths resolving to the same file count once.
+func detectOpenCodeInstalls(runner CommandRunner) []OpenCodeInfo {
+	var installs []OpenCodeInfo
+	seen := map[string]bool{}
+	dirs := append(filepath.SplitList(os.Getenv("PATH")), openCodeSearchDirs()...)
+	for _, dir := range dirs {
+		if dir == "" {
+			continue
+		}
+		binaryPath, err := exec.LookPath(filepath.Join(dir, "opencode"))
+		if err != nil {
+			continue
+		}
+		realPath, err := filepath.EvalSymlinks(binaryPath)
+		if err != nil {
+			realPath = binaryPath
+		}
+		if seen[realPath] {
+			continue
+		}
+		seen[realPath] = true
+		installs = append(installs, inspectOpenCode(runner, binaryPath))
+	}
+	return installs
+}
+
+// inspectOpenCode gathers the version and install method of one opencode binary
+func inspectOpenCode(runner CommandRunner, binaryPath string) OpenCodeInfo {
+	info := OpenCodeInfo{
+		Installed:  true,
+		BinaryPath: binaryPath,
+	}
+
+	// Get version
+	if output, err := runner.Output(exec.Command(binaryPath, "--version")); err == nil {
+		info.Version = strings.TrimSpace(string(output))
+	}
+
+	// Resolve symlinks to get actual binary location
+	realPath, err := filepath.EvalSymlinks(binaryPath)
+	if err != nil {
+		realPath = binaryPath
+	}
+
+	// Determine installation method based on binary location
+	homeDir, _ := os.UserHomeDir()
+
+	switch {
+	case strings.Contains(realPath, "/Cellar/") || strings.Contains(realPath, "/homebrew/") || strings.Contains(realPath, "/linuxbrew/"):
+		info.InstallMethod = InstallMethodHomebrew
+	case strings.HasPrefix(realPath, "/usr/bin/") || strings.HasPrefix(realPath, "/usr/local/bin/"):
+		// Could be AUR or system package
+		// Check if installed via pacman (Arch Linux)
+		if isInstalledViaPacman(runner) {
+			info.InstallMethod = InstallMethodAUR
+		} else {
+			info.InstallMethod = InstallMethodUnknown
+		}
+	case strings.HasPrefix(realPath, filepath.Join(homeDir, ".opencode")):
+		info.InstallMethod = InstallMethodCurlScript
+	case strings.Contains(realPath, "node_modules"):
+		// Could be npm or bun global
+		if strings.Contains(realPath, ".bun") {
+			info.InstallMethod = InstallMethodBunGlobal
+		} else {
+			info.InstallMethod = InstallMethodNpmGlobal
+		}
+	default:
+		info.InstallMethod = InstallMethodUnknown
+	}
+
+	// Set standard config paths (same for all install methods)
+	configDir, _ := getConfigDir()
+	info.ConfigDir = filepath.Join(configDir, "opencode")
+	info.PluginDir = filepath.Join(info.ConfigDir, "plugin")
+	info.NodeModules = filepath.Join(info.ConfigDir, "node_modules")
+
+	return info
+}
+
+// isInstalledViaPacman checks if opencode is installed via pacman (Arch Linux AUR)
+func isInstalledViaPacman(runner CommandRunner) bool {
+	_, err := runner.Output(exec.Command("pacman", "-Qs", "opencode"))
+	return err == nil
+}
+
+// getOpenCodeNodeModulesDir returns the node_modules directory used by opencode for plugins
+func getOpenCodeNodeModulesDir() string {
+	configDir, err := getConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(configDir, "opencode", "node_modules")
+}
+
+// inContainer reports whether the installer is running in a container:
+// Docker's /.dockerenv, Podman's /run/.containerenv, or a container runtime in
+// PID 1's cgroup. OPENCODE_CURSOR_CONTAINER=1 or 0 overrides the detection.
+func inContainer() bool {
+	if v := os.Getenv("OPENCODE_CURSOR_CONTAINER"); v != "" {
+		return v != "0" && v != "false"
+	}
+	for _, marker := range []string{"/.dockerenv", "/run/.containerenv"} {
+		if _, err := os.Stat(marker); err == nil {
+			return true
+		}
+	}
+	data, err := os.ReadFile("/proc/1/cgroup")
+	if err != nil {
+		return false
+	}
+	for _, hint := range []string{"docker", "containerd", "kubepods", "libpod", "lxc"} {
+		if strings.Contains(string(data), hint) {
+			return true
+		}
+	}
+	return false
+}
+
+func getProjectDir(container bool) string {
+	if envDir := os.Getenv("OPENCODE_CURSOR_PROJECT_DIR"); envDir != "" {
+		return envDir
+	}
+	if cwd, err := os.Getwd(); err == nil {
+		for {
+			if _, err := os.Stat(filepath.Join(cwd, "package.json")); err == nil {
+				return cwd
+			}
+			parent := filepath.Dir(cwd)
+			if parent == cwd {
+				break
+			}
+			cwd = parent
+		}
+	}
+	// A container image usually copies the binary somewhere unrelated to the
+	// checkout, so its directory is no better a guess than the working one
+	if container {
+		if cwd, err := os.Getwd(); err == nil {
+			return cwd
+		}
+		return "."
+	}
+	exe, err := os.Executable()
+	if err != nil {
+		return "."
+	}
+	return filepath.Dir(exe)
+}
+
+// diffLines returns a minimal line diff between before and after, with removed
+// lines prefixed by "- " and added lines by "+ ". Unchanged lines are omitted.
+func diffLines(before, after string) []string {
+	a := strings.Split(strings.TrimRight(before, "\n"), "\n")
+	b := strings.Split(strings.TrimRight(after, "\n"), "\n")
+	if before == "" {
+		a = nil
+	}
+
+	// Longest common subsequence table
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	var out []string
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] == b[j]:
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			out = append(out, "- "+a[i])
+			i++
+		default:
+			out = append(out, "+ "+b[j])
+			j++
+		}
+	}
+	for ; i < len(a); i++ {
+		out = append(out, "- "+a[i])
+	}
+	for ; j < len(b); j++ {
+		out = append(out, "+ "+b[j])
+	}
+	return out
+}
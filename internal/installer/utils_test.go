@@ -0,0 +1,92 @@
+// internal/installer/utils_test.go
+package installer
+
+import (
+	"os"
+	"os/user"
+	"path/filepath"
+	"testing"
+)
+
+func TestConfigDirFor(t *testing.T) {
+	xdg := filepath.Join(t.TempDir(), "dotfiles", "config")
+	tests := []struct {
+		name  string
+		xdg   string
+		goos  string
+		files []string // created under home before the lookup
+		want  string   // relative to home unless absolute
+	}{
+		{name: "empty XDG_CONFIG_HOME uses ~/.config", goos: "linux", want: ".config"},
+		{name: "absolute XDG_CONFIG_HOME", xdg: xdg, goos: "linux", want: xdg},
+		{name: "XDG_CONFIG_HOME cleaned", xdg: xdg + "/", goos: "linux", want: xdg},
+		{name: "relative XDG_CONFIG_HOME ignored", xdg: "dotfiles/config", goos: "linux", want: ".config"},
+		{name: "XDG_CONFIG_HOME beats Application Support", xdg: xdg, goos: "darwin",
+			files: []string{"Library/Application Support/opencode/opencode.json"}, want: xdg},
+		{name: "darwin without either config", goos: "darwin", want: ".config"},
+		{name: "darwin with only Application Support", goos: "darwin",
+			files: []string{"Library/Application Support/opencode/opencode.json"}, want: "Library/Application Support"},
+		{name: "darwin with both", goos: "darwin",
+			files: []string{".config/opencode/opencode.json", "Library/Application Support/opencode/opencode.json"}, want: ".config"},
+		{name: "Application Support ignored off darwin", goos: "linux",
+			files: []string{"Library/Application Support/opencode/opencode.json"}, want: ".config"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			home := t.TempDir()
+			for _, file := range tt.files {
+				path := filepath.Join(home, filepath.FromSlash(file))
+				if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+					t.Fatal(err)
+				}
+				if err := os.WriteFile(path, []byte("{}"), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			want := tt.want
+			if !filepath.IsAbs(want) {
+				want = filepath.Join(home, filepath.FromSlash(want))
+			}
+			if got := configDirFor(home, tt.xdg, tt.goos); got != want {
+				t.Errorf("configDirFor(%q, %q) = %q, want %q", tt.xdg, tt.goos, got, want)
+			}
+		})
+	}
+}
+
+func TestActualHomeDir(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	for _, sudoUser := range []string{"", "root", "no-such-user-opencode-cursor"} {
+		t.Setenv("SUDO_USER", sudoUser)
+		if got, err := actualHomeDir(); err != nil || got != home {
+			t.Errorf("SUDO_USER=%q: actualHomeDir() = %q, %v; want $HOME %q", sudoUser, got, err, home)
+		}
+	}
+
+	invoker, err := user.Lookup("nobody")
+	if err != nil {
+		t.Skip("no nobody user to stand in for the sudo invoker")
+	}
+	t.Setenv("SUDO_USER", "nobody")
+	if got, err := actualHomeDir(); err != nil || got != invoker.HomeDir {
+		t.Errorf("SUDO_USER=nobody: actualHomeDir() = %q, %v; want %q", got, err, invoker.HomeDir)
+	}
+}
+
+// The old plugin's cache is found under the home directory wherever the
+// config directory is
+func TestLegacyPluginCacheDir(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("SUDO_USER", "")
+	want := filepath.Join(home, ".cache", "opencode", "node_modules", "cursor-acp-auth")
+
+	for _, xdg := range []string{"", filepath.Join(home, "dotfiles", "config")} {
+		t.Setenv("XDG_CONFIG_HOME", xdg)
+		if got := legacyPluginCacheDir(); got != want {
+			t.Errorf("XDG_CONFIG_HOME=%q: legacyPluginCacheDir() = %q, want %q", xdg, got, want)
+		}
+	}
+}
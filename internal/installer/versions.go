@@ -0,0 +1,294 @@
+// internal/installer/versions.go
+package installer
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Minimum supported tool versions. Older bun releases fail `bun run build`
+// with unhelpful errors; cursor-agent versions are date-based.
+const (
+	minBunVersion         = "1.1.0"
+	minCursorAgentVersion = "2025.8.0"
+)
+
+// minNodeVersion is the first node whose `--input-type=module -e` allows the
+// top-level await pluginExportCheck uses
+const minNodeVersion = "14.8.0"
+
+// requiredTool describes a prerequisite binary and how to upgrade it.
+type requiredTool struct {
+	command string
+	minimum string
+	upgrade string
+}
+
+var requiredTools = []requiredTool{
+	{command: "bun", minimum: minBunVersion, upgrade: "bun upgrade"},
+	{command: "cursor-agent", minimum: minCursorAgentVersion, upgrade: "cursor-agent update"},
+}
+
+// defaultAcpSdkVersion is the @agentclientprotocol/sdk range known to work
+// with the plugin; --acp-sdk-version overrides it.
+const defaultAcpSdkVersion = "^0.13.1"
+
+var versionRegex = regexp.MustCompile(`\d+(?:\.\d+)*`)
+
+// rangeComparatorRegex matches one npm range comparator such as "^1.2.0",
+// ">=0.13", "1.x" or "2.0.0-beta.1".
+var rangeComparatorRegex = regexp.MustCompile(`^(?:[~^]|[<>]=?|=)?v?\d+(?:\.(?:\d+|[xX*])){0,2}(?:-[0-9A-Za-z.-]+)?(?:\+[0-9A-Za-z.-]+)?$`)
+
+// validPackageVersion reports whether v looks like an npm version or range
+// (comparators joined by spaces or "||"), or the "latest" tag.
+func validPackageVersion(v string) bool {
+	v = strings.TrimSpace(v)
+	if v == "latest" {
+		return true
+	}
+	if v == "" {
+		return false
+	}
+	for _, set := range strings.Split(v, "||") {
+		comparators := strings.Fields(set)
+		if len(comparators) == 0 {
+			return false
+		}
+		for _, c := range comparators {
+			if !rangeComparatorRegex.MatchString(c) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// versionSatisfies reports whether version is in the npm range rng, one of
+// the forms validPackageVersion accepts. Pre-release tags are ignored, and
+// "latest" is satisfied by nothing: only the registry knows what it is.
+func versionSatisfies(version, rng string) bool {
+	installed, ok := parseVersion(version)
+	if !ok {
+		return false
+	}
+	for _, set := range strings.Split(rng, "||") {
+		comparators := strings.Fields(set)
+		satisfied := len(comparators) > 0
+		for _, c := range comparators {
+			if !comparatorSatisfied(installed, c) {
+				satisfied = false
+				break
+			}
+		}
+		if satisfied {
+			return true
+		}
+	}
+	return false
+}
+
+// comparatorSatisfied checks v against one range comparator. A partial
+// version stands for every version it prefixes: "1.2" and "1.2.x" are
+// >=1.2.0 <1.3.0, so "<=1.2" is <1.3.0 and ">1.2" is >=1.3.0.
+func comparatorSatisfied(v []int, comparator string) bool {
+	op := ""
+	for _, prefix := range []string{">=", "<=", ">", "<", "=", "^", "~"} {
+		if strings.HasPrefix(comparator, prefix) {
+			op = prefix
+			break
+		}
+	}
+	rest := strings.TrimPrefix(strings.TrimPrefix(comparator, op), "v")
+	rest, _, _ = strings.Cut(rest, "+")
+	rest, _, _ = strings.Cut(rest, "-")
+	if rest == "latest" {
+		return false
+	}
+
+	// The numbers given, up to the first x or *
+	var parts []int
+	for _, field := range strings.Split(rest, ".") {
+		n, err := strconv.Atoi(field)
+		if err != nil {
+			break
+		}
+		parts = append(parts, n)
+	}
+	if len(parts) == 0 {
+		// "*", "x", ">=x": any version; ">x" and "<x" none
+		return op != ">" && op != "<"
+	}
+
+	// next is the first version past parts[:i+1], e.g. 1.3.0 for 1.2.x at i=1
+	next := func(i int) []int {
+		bumped := append([]int(nil), parts[:i+1]...)
+		bumped[i]++
+		return bumped
+	}
+	exact := len(parts) == 3
+	last := len(parts) - 1
+	lower := compareVersions(v, parts)
+	switch op {
+	case ">=":
+		return lower >= 0
+	case "<":
+		return lower < 0
+	case ">":
+		if exact {
+			return lower > 0
+		}
+		return compareVersions(v, next(last)) >= 0
+	case "<=":
+		if exact {
+			return lower <= 0
+		}
+		return compareVersions(v, next(last)) < 0
+	case "^":
+		// Up to the next change of the first non-zero part
+		upper := last
+		for i, n := range parts {
+			if n != 0 {
+				upper = i
+				break
+			}
+		}
+		return lower >= 0 && compareVersions(v, next(upper)) < 0
+	case "~":
+		return lower >= 0 && compareVersions(v, next(min(1, last))) < 0
+	}
+	if exact {
+		return lower == 0
+	}
+	return lower >= 0 && compareVersions(v, next(last)) < 0
+}
+
+// packageSpec joins a package name and optional version for bun add/install
+func packageSpec(name, version string) string {
+	if version == "" {
+		return name
+	}
+	return name + "@" + version
+}
+
+// parseVersion extracts the numeric components of a version string such as
+// "v1.2.3", "1.2.3-canary.4" or "2025.11.25-abc". Only the first dotted
+// number is used, so pre-release and build suffixes are ignored.
+func parseVersion(s string) ([]int, bool) {
+	s = strings.TrimPrefix(strings.TrimSpace(s), "v")
+	match := versionRegex.FindString(s)
+	if match == "" {
+		return nil, false
+	}
+	var parts []int
+	for _, field := range strings.Split(match, ".") {
+		n, err := strconv.Atoi(field)
+		if err != nil {
+			return nil, false
+		}
+		parts = append(parts, n)
+	}
+	return parts, true
+}
+
+// compareVersions returns -1, 0 or 1; missing components count as zero.
+func compareVersions(a, b []int) int {
+	for i := 0; i < len(a) || i < len(b); i++ {
+		var x, y int
+		if i < len(a) {
+			x = a[i]
+		}
+		if i < len(b) {
+			y = b[i]
+		}
+		if x != y {
+			if x < y {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
+
+// toolVersion runs `<command> --version` and returns its trimmed output.
+func toolVersion(runner CommandRunner, command string) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	output, err := runner.Output(exec.CommandContext(ctx, command, "--version"))
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// nodeVersion returns node's version if it is on PATH and new enough to run
+// pluginExportCheck, and otherwise why not
+func nodeVersion(runner CommandRunner) (string, error) {
+	if !commandExists("node") {
+		return "", fmt.Errorf("node not found")
+	}
+	raw, err := toolVersion(runner, "node")
+	if err != nil {
+		return "", fmt.Errorf("could not run node --version: %v", err)
+	}
+	installed, ok := parseVersion(raw)
+	minimum, _ := parseVersion(minNodeVersion)
+	if !ok || compareVersions(installed, minimum) < 0 {
+		return "", fmt.Errorf("node %s is older than %s", summarizeRawOutput(raw), minNodeVersion)
+	}
+	return raw, nil
+}
+
+// pluginLoader is the runtime verifyPlugin loads the plugin with: node if it
+// is usable, else bun, else "" and Verify plugin is skipped
+func pluginLoader(runner CommandRunner) string {
+	if _, err := nodeVersion(runner); err == nil {
+		return "node"
+	}
+	if commandExists("bun") {
+		return "bun"
+	}
+	return ""
+}
+
+// checkNode reports what will load the plugin to verify it. Only node is
+// checked for, since bun is covered by the package manager checks; without
+// either the install still goes ahead, just without that verification.
+func checkNode(runner CommandRunner) checkResult {
+	version, err := nodeVersion(runner)
+	switch {
+	case err == nil:
+		return checkResult{name: "node", passed: true, message: version}
+	case commandExists("bun"):
+		return checkResult{name: "node", passed: true, message: err.Error() + "; bun will load the plugin to verify it"}
+	default:
+		return checkResult{name: "node", passed: false, warning: true, message: err.Error() + " and bun is not installed - the plugin cannot be loaded to verify it"}
+	}
+}
+
+// checkToolVersion compares an installed tool against its minimum version.
+// A version that cannot be determined is reported as a warning, not a failure.
+func checkToolVersion(runner CommandRunner, tool requiredTool) checkResult {
+	name := tool.command + " version"
+	raw, err := toolVersion(runner, tool.command)
+	if err != nil {
+		return checkResult{name: name, passed: false, message: fmt.Sprintf("could not run %s --version: %v", tool.command, err), warning: true}
+	}
+
+	installed, ok := parseVersion(raw)
+	minimum, _ := parseVersion(tool.minimum)
+	if !ok {
+		return checkResult{name: name, passed: false, message: fmt.Sprintf("unrecognized version %q (need >= %s)", summarizeRawOutput(raw), tool.minimum), warning: true}
+	}
+
+	if compareVersions(installed, minimum) < 0 {
+		return checkResult{name: name, passed: false, message: fmt.Sprintf("%s is too old (need >= %s) - upgrade with: %s", raw, tool.minimum, tool.upgrade)}
+	}
+	return checkResult{name: name, passed: true, message: raw}
+}
@@ -0,0 +1,66 @@
+// internal/installer/versions_test.go
+package installer
+
+import "testing"
+
+func TestVersionSatisfies(t *testing.T) {
+	tests := []struct {
+		version, rng string
+		want         bool
+	}{
+		{"1.0.9", "1.0.9", true},
+		{"1.0.9", "1.0.8", false},
+		{"1.0.9", "v1.0.9", true},
+		{"1.0.9", "=1.0.9", true},
+		{"1.0.9", "1.0", true},
+		{"1.1.0", "1.0", false},
+		{"1.0.9", "1.0.x", true},
+		{"1.9.0", "1.x", true},
+		{"2.0.0", "1.x", false},
+		{"3.1.4", "*", true},
+		{"3.1.4", "x", true},
+
+		{"1.2.3", "^1.2.3", true},
+		{"1.9.0", "^1.2.3", true},
+		{"1.2.2", "^1.2.3", false},
+		{"2.0.0", "^1.2.3", false},
+		{"1.2.0", "^1.2", true},
+		{"0.2.5", "^0.2.3", true},
+		{"0.3.0", "^0.2.3", false},
+		{"0.0.3", "^0.0.3", true},
+		{"0.0.4", "^0.0.3", false},
+		{"0.9.0", "^0.x", true},
+		{"1.0.0", "^0.x", false},
+
+		{"1.2.9", "~1.2.3", true},
+		{"1.3.0", "~1.2.3", false},
+		{"1.9.0", "~1", true},
+		{"2.0.0", "~1", false},
+
+		{"1.2.3", ">=1.2.3", true},
+		{"1.2.2", ">=1.2.3", false},
+		{"1.2.4", ">1.2.3", true},
+		{"1.2.3", ">1.2.3", false},
+		{"1.2.9", ">1.2", false},
+		{"1.3.0", ">1.2", true},
+		{"1.2.9", "<=1.2", true},
+		{"1.3.0", "<=1.2", false},
+		{"1.2.2", "<1.2.3", true},
+		{"1.2.3", "<1.2.3", false},
+
+		{"1.5.0", ">=1.2.0 <2.0.0", true},
+		{"2.0.0", ">=1.2.0 <2.0.0", false},
+		{"3.0.1", "^1.0.0 || ^3.0.0", true},
+		{"2.0.1", "^1.0.0 || ^3.0.0", false},
+		{"1.2.3-beta.1", "^1.2.0", true},
+
+		{"1.2.3", "latest", false},
+		{"", "^1.0.0", false},
+		{"1.2.3", "", false},
+	}
+	for _, tt := range tests {
+		if got := versionSatisfies(tt.version, tt.rng); got != tt.want {
+			t.Errorf("versionSatisfies(%q, %q) = %v, want %v", tt.version, tt.rng, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,793 @@
+// internal/installer/view.go
+package installer
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+func (m model) View() string {
+	if m.width == 0 {
+		return "Loading..."
+	}
+
+	if m.width < 80 || m.height < 24 {
+		return lipgloss.NewStyle().
+			Foreground(ErrorColor).
+			Background(BgBase).
+			Bold(true).
+			Width(m.width).
+			Height(m.height).
+			Render(fmt.Sprintf(
+				"Terminal too small!\n\nMinimum: 80x24\nCurrent: %dx%d\n\nPlease resize.",
+				m.width, m.height,
+			))
+	}
+
+	var content strings.Builder
+
+	// Render animated header
+	if m.beams != nil {
+		beamsOutput := m.beams.Render()
+		content.WriteString(beamsOutput)
+		content.WriteString("\n")
+	} else {
+		// Render entire ASCII block at once to preserve character alignment
+		// (per-line Render() mangles widths with Unicode block characters)
+		asciiStyle := lipgloss.NewStyle().
+			Foreground(Primary).
+			Background(BgBase).
+			Bold(true)
+		styledAscii := asciiStyle.Render(asciiHeader)
+
+		// Center the entire block
+		centeredAscii := lipgloss.NewStyle().
+			Width(m.width).
+			Align(lipgloss.Center).
+			Render(styledAscii)
+		content.WriteString(centeredAscii)
+		content.WriteString("\n")
+	}
+	content.WriteString("\n")
+
+	// Render ticker
+	if m.ticker != nil {
+		tickerText := m.ticker.Render(m.width - 4)
+		tickerStyled := lipgloss.NewStyle().
+			Foreground(FgMuted).
+			Background(BgBase).
+			Italic(true).
+			Width(m.width).
+			Align(lipgloss.Center).
+			Render(tickerText)
+		content.WriteString(tickerStyled)
+		content.WriteString("\n\n")
+	}
+
+	// Main content based on step
+	var mainContent string
+	switch m.step {
+	case stepWelcome:
+		mainContent = m.renderWelcome()
+	case stepInstalling:
+		mainContent = m.renderInstalling()
+	case stepUninstalling:
+		mainContent = m.renderInstalling() // Same view for uninstalling
+	case stepSelectModels:
+		mainContent = m.renderSelectModels()
+	case stepConfirmConfig:
+		mainContent = m.renderConfirmConfig()
+	case stepSelectOpenCode:
+		mainContent = m.renderSelectOpenCode()
+	case stepRepairConfig:
+		mainContent = m.renderRepairConfig()
+	case stepConfirmUninstall:
+		mainContent = m.renderConfirmUninstall()
+	case stepComplete:
+		mainContent = m.renderComplete()
+		if m.completeStatus != "" {
+			mainContent += "\n\n" + lipgloss.NewStyle().Foreground(FgMuted).Italic(true).Render(m.completeStatus)
+		}
+	}
+
+	mainStyle := lipgloss.NewStyle().
+		Padding(1, 2).
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(Secondary).
+		Foreground(FgPrimary).
+		Background(BgBase).
+		Width(m.width - 4)
+	content.WriteString(mainStyle.Render(mainContent))
+	content.WriteString("\n")
+
+	// Help text
+	helpText := m.getHelpText()
+	if helpText != "" {
+		helpStyle := lipgloss.NewStyle().
+			Foreground(FgMuted).
+			Background(BgBase).
+			Italic(true).
+			Width(m.width).
+			Align(lipgloss.Center)
+		content.WriteString("\n" + helpStyle.Render(helpText))
+	}
+
+	// Full screen background
+	bgStyle := lipgloss.NewStyle().
+		Background(BgBase).
+		Foreground(FgPrimary).
+		Width(m.width).
+		Height(m.height).
+		Align(lipgloss.Center, lipgloss.Top)
+
+	return bgStyle.Render(content.String())
+}
+
+func (m model) getHelpText() string {
+	switch m.step {
+	case stepWelcome:
+		help := "Enter: Install"
+		if m.existingSetup && m.force {
+			help = "Enter: Repair"
+		}
+		if m.needsLogin() {
+			help += "  •  l: Log in to Cursor"
+		}
+		if _, ok := m.selectedFix(); ok {
+			help += "  •  f: Run fix"
+		}
+		if m.existingSetup {
+			help += "  •  u: Uninstall"
+		}
+		return help + "  •  q: Quit"
+	case stepInstalling, stepUninstalling:
+		if m.cancelling {
+			return "Cancelling, rolling back...  •  Ctrl+C: Force quit"
+		}
+		if m.showLog {
+			return "Please wait...  •  l: Hide log  •  ↑/↓: Scroll  •  Ctrl+C: Cancel"
+		}
+		return "Please wait...  •  l: Show log  •  Ctrl+C: Cancel"
+	case stepSelectModels:
+		if m.refetching {
+			return "Re-fetching models...  •  Esc: Cancel install"
+		}
+		return "↑/↓: Move  •  Space: Toggle  •  a: All/None  •  r: Re-fetch  •  Enter: Continue"
+	case stepConfirmConfig:
+		return "↑/↓: Scroll  •  Enter/y: Apply  •  n/Esc: Cancel install"
+	case stepSelectOpenCode:
+		return "↑/↓: Move  •  Enter: Use this OpenCode  •  q: Quit"
+	case stepRepairConfig:
+		return "Enter/y: Back up and reset  •  n: Back  •  q: Quit"
+	case stepConfirmUninstall:
+		return "Enter/y: Back up and uninstall  •  n: Back  •  q: Quit"
+	case stepComplete:
+		help := "o: Open log  •  c: Copy log path  •  Enter: Exit"
+		if m.logFile == nil {
+			help = "Enter: Exit  •  (no log file for this run)"
+		}
+		if _, ok := m.unparsedTask(); ok {
+			if m.showRawOutput {
+				return "r: Hide output  •  ↑/↓: Scroll  •  " + help
+			}
+			return "r: Show unparsed output  •  " + help
+		}
+		return help
+	}
+	return ""
+}
+
+func (m model) renderWelcome() string {
+	var b strings.Builder
+
+	b.WriteString(lipgloss.NewStyle().Bold(true).Foreground(Primary).Render("OpenCode-Cursor Plugin Installer"))
+	b.WriteString("\n\n")
+
+	b.WriteString("Pre-install checks:\n\n")
+	b.WriteString(renderChecks(m.checks))
+	b.WriteString("\n")
+
+	if m.needsLogin() {
+		b.WriteString(lipgloss.NewStyle().Foreground(WarningColor).Render("⚠ cursor-agent is not logged in - press 'l' to log in now"))
+		b.WriteString("\n\n")
+	}
+
+	// The login has its own key and line above
+	if check, ok := m.selectedFix(); ok && check.name != loginCheckName {
+		fix := fmt.Sprintf("Fix %s: press 'f' to run ", check.name)
+		b.WriteString(lipgloss.NewStyle().Foreground(WarningColor).Render(fix))
+		b.WriteString(lipgloss.NewStyle().Foreground(Secondary).Render(describeFix(check.fixCmd)))
+		if n := len(fixableChecks(m.checks)); n > 1 {
+			b.WriteString(lipgloss.NewStyle().Foreground(FgMuted).Render(fmt.Sprintf("  (%d of %d, ↑/↓ for the others)", min(m.fixCursor, n-1)+1, n)))
+		}
+		b.WriteString("\n\n")
+	}
+
+	if m.existingSetup {
+		action := "reinstall"
+		if m.force {
+			action = "repair (rebuild, relink and rewrite the provider)"
+		}
+		b.WriteString(lipgloss.NewStyle().Foreground(WarningColor).Render("⚠ cursor-acp already configured"))
+		b.WriteString("\n\n")
+		b.WriteString(lipgloss.NewStyle().Bold(true).Foreground(Primary).Render("Press Enter to " + action))
+		b.WriteString("  •  ")
+		b.WriteString(lipgloss.NewStyle().Bold(true).Foreground(ErrorColor).Render("Press 'u' to uninstall"))
+	} else {
+		// Check if we can proceed
+		canProceed := true
+		for _, check := range m.checks {
+			if !check.passed && !check.warning {
+				canProceed = false
+				break
+			}
+		}
+
+		if canProceed {
+			b.WriteString(lipgloss.NewStyle().Bold(true).Foreground(Primary).Render("Press Enter to install"))
+		} else {
+			b.WriteString(lipgloss.NewStyle().Foreground(ErrorColor).Render("Fix errors above before installing"))
+		}
+	}
+
+	return b.String()
+}
+
+// renderChecks renders check results one per line with status marks
+func renderChecks(checks []checkResult) string {
+	var b strings.Builder
+	for _, check := range checks {
+		var status string
+		if check.passed {
+			status = checkMark.String()
+		} else if check.warning {
+			status = skipMark.String()
+		} else {
+			status = failMark.String()
+		}
+		b.WriteString(fmt.Sprintf("  %s %s: %s\n", status, check.name, check.message))
+	}
+	return b.String()
+}
+
+func (m model) renderSelectModels() string {
+	var b strings.Builder
+
+	b.WriteString(lipgloss.NewStyle().Bold(true).Foreground(Primary).Render("Select models"))
+	b.WriteString("\n\n")
+	b.WriteString(fmt.Sprintf("%d of %d models selected. %s\n\n",
+		m.selectedModelCount(), len(m.modelChoices), m.selectionNote()))
+	if m.refetching {
+		b.WriteString(m.spinner.View() + " Re-fetching models from cursor-agent...\n\n")
+	} else if m.refetchError != "" {
+		b.WriteString(lipgloss.NewStyle().Foreground(ErrorColor).Render("Re-fetch failed: " + m.refetchError))
+		b.WriteString("\n")
+		b.WriteString(lipgloss.NewStyle().Foreground(FgMuted).Render("The list below is from the first fetch. Press r to try again."))
+		b.WriteString("\n\n")
+	}
+
+	// Show a window of the list around the cursor so long lists fit the screen
+	visible := m.height - 22
+	if visible < 5 {
+		visible = 5
+	}
+	start := 0
+	if m.modelCursor >= visible {
+		start = m.modelCursor - visible + 1
+	}
+	end := start + visible
+	if end > len(m.modelChoices) {
+		end = len(m.modelChoices)
+	}
+
+	nameStyle := lipgloss.NewStyle().Foreground(FgMuted)
+	for i := start; i < end; i++ {
+		id := m.modelChoices[i]
+		cursor := "  "
+		if i == m.modelCursor {
+			cursor = "> "
+		}
+		box := "[ ]"
+		if m.modelSelected[id] {
+			box = "[x]"
+		}
+		name := ""
+		if entry, ok := m.state.models[id].(map[string]interface{}); ok {
+			name, _ = entry["name"].(string)
+		}
+		line := fmt.Sprintf("%s%s %s", cursor, box, id)
+		if i == m.modelCursor {
+			line = lipgloss.NewStyle().Bold(true).Foreground(Primary).Render(line)
+		}
+		b.WriteString(line + "  " + nameStyle.Render(name) + "\n")
+	}
+	if end < len(m.modelChoices) {
+		b.WriteString(nameStyle.Render(fmt.Sprintf("  ... %d more\n", len(m.modelChoices)-end)))
+	}
+
+	return b.String()
+}
+
+func (m model) renderSelectOpenCode() string {
+	var b strings.Builder
+
+	b.WriteString(lipgloss.NewStyle().Bold(true).Foreground(Primary).Render("Select OpenCode install"))
+	b.WriteString("\n\n")
+	b.WriteString(fmt.Sprintf("Found %d OpenCode installs. The first is the one your shell runs.\n\n", len(m.opencodeInstalls)))
+
+	detailStyle := lipgloss.NewStyle().Foreground(FgMuted)
+	for i, info := range m.opencodeInstalls {
+		cursor := "  "
+		if i == m.opencodeCursor {
+			cursor = "> "
+		}
+		line := cursor + info.BinaryPath
+		if i == m.opencodeCursor {
+			line = lipgloss.NewStyle().Bold(true).Foreground(Primary).Render(line)
+		}
+		b.WriteString(line + "  " + detailStyle.Render(info.describe()) + "\n")
+	}
+
+	return b.String()
+}
+
+func (m model) renderRepairConfig() string {
+	var b strings.Builder
+
+	b.WriteString(lipgloss.NewStyle().Bold(true).Foreground(Primary).Render("Config is not valid JSON"))
+	b.WriteString("\n\n")
+	b.WriteString(fmt.Sprintf("%s cannot be parsed:\n\n", m.configPath))
+	b.WriteString(lipgloss.NewStyle().Foreground(ErrorColor).Render("  "+configSyntaxProblem(m.configPath)) + "\n\n")
+	b.WriteString("The installer can copy it to a timestamped backup in\n")
+	b.WriteString(lipgloss.NewStyle().Foreground(FgMuted).Render("  "+m.backupDir) + "\n")
+	b.WriteString("and start from a minimal config. Anything else you had configured\n")
+	b.WriteString("will need to be copied back from the backup.\n")
+	if m.repairError != "" {
+		b.WriteString("\n" + lipgloss.NewStyle().Foreground(ErrorColor).Render("Reset failed: "+m.repairError) + "\n")
+	}
+
+	return b.String()
+}
+
+func (m model) renderConfirmUninstall() string {
+	var b strings.Builder
+
+	b.WriteString(lipgloss.NewStyle().Bold(true).Foreground(Primary).Render("Uninstall cursor-acp?"))
+	b.WriteString("\n\n")
+	if len(m.uninstallPlan) == 0 {
+		b.WriteString("Nothing the installer added was found; uninstalling will only check the config.\n")
+		return b.String()
+	}
+
+	b.WriteString("This will remove:\n\n")
+	kindStyle := lipgloss.NewStyle().Foreground(FgMuted)
+	for _, item := range m.uninstallPlan {
+		b.WriteString(lipgloss.NewStyle().Foreground(ErrorColor).Render("  - ") + kindStyle.Render(fmt.Sprintf("%-8s", item.Kind)) + item.What + "\n")
+	}
+	b.WriteString("\nThe files it changes are backed up first to\n")
+	b.WriteString(lipgloss.NewStyle().Foreground(FgMuted).Render("  "+m.backupDir) + "\n")
+	b.WriteString("and can be put back with the `restore` command.\n")
+
+	return b.String()
+}
+
+func (m model) renderConfirmConfig() string {
+	var b strings.Builder
+
+	b.WriteString(lipgloss.NewStyle().Bold(true).Foreground(Primary).Render("Review config changes"))
+	b.WriteString("\n\n")
+	b.WriteString(fmt.Sprintf("The installer will make these changes to %s:\n\n", m.configPath))
+
+	visible := m.height - 22
+	if visible < 5 {
+		visible = 5
+	}
+	diff := m.state.configDiff
+	start := m.diffScroll
+	end := start + visible
+	if end > len(diff) {
+		end = len(diff)
+	}
+
+	addStyle := lipgloss.NewStyle().Foreground(SuccessColor)
+	removeStyle := lipgloss.NewStyle().Foreground(ErrorColor)
+	mutedStyle := lipgloss.NewStyle().Foreground(FgMuted)
+	for _, line := range diff[start:end] {
+		switch {
+		case strings.HasPrefix(line, "+"):
+			b.WriteString(addStyle.Render(line) + "\n")
+		case strings.HasPrefix(line, "-"):
+			b.WriteString(removeStyle.Render(line) + "\n")
+		default:
+			b.WriteString(mutedStyle.Render(line) + "\n")
+		}
+	}
+	if end < len(diff) {
+		b.WriteString(mutedStyle.Render(fmt.Sprintf("  ... %d more lines\n", len(diff)-end)))
+	}
+
+	return b.String()
+}
+
+func (m model) renderInstalling() string {
+	var b strings.Builder
+
+	if len(m.tasks) > 0 {
+		done := 0
+		for _, task := range m.tasks {
+			if task.status != statusPending && task.status != statusRunning {
+				done++
+			}
+		}
+		percent := float64(done) / float64(len(m.tasks))
+		b.WriteString(fmt.Sprintf("%s  %d/%d\n\n", m.progress.ViewAs(percent), done, len(m.tasks)))
+	}
+
+	for _, task := range m.tasks {
+		var line string
+		switch task.status {
+		case statusPending:
+			line = lipgloss.NewStyle().Foreground(FgMuted).Render("  " + task.name)
+		case statusRunning:
+			line = m.spinner.View() + " " + lipgloss.NewStyle().Foreground(Secondary).Render(task.description) +
+				lipgloss.NewStyle().Foreground(FgMuted).Render(" ("+formatDuration(time.Since(task.startedAt))+")")
+		case statusComplete:
+			line = checkMark.String() + " " + task.name + lipgloss.NewStyle().Foreground(FgMuted).Render(" ("+formatDuration(task.duration)+")")
+		case statusFailed:
+			line = failMark.String() + " " + task.name + lipgloss.NewStyle().Foreground(FgMuted).Render(" ("+formatDuration(task.duration)+")")
+		case statusSkipped:
+			line = skipMark.String() + " " + task.name
+		}
+		b.WriteString(line + "\n")
+
+		if (task.status == statusSkipped || task.status == statusComplete) && task.note != "" {
+			summary := strings.SplitN(task.note, "\n", 2)[0]
+			b.WriteString(lipgloss.NewStyle().Foreground(FgMuted).Render(
+				fmt.Sprintf("  └─ %s\n", summary)))
+		}
+
+		if task.status == statusFailed && task.errorDetails != nil {
+			err := task.errorDetails
+			style := categoryStyleFor(err.category)
+			b.WriteString(lipgloss.NewStyle().Foreground(style.color).Render(
+				fmt.Sprintf("  └─ %s %s\n", style.icon, err.message)))
+			if err.logFile != "" {
+				b.WriteString(lipgloss.NewStyle().Foreground(FgMuted).Render(
+					fmt.Sprintf("  └─ See logs: %s\n", err.logFile)))
+			}
+			if err.remediation != "" {
+				b.WriteString(lipgloss.NewStyle().Foreground(WarningColor).Bold(true).Render(
+					fmt.Sprintf("  └─ Fix: %s\n", err.remediation)))
+			}
+			if strings.Contains(err.message, "no models found") {
+				b.WriteString(lipgloss.NewStyle().Foreground(WarningColor).Render(
+					"  └─ Hint: Run with --debug to see raw cursor-agent output\n"))
+			}
+			if strings.Contains(err.message, "cursor-agent") && strings.Contains(err.message, "failed") {
+				b.WriteString(lipgloss.NewStyle().Foreground(WarningColor).Render(
+					"  └─ Hint: Ensure cursor-agent is installed and logged in\n"))
+			}
+		}
+	}
+
+	if m.showLog {
+		b.WriteString("\n" + m.renderLogPane())
+	}
+
+	return b.String()
+}
+
+// renderLogPane shows the most recent child-process output, scrolled back by m.logScroll
+func (m model) renderLogPane() string {
+	end := len(m.logLines) - m.logScroll
+	if end < 0 {
+		end = 0
+	}
+	start := end - logPaneHeight
+	if start < 0 {
+		start = 0
+	}
+
+	title := "Output"
+	if m.logScroll > 0 {
+		title = fmt.Sprintf("Output (%d lines back)", m.logScroll)
+	}
+	return m.renderPane(title, m.logLines[start:end], logPaneHeight)
+}
+
+// paneWidth is the text width of the bordered output panes
+func (m model) paneWidth() int {
+	return max(m.width-14, 20)
+}
+
+// renderPane draws lines in a bordered box height lines tall, cutting off
+// lines too long for it
+func (m model) renderPane(title string, lines []string, height int) string {
+	width := m.paneWidth()
+	shown := make([]string, 0, height)
+	for _, line := range lines {
+		if runes := []rune(line); len(runes) > width {
+			line = string(runes[:width-1]) + "…"
+		}
+		shown = append(shown, line)
+	}
+	for len(shown) < height {
+		shown = append(shown, "")
+	}
+	return lipgloss.NewStyle().Bold(true).Foreground(Secondary).Render(title) + "\n" +
+		lipgloss.NewStyle().
+			Border(lipgloss.NormalBorder()).
+			BorderForeground(FgMuted).
+			Foreground(FgMuted).
+			Width(width).
+			Render(strings.Join(shown, "\n"))
+}
+
+// rawPaneHeight is the number of lines of unparsed output shown at once
+const rawPaneHeight = 12
+
+// unparsedTask returns the failed task whose PARSE error kept the output it
+// could not make sense of, if there is one
+func (m model) unparsedTask() (installTask, bool) {
+	for _, task := range m.tasks {
+		if task.status == statusFailed && task.errorDetails != nil && task.errorDetails.rawOutput != "" {
+			return task, true
+		}
+	}
+	return installTask{}, false
+}
+
+// rawOutputLines is the unparsed output wrapped to the pane, so every
+// character of it can be scrolled to
+func (m model) rawOutputLines() []string {
+	task, ok := m.unparsedTask()
+	if !ok {
+		return nil
+	}
+	width := m.paneWidth()
+	var lines []string
+	for _, line := range strings.Split(task.errorDetails.rawOutput, "\n") {
+		runes := []rune(strings.TrimRight(line, "\r"))
+		for len(runes) > width {
+			lines = append(lines, string(runes[:width]))
+			runes = runes[width:]
+		}
+		lines = append(lines, string(runes))
+	}
+	return lines
+}
+
+// renderRawOutput shows the full output a failed parse choked on, from line
+// m.rawScroll, so a changed cursor-agent format can be seen and reported
+func (m model) renderRawOutput() string {
+	task, _ := m.unparsedTask()
+	lines := m.rawOutputLines()
+	// A wider terminal wraps to fewer lines than were scrolled through
+	start := min(m.rawScroll, max(len(lines)-rawPaneHeight, 0))
+	end := min(start+rawPaneHeight, len(lines))
+	title := fmt.Sprintf("%s: output that could not be parsed (lines %d-%d of %d)", task.name, start+1, end, len(lines))
+	return m.renderPane(title, lines[start:end], rawPaneHeight)
+}
+
+// formatDuration renders a task time like "12.3s"
+func formatDuration(d time.Duration) string {
+	if d < time.Minute {
+		return fmt.Sprintf("%.1fs", d.Seconds())
+	}
+	return d.Round(time.Second).String()
+}
+
+// categoryStyleFor returns the style for an error category, treating an
+// unrecognised one as UNKNOWN
+func categoryStyleFor(category string) categoryStyle {
+	if style, ok := errorCategoryStyles[category]; ok {
+		return style
+	}
+	return errorCategoryStyles[errorCategoryUnknown]
+}
+
+// renderErrorGroups lists failed tasks grouped by error category, each group
+// headed by what that kind of failure usually means.
+func (m model) renderErrorGroups() string {
+	groups := make(map[string][]installTask)
+	for _, task := range m.tasks {
+		if task.status != statusFailed || task.errorDetails == nil {
+			continue
+		}
+		category := task.errorDetails.category
+		if _, known := errorCategoryStyles[category]; !known {
+			category = errorCategoryUnknown
+		}
+		groups[category] = append(groups[category], task)
+	}
+
+	var b strings.Builder
+	for _, category := range errorCategories {
+		tasks := groups[category]
+		if len(tasks) == 0 {
+			continue
+		}
+		style := categoryStyleFor(category)
+		b.WriteString("\n")
+		b.WriteString(lipgloss.NewStyle().Foreground(style.color).Bold(true).Render(style.icon + " " + category))
+		b.WriteString(lipgloss.NewStyle().Foreground(FgMuted).Render("  " + categoryHint[category]))
+		b.WriteString("\n")
+
+		for _, task := range tasks {
+			err := task.errorDetails
+			message := strings.TrimPrefix(err.message, "["+category+"] ")
+			b.WriteString(lipgloss.NewStyle().Foreground(style.color).Render(
+				fmt.Sprintf("  └─ %s: %s\n", task.name, message)))
+			if err.remediation != "" {
+				b.WriteString(lipgloss.NewStyle().Foreground(WarningColor).Bold(true).Render(
+					fmt.Sprintf("     Fix: %s\n", err.remediation)))
+			}
+			if err.rawOutput != "" {
+				b.WriteString(lipgloss.NewStyle().Foreground(WarningColor).Render(
+					"     Hint: Press r to see the full output that could not be parsed\n"))
+			} else if strings.Contains(err.message, "no models found") {
+				b.WriteString(lipgloss.NewStyle().Foreground(WarningColor).Render(
+					"     Hint: Run with --debug to see raw cursor-agent output\n"))
+			}
+		}
+	}
+	b.WriteString("\n")
+	return b.String()
+}
+
+func (m model) renderComplete() string {
+	hasCriticalFailure := false
+	for _, task := range m.tasks {
+		if task.status == statusFailed && !task.optional {
+			hasCriticalFailure = true
+			break
+		}
+	}
+
+	if hasCriticalFailure {
+		action := "Installation"
+		if m.isUninstall {
+			action = "Uninstallation"
+		}
+
+		var b strings.Builder
+		b.WriteString(lipgloss.NewStyle().Foreground(ErrorColor).Bold(true).Render(
+			fmt.Sprintf("✗ %s Failed\n\n", action)))
+
+		for _, task := range m.tasks {
+			var line string
+			switch task.status {
+			case statusComplete:
+				line = checkMark.String() + " " + task.name
+			case statusFailed:
+				line = failMark.String() + " " + task.name
+			case statusSkipped:
+				line = skipMark.String() + " " + task.name
+			default:
+				line = lipgloss.NewStyle().Foreground(FgMuted).Render("  " + task.name)
+			}
+			b.WriteString(line + "\n")
+		}
+
+		b.WriteString(m.renderErrorGroups())
+		if m.showRawOutput {
+			b.WriteString(m.renderRawOutput())
+			b.WriteString("\n\n")
+		}
+		if logFile := logFileName(m.logFile); logFile != "" {
+			b.WriteString(lipgloss.NewStyle().Foreground(FgMuted).Render("Logs: " + logFile))
+			b.WriteString("\n")
+		}
+
+		b.WriteString("\n")
+		b.WriteString(lipgloss.NewStyle().Foreground(FgMuted).Render("Press Enter to exit"))
+		return b.String()
+	}
+
+	if m.dryRun {
+		return m.renderDryRunSummary()
+	}
+
+	// Warnings get their own heading, so "fine" and "fine, but you should
+	// know this" look different
+	warnings := m.allWarnings()
+	title, titleColor := "✓ Installation Complete", SuccessColor
+	if m.isUninstall {
+		title = "✓ Uninstallation Complete"
+	}
+	if len(warnings) > 0 {
+		title = strings.Replace(strings.Replace(title, "✓", "⚠", 1), "Complete", "Completed with Warnings", 1)
+		titleColor = WarningColor
+	}
+
+	var b strings.Builder
+	b.WriteString(lipgloss.NewStyle().Foreground(titleColor).Bold(true).Render(title))
+	b.WriteString("\n\n")
+	if m.isUninstall {
+		b.WriteString("The cursor-acp plugin has been removed from OpenCode.\n\n")
+		for _, item := range m.state.removed {
+			b.WriteString(lipgloss.NewStyle().Foreground(FgMuted).Render("  - " + item))
+			b.WriteString("\n")
+		}
+		if len(m.state.removed) > 0 {
+			b.WriteString("\n")
+		}
+	} else if m.changedNothing() {
+		b.WriteString("cursor-acp was already installed and up to date - no changes needed.\n\n")
+	} else {
+		b.WriteString("The cursor-acp provider is now available in OpenCode.\n\n")
+	}
+
+	if len(warnings) > 0 {
+		b.WriteString(lipgloss.NewStyle().Bold(true).Foreground(WarningColor).Render(fmt.Sprintf("Warnings (%d)", len(warnings))))
+		b.WriteString("\n")
+		sourceStyle := lipgloss.NewStyle().Foreground(FgMuted)
+		for _, warning := range warnings {
+			b.WriteString(lipgloss.NewStyle().Foreground(WarningColor).Render("  ⚠ "+warning.Message) + " " + sourceStyle.Render("["+warning.Source+"]"))
+			b.WriteString("\n")
+		}
+		b.WriteString("\n")
+	}
+
+	if !m.isUninstall {
+		b.WriteString(lipgloss.NewStyle().Bold(true).Foreground(Primary).Render("Next Steps"))
+		b.WriteString("\n")
+
+		cmdStyle := lipgloss.NewStyle().Foreground(Secondary)
+		descStyle := lipgloss.NewStyle().Foreground(FgMuted)
+		for i, step := range m.nextSteps() {
+			b.WriteString(fmt.Sprintf("  %d. %s  %s\n", i+1, cmdStyle.Render(step.Command), descStyle.Render(step.Description)))
+		}
+		b.WriteString("\n")
+
+		pathStyle := lipgloss.NewStyle().Foreground(FgMuted).Italic(true)
+		b.WriteString(fmt.Sprintf("Plugin:  %s\n", pathStyle.Render(m.pluginDir+"/cursor-acp.js")))
+		b.WriteString(fmt.Sprintf("Config:  %s\n", pathStyle.Render(m.configPath)))
+		if m.state.autoPortURL != "" {
+			b.WriteString(fmt.Sprintf("Proxy:   %s\n", pathStyle.Render(m.state.autoPortURL+" (auto-selected port)")))
+		}
+	}
+	if backupDir := m.writtenBackupDir(); backupDir != "" {
+		pathStyle := lipgloss.NewStyle().Foreground(FgMuted).Italic(true)
+		b.WriteString(fmt.Sprintf("Backups: %s\n", pathStyle.Render(backupDir+" (`restore` puts a file back)")))
+	}
+
+	b.WriteString("\n")
+	b.WriteString(lipgloss.NewStyle().Foreground(FgMuted).Render("Press Enter to exit"))
+
+	return b.String()
+}
+
+func (m model) renderDryRunSummary() string {
+	var b strings.Builder
+	b.WriteString(lipgloss.NewStyle().Foreground(SuccessColor).Bold(true).Render("✓ Dry Run Complete"))
+	b.WriteString("\n\n")
+	b.WriteString("No changes were made. Planned mutations:\n\n")
+
+	diffStyle := lipgloss.NewStyle().Foreground(FgMuted)
+	planned := 0
+	for _, task := range m.tasks {
+		if task.status != statusSkipped || task.note == "" {
+			continue
+		}
+		lines := strings.Split(task.note, "\n")
+		if !strings.HasPrefix(lines[0], "would ") {
+			continue
+		}
+		planned++
+		b.WriteString(fmt.Sprintf("  %s %s: %s\n", skipMark.String(), task.name, lines[0]))
+		for _, line := range lines[1:] {
+			b.WriteString(diffStyle.Render("      "+line) + "\n")
+		}
+	}
+	if planned == 0 {
+		b.WriteString("  (none)\n")
+	}
+
+	b.WriteString("\n")
+	b.WriteString(lipgloss.NewStyle().Foreground(FgMuted).Render("Press Enter to exit"))
+	return b.String()
+}
@@ -0,0 +1,50 @@
+// pkg/installer/api.go
+
+// Package installer installs the cursor-acp OpenCode plugin from Go, for
+// tools that embed it. Install, Uninstall and Doctor run the same steps as
+// the installer binary, without a terminal UI or printed output.
+package installer
+
+import (
+	"context"
+
+	"github.com/nomadcxx/opencode-cursor/internal/installer"
+)
+
+type (
+	// Options configures Install, Uninstall and Doctor. The zero value
+	// behaves like the installer run with no flags, except that nothing is
+	// logged.
+	Options = installer.Options
+
+	// Result is what Install and Uninstall did.
+	Result = installer.Result
+
+	// TaskResult is the outcome of one install or uninstall step.
+	TaskResult = installer.TaskResult
+
+	// Check is one Doctor diagnostic.
+	Check = installer.Check
+
+	// CommandRunner runs the external commands an install depends on; set
+	// Options.Runner to answer them without the real binaries, e.g. in tests.
+	CommandRunner = installer.CommandRunner
+)
+
+// Install installs the plugin and configures OpenCode to use it. It returns
+// an error if a pre-install check or a required task fails; the Result then
+// shows how far it got. Cancelling ctx stops the install and rolls it back.
+func Install(ctx context.Context, opts Options) (Result, error) {
+	return installer.Install(ctx, opts)
+}
+
+// Uninstall removes what Install added. The pre-install checks are not run.
+func Uninstall(ctx context.Context, opts Options) (Result, error) {
+	return installer.Uninstall(ctx, opts)
+}
+
+// Doctor inspects an existing install without changing anything. It returns
+// an error if any check found a problem that breaks the install.
+func Doctor(ctx context.Context, opts Options) ([]Check, error) {
+	return installer.Doctor(ctx, opts)
+}